@@ -89,6 +89,26 @@ Get-ChildItem -Path $DistPath | ForEach-Object {
 # Cleanup raw binaries
 Get-ChildItem -Path $DistPath -Exclude "*.zip" | Remove-Item
 
+# Build the WebAssembly module with its JavaScript loaders
+Write-Host "🌐 Building the WebAssembly module..." -ForegroundColor Cyan
+$WasmPath = Join-Path -Path $DistPath -ChildPath "wasm"
+New-Item -Path $WasmPath -ItemType Directory | Out-Null
+$env:GOOS = "js"
+$env:GOARCH = "wasm"
+go build -trimpath -ldflags="-s -w" -o (Join-Path -Path $WasmPath -ChildPath "wintree.wasm") ./wasm
+if ($LASTEXITCODE -ne 0) {
+    Write-Error "Build failed for js/wasm"
+    exit 1
+}
+Copy-Item -Path (Join-Path -Path (go env GOROOT) -ChildPath "lib/wasm/wasm_exec.js") -Destination $WasmPath
+Copy-Item -Path (Join-Path -Path $ProjectRoot -ChildPath "wasm/wintree.js") -Destination $WasmPath
+Remove-Item Env:GOOS, Env:GOARCH
+
+$ArchiveName = "wintree_$($Version)_wasm.zip"
+Write-Host "  -> Creating $ArchiveName..."
+Compress-Archive -Path (Join-Path -Path $WasmPath -ChildPath "*") -DestinationPath (Join-Path -Path $DistPath -ChildPath $ArchiveName) -Force
+Remove-Item -Path $WasmPath -Recurse
+
 Write-Host ""
 Write-Host "✅ Release build complete!" -ForegroundColor Green
 Write-Host "📦 Release artifacts in: $DistPath"
@@ -0,0 +1,96 @@
+package cmd
+
+import "strings"
+
+// aclEntry is a single allow or deny entry from a Windows DACL.
+type aclEntry struct {
+	account string
+	mask    uint32
+	deny    bool
+}
+
+// Access mask bits used to summarize the rights granted by an ACL entry.
+const (
+	accessReadData    = 0x1
+	accessWriteData   = 0x2
+	accessExecute     = 0x20
+	accessDelete      = 0x10000
+	accessFullControl = 0x1F01FF
+
+	genericAll     = 0x10000000
+	genericExecute = 0x20000000
+	genericWrite   = 0x40000000
+	genericRead    = 0x80000000
+)
+
+// aclAnnotation summarizes the DACL of path, e.g. "[Administrators:F,
+// Users:RX, Guests:!W]". ACLs are only read on Windows; elsewhere, and for
+// virtual nodes, it returns an empty string.
+func aclAnnotation(path string) string {
+	if path == "" {
+		return ""
+	}
+	entries, err := readACL(path)
+	if err != nil {
+		return "[acl: unreadable]"
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	return "[" + summarizeACL(entries) + "]"
+}
+
+// summarizeACL merges the entries for each account and formats them as
+// "account:rights", with denied rights prefixed by "!".
+func summarizeACL(entries []aclEntry) string {
+	type key struct {
+		account string
+		deny    bool
+	}
+
+	var order []key
+	masks := make(map[key]uint32)
+	for _, entry := range entries {
+		k := key{entry.account, entry.deny}
+		if _, ok := masks[k]; !ok {
+			order = append(order, k)
+		}
+		masks[k] |= entry.mask
+	}
+
+	parts := make([]string, 0, len(order))
+	for _, k := range order {
+		rights := aclRights(masks[k])
+		if rights == "" {
+			continue
+		}
+		if k.deny {
+			rights = "!" + rights
+		}
+		parts = append(parts, k.account+":"+rights)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// aclRights abbreviates an access mask as F for full control, or a
+// combination of R (read), W (write), X (execute), and D (delete).
+func aclRights(mask uint32) string {
+	if mask&genericAll != 0 || mask&accessFullControl == accessFullControl {
+		return "F"
+	}
+
+	var rights strings.Builder
+	if mask&(accessReadData|genericRead) != 0 {
+		rights.WriteString("R")
+	}
+	if mask&(accessWriteData|genericWrite) != 0 {
+		rights.WriteString("W")
+	}
+	if mask&(accessExecute|genericExecute) != 0 {
+		rights.WriteString("X")
+	}
+	if mask&accessDelete != 0 {
+		rights.WriteString("D")
+	}
+	return rights.String()
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import "testing"
+
+func TestAclRights(t *testing.T) {
+	tests := []struct {
+		mask     uint32
+		expected string
+	}{
+		{accessFullControl, "F"},
+		{genericAll, "F"},
+		{genericRead | genericExecute, "RX"},
+		{accessReadData | accessWriteData | accessDelete, "RWD"},
+		{0x100000, ""},
+	}
+
+	for _, tt := range tests {
+		if result := aclRights(tt.mask); result != tt.expected {
+			t.Errorf("aclRights(%#x) = %q, expected %q", tt.mask, result, tt.expected)
+		}
+	}
+}
+
+func TestSummarizeACL(t *testing.T) {
+	entries := []aclEntry{
+		{account: "Administrators", mask: accessFullControl},
+		{account: "Users", mask: accessReadData},
+		{account: "Guests", mask: accessWriteData, deny: true},
+		{account: "Users", mask: accessExecute},
+		{account: "SYNCHRONIZE-only", mask: 0x100000},
+	}
+
+	expected := "Administrators:F, Users:RX, Guests:!W"
+	if result := summarizeACL(entries); result != expected {
+		t.Errorf("summarizeACL() = %q, expected %q", result, expected)
+	}
+}
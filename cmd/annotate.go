@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+)
+
+// repeatedDirs maps the directories that the last findMatchingFiles walk
+// reached a second time, through a bind mount or mounted folder, to the path
+// they were first listed at. They are marked instead of being listed again.
+var repeatedDirs map[string]string
+
+// annotationsShown reports whether nodeAnnotations may return text for any
+// node, by the current flags and the results of the last walk.
+func annotationsShown() bool {
+	return followSymlinks || len(repeatedDirs) > 0 || annotateMeta || describeDirs || showLatest ||
+		len(flaggedBinaries) > 0 || len(budgetUsages) > 0 || pruneOlderThan != "" || showACL ||
+		ignoredRules != nil || showGitStatus || showOwners || repoStatuses != nil || pluginAnnotations != nil
+}
+
+// nodeAnnotations returns the text shown after a node's name and metadata
+// columns, combining every annotation enabled by the current flags.
+func nodeAnnotations(path string) string {
+	var parts []string
+
+	if followSymlinks {
+		if target := linkAnnotation(path); target != "" {
+			parts = append(parts, target)
+		}
+	}
+
+	if _, ok := repeatedDirs[path]; ok {
+		parts = append(parts, "[seen above]")
+	}
+
+	if annotateMeta {
+		if meta := metaAnnotation(path); meta != "" {
+			parts = append(parts, meta)
+		}
+	}
+
+	if describeDirs {
+		if description := describeAnnotation(path); description != "" {
+			parts = append(parts, description)
+		}
+	}
+
+	if showLatest {
+		if latest := latestAnnotation(path); latest != "" {
+			parts = append(parts, latest)
+		}
+	}
+
+	if binary := binaryAnnotation(path); binary != "" {
+		parts = append(parts, binary)
+	}
+
+	if budget := budgetAnnotation(path); budget != "" {
+		parts = append(parts, budget)
+	}
+
+	if pruneOlderThan != "" {
+		if pruned := pruneAnnotation(path); pruned != "" {
+			parts = append(parts, pruned)
+		}
+	}
+
+	if showACL {
+		if acl := aclAnnotation(path); acl != "" {
+			parts = append(parts, acl)
+		}
+	}
+
+	if ignoredRules != nil {
+		if ignored := ignoreAnnotation(path); ignored != "" {
+			parts = append(parts, ignored)
+		}
+	}
+
+	if showGitStatus {
+		if state := gitStatusAnnotation(path); state != "" {
+			parts = append(parts, state)
+		}
+	}
+
+	if showOwners {
+		if owners := ownersAnnotation(path); owners != "" {
+			parts = append(parts, owners)
+		}
+	}
+
+	if repoStatuses != nil {
+		if repo := repoAnnotation(path); repo != "" {
+			parts = append(parts, repo)
+		}
+	}
+
+	if pluginAnnotations != nil {
+		if label := pluginLabel(path); label != "" {
+			parts = append(parts, label)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// linkAnnotation shows where a symlink points, as "-> target". Other entries
+// are not annotated.
+func linkAnnotation(path string) string {
+	info, err := lstatCached(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return ""
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return "-> " + target
+}
+
+// metaAnnotation reports whether a directory contains a LICENSE and a README.
+// Files are not annotated.
+func metaAnnotation(path string) string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return ""
+	}
+
+	hasLicense, hasReadme := false, false
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := strings.ToUpper(entry.Name())
+		if strings.HasPrefix(name, "LICENSE") || strings.HasPrefix(name, "LICENCE") || strings.HasPrefix(name, "COPYING") {
+			hasLicense = true
+		}
+		if strings.HasPrefix(name, "README") {
+			hasReadme = true
+		}
+	}
+
+	return "[" + metaMark("README", hasReadme) + ", " + metaMark("LICENSE", hasLicense) + "]"
+}
+
+// metaMark formats a single present/missing marker for metaAnnotation.
+func metaMark(name string, present bool) string {
+	if present {
+		return name + " ✓"
+	}
+	return name + " ✗"
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetaAnnotation(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wintree_meta_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	testFiles := []string{
+		"README.md",
+		"LICENSE",
+		"pkg/readme.txt",
+		"empty/.keep",
+	}
+
+	for _, file := range testFiles {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "both present",
+			path:     tempDir,
+			expected: "[README ✓, LICENSE ✓]",
+		},
+		{
+			name:     "license missing",
+			path:     filepath.Join(tempDir, "pkg"),
+			expected: "[README ✓, LICENSE ✗]",
+		},
+		{
+			name:     "both missing",
+			path:     filepath.Join(tempDir, "empty"),
+			expected: "[README ✗, LICENSE ✗]",
+		},
+		{
+			name:     "files are not annotated",
+			path:     filepath.Join(tempDir, "LICENSE"),
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := metaAnnotation(tt.path); result != tt.expected {
+				t.Errorf("metaAnnotation(%q) = %q, expected %q", tt.path, result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("tree output", func(t *testing.T) {
+		annotateMeta = true
+		defer func() { annotateMeta = false }()
+
+		output := buildTreeOutput(tempDir, []string{filepath.Join(tempDir, "pkg", "readme.txt")})
+		lines := strings.Split(output, "\n")
+		if len(lines) < 3 {
+			t.Fatalf("buildTreeOutput() returned too few lines:\n%s", output)
+		}
+		if !strings.Contains(lines[1], "pkg") || !strings.HasSuffix(lines[1], "[README ✓, LICENSE ✗]") {
+			t.Errorf("buildTreeOutput() missing meta annotation for pkg:\n%s", output)
+		}
+		if !strings.HasSuffix(lines[2], "readme.txt") {
+			t.Errorf("buildTreeOutput() should not annotate files:\n%s", output)
+		}
+	})
+}
+
+func TestLatestAnnotation(t *testing.T) {
+	tempDir := t.TempDir()
+
+	times := map[string]time.Time{
+		"old/a.txt":        time.Date(2020, 1, 1, 12, 0, 0, 0, time.Local),
+		"src/main.go":      time.Date(2023, 5, 1, 9, 30, 0, 0, time.Local),
+		"src/deep/util.go": time.Date(2024, 3, 15, 18, 45, 0, 0, time.Local),
+		// Excluded, so it must not count even though it is the newest
+		"src/build/out.log": time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local),
+	}
+	for file, modTime := range times {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(fullPath, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	originalExcludes := excludePatterns
+	defer func() {
+		excludePatterns = originalExcludes
+		clear(latestCache)
+	}()
+	excludePatterns = []string{"build"}
+	clear(latestCache)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{tempDir, "[latest 2024-03-15 18:45]"},
+		{filepath.Join(tempDir, "old"), "[latest 2020-01-01 12:00]"},
+		{filepath.Join(tempDir, "src"), "[latest 2024-03-15 18:45]"},
+		{filepath.Join(tempDir, "src", "main.go"), ""},
+		{filepath.Join(tempDir, "empty"), ""},
+	}
+	for _, tt := range tests {
+		if result := latestAnnotation(tt.path); result != tt.expected {
+			t.Errorf("latestAnnotation(%s) = %q, expected %q", tt.path, result, tt.expected)
+		}
+	}
+
+	// Files hidden after the walk do not count either: by a nested
+	// .wintreeignore, and then by --max-size
+	ignoreFile := filepath.Join(tempDir, "src", ignoreFileName)
+	if err := os.WriteFile(ignoreFile, []byte("deep\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(ignoreFile, times["old/a.txt"], times["old/a.txt"]); err != nil {
+		t.Fatal(err)
+	}
+	clear(latestCache)
+	if result := latestAnnotation(filepath.Join(tempDir, "src")); result != "[latest 2023-05-01 09:30]" {
+		t.Errorf("latestAnnotation(src) = %q with deep ignored, expected main.go's time", result)
+	}
+	defer func(original walkLimits) { limits = original }(limits)
+	limits.maxSize = 4
+	clear(latestCache)
+	if result := latestAnnotation(tempDir); result != "" {
+		t.Errorf("latestAnnotation() = %q with every file over --max-size, expected none", result)
+	}
+}
+
+func TestLinkAnnotation(t *testing.T) {
+	tempDir := t.TempDir()
+	target := filepath.Join(tempDir, "target")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(tempDir, "link")
+	if err := os.Symlink("target", link); err != nil {
+		t.Skipf("symlinks are not available: %v", err)
+	}
+
+	if annotation := linkAnnotation(link); annotation != "-> target" {
+		t.Errorf("linkAnnotation(link) = %q, expected %q", annotation, "-> target")
+	}
+	if annotation := linkAnnotation(target); annotation != "" {
+		t.Errorf("linkAnnotation(target) = %q, expected no annotation", annotation)
+	}
+}
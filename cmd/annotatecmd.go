@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// annotateCommand is the --annotate-cmd run to annotate the matched entries,
+// or "" for none.
+var annotateCommand string
+
+// pluginAnnotations holds what --annotate-cmd returned for the last walk,
+// keyed by path, or nil when it is not used.
+var pluginAnnotations map[string]pluginAnnotation
+
+// pluginRequest is written to the --annotate-cmd's stdin: every matched entry
+// and the directories above them, with paths relative to the root ("." for
+// the root itself) and forward slashes.
+type pluginRequest struct {
+	Root    string        `json:"root"`
+	Entries []pluginEntry `json:"entries"`
+}
+
+// pluginEntry describes one entry of a pluginRequest.
+type pluginEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+// pluginResponse is read from the --annotate-cmd's stdout. Entries it does
+// not mention are left as they are.
+type pluginResponse struct {
+	Annotations []pluginAnnotation `json:"annotations"`
+}
+
+// pluginAnnotation is what the --annotate-cmd has to say about one entry: a
+// label shown after it, a color as in $LS_COLORS (e.g. 01;31), and whether
+// to hide it, along with everything beneath a directory.
+type pluginAnnotation struct {
+	Path  string `json:"path"`
+	Label string `json:"label,omitempty"`
+	Color string `json:"color,omitempty"`
+	Hide  bool   `json:"hide,omitempty"`
+}
+
+// runAnnotateCommand runs --annotate-cmd on the matched paths beneath root
+// and stores its answer in pluginAnnotations.
+func runAnnotateCommand(root string, paths []string) error {
+	args := strings.Fields(annotateCommand)
+	if len(args) == 0 {
+		return fmt.Errorf("--annotate-cmd command is empty")
+	}
+
+	// The directories above the matches are drawn too, so they are sent along
+	request := pluginRequest{Root: root, Entries: []pluginEntry{}}
+	sent := map[string]bool{root: true}
+	add := func(path string) {
+		if info, err := lstatCached(path); err == nil {
+			request.Entries = append(request.Entries, pluginEntry{Path: pluginPath(root, path), Type: entryType(info), Size: info.Size()})
+		}
+	}
+	add(root)
+	for _, path := range paths {
+		for dir := filepath.Dir(path); !sent[dir] && isBelow(dir, root); dir = filepath.Dir(dir) {
+			sent[dir] = true
+			add(dir)
+		}
+		if !sent[path] {
+			sent[path] = true
+			add(path)
+		}
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("--annotate-cmd %q failed: %w", annotateCommand, err)
+	}
+	var response pluginResponse
+	if err := json.Unmarshal(output.Bytes(), &response); err != nil {
+		return fmt.Errorf("--annotate-cmd %q wrote invalid JSON: %w", annotateCommand, err)
+	}
+
+	pluginAnnotations = make(map[string]pluginAnnotation, len(response.Annotations))
+	for _, annotation := range response.Annotations {
+		pluginAnnotations[filepath.Join(root, filepath.FromSlash(annotation.Path))] = annotation
+	}
+	return nil
+}
+
+// pluginPath returns path as a pluginEntry has it: relative to root with
+// forward slashes, or "." for root itself.
+func pluginPath(root, path string) string {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// dropPluginHidden drops the paths the --annotate-cmd hid, and everything
+// beneath the directories it hid.
+func dropPluginHidden(root string, paths []string) []string {
+	var kept []string
+	for _, path := range paths {
+		hidden := false
+		for dir := path; isBelow(dir, root) && !hidden; dir = filepath.Dir(dir) {
+			hidden = pluginAnnotations[dir].Hide
+		}
+		if !hidden {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// pluginLabel returns the label the --annotate-cmd gave path, if any.
+func pluginLabel(path string) string {
+	return pluginAnnotations[path].Label
+}
+
+// pluginColor returns the color the --annotate-cmd gave a node, if any.
+func pluginColor(node *tree.Node) string {
+	if node == nil || node.Path == "" {
+		return ""
+	}
+	return pluginAnnotations[node.Path].Color
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestRunAnnotateCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command is a shell script")
+	}
+	root := t.TempDir()
+	for _, file := range []string{"src/main.go", "build/out.bin", "README.md"} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The command saves its input and hides build/, labeling and coloring main.go
+	dir := t.TempDir()
+	script := filepath.Join(dir, "annotate.sh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+cat > "$(dirname "$0")/request.json"
+echo '{"annotations": [{"path": "build", "hide": true}, {"path": "src/main.go", "label": "[lint: 2]", "color": "01;31"}]}'
+`), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalCommand, originalAnnotations := annotateCommand, pluginAnnotations
+	defer func() { annotateCommand, pluginAnnotations = originalCommand, originalAnnotations }()
+	annotateCommand = script
+
+	main := filepath.Join(root, "src", "main.go")
+	paths := []string{filepath.Join(root, "README.md"), filepath.Join(root, "build", "out.bin"), main}
+	if err := runAnnotateCommand(root, paths); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request pluginRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+	var sent []string
+	for _, entry := range request.Entries {
+		sent = append(sent, entry.Path+":"+entry.Type)
+	}
+	expected := []string{".:dir", "README.md:file", "build:dir", "build/out.bin:file", "src:dir", "src/main.go:file"}
+	if !slices.Equal(sent, expected) {
+		t.Errorf("the command was sent %v, expected %v", sent, expected)
+	}
+
+	if kept := dropPluginHidden(root, paths); !slices.Equal(kept, []string{paths[0], main}) {
+		t.Errorf("dropPluginHidden() = %v, expected build/ and its contents hidden", kept)
+	}
+	if label := pluginLabel(main); label != "[lint: 2]" {
+		t.Errorf("pluginLabel(main.go) = %q, expected %q", label, "[lint: 2]")
+	}
+
+	annotateCommand = "false"
+	if err := runAnnotateCommand(root, paths); err == nil {
+		t.Error("runAnnotateCommand() with a failing command returned no error")
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// anonymizedUser replaces path segments that match the current user name.
+const anonymizedUser = "user"
+
+// currentUserName returns the login name of the current user without any
+// Windows domain prefix, or an empty string if it cannot be determined.
+func currentUserName() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	name := u.Username
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// anonymizePath replaces the home directory prefix of path with "~" and any
+// remaining segment equal to the user name with a placeholder.
+func anonymizePath(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		if path == home {
+			return "~"
+		}
+		if strings.HasPrefix(path, home+string(filepath.Separator)) {
+			path = "~" + path[len(home):]
+		}
+	}
+
+	name := currentUserName()
+	if name == "" {
+		return path
+	}
+
+	parts := strings.Split(path, string(filepath.Separator))
+	for i, part := range parts {
+		if sameName(part, name) {
+			parts[i] = anonymizedUser
+		}
+	}
+	return strings.Join(parts, string(filepath.Separator))
+}
+
+// anonymizeName anonymizes a single file or directory name: the user name is
+// replaced, and names matching an --anonymize-hash pattern are replaced by a
+// short hash that keeps the extension.
+func anonymizeName(name string) string {
+	if userName := currentUserName(); userName != "" && sameName(name, userName) {
+		return anonymizedUser
+	}
+
+	for _, pattern := range anonymizeHashPatterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			ext := filepath.Ext(name)
+			sum := sha256.Sum256([]byte(name))
+			return hex.EncodeToString(sum[:])[:12] + ext
+		}
+	}
+	return name
+}
+
+// anonymizeRelPath applies anonymizeName to every segment of a slash-separated
+// relative path.
+func anonymizeRelPath(relPath string) string {
+	parts := strings.Split(relPath, "/")
+	for i, part := range parts {
+		parts[i] = anonymizeName(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// anonymizing reports whether any anonymization was requested.
+func anonymizing() bool {
+	return anonymize || len(anonymizeHashPatterns) > 0
+}
+
+// sameName compares names the way the platform's file system does.
+func sameName(a, b string) bool {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
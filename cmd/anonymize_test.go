@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizePath(t *testing.T) {
+	home := filepath.Join(string(filepath.Separator)+"homes", "someone")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"home directory", home, "~"},
+		{"inside home", filepath.Join(home, "code", "project"), filepath.Join("~", "code", "project")},
+		{"outside home", filepath.Join(string(filepath.Separator)+"srv", "project"), filepath.Join(string(filepath.Separator)+"srv", "project")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := anonymizePath(tt.path); result != tt.expected {
+				t.Errorf("anonymizePath(%q) = %q, expected %q", tt.path, result, tt.expected)
+			}
+		})
+	}
+
+	t.Run("user name segment", func(t *testing.T) {
+		userName := currentUserName()
+		if userName == "" {
+			t.Skip("current user name unavailable")
+		}
+		path := filepath.Join(string(filepath.Separator)+"data", userName, "project")
+		expected := filepath.Join(string(filepath.Separator)+"data", anonymizedUser, "project")
+		if result := anonymizePath(path); result != expected {
+			t.Errorf("anonymizePath(%q) = %q, expected %q", path, result, expected)
+		}
+	})
+}
+
+func TestAnonymizeName(t *testing.T) {
+	anonymizeHashPatterns = []string{"*.pdf", "client-*"}
+	defer func() { anonymizeHashPatterns = []string{} }()
+
+	t.Run("unmatched names are kept", func(t *testing.T) {
+		if result := anonymizeName("main.go"); result != "main.go" {
+			t.Errorf("anonymizeName(%q) = %q, expected it unchanged", "main.go", result)
+		}
+	})
+
+	t.Run("matched names are hashed with extension kept", func(t *testing.T) {
+		result := anonymizeName("salary-report.pdf")
+		if result == "salary-report.pdf" || !strings.HasSuffix(result, ".pdf") || len(result) != 12+len(".pdf") {
+			t.Errorf("anonymizeName(%q) = %q, expected a 12 character hash ending in .pdf", "salary-report.pdf", result)
+		}
+		if again := anonymizeName("salary-report.pdf"); again != result {
+			t.Errorf("anonymizeName() should be stable: got %q then %q", result, again)
+		}
+		if other := anonymizeName("other.pdf"); other == result {
+			t.Errorf("anonymizeName() returned the same hash %q for different names", result)
+		}
+	})
+
+	t.Run("relative paths", func(t *testing.T) {
+		result := anonymizeRelPath("client-acme/notes.txt")
+		if strings.Contains(result, "acme") || !strings.HasSuffix(result, "/notes.txt") {
+			t.Errorf("anonymizeRelPath() = %q, expected hashed directory and unchanged file", result)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveWriter adds files to an archive under slash-separated relative names.
+type archiveWriter interface {
+	add(name string, info os.FileInfo, src io.Reader) error
+	Close() error
+}
+
+// zipArchive writes entries to a zip file.
+type zipArchive struct {
+	zw *zip.Writer
+}
+
+func (a *zipArchive) add(name string, info os.FileInfo, src io.Reader) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	w, err := a.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
+
+func (a *zipArchive) Close() error {
+	return a.zw.Close()
+}
+
+// tarArchive writes entries to a tar stream, optionally gzip-compressed.
+type tarArchive struct {
+	tw *tar.Writer
+	gz *gzip.Writer
+}
+
+func (a *tarArchive) add(name string, info os.FileInfo, src io.Reader) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	if err := a.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(a.tw, src)
+	return err
+}
+
+func (a *tarArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	if a.gz != nil {
+		return a.gz.Close()
+	}
+	return nil
+}
+
+// archiveFormat picks the archive format from the destination's extension.
+func archiveFormat(dest string) (string, error) {
+	lower := strings.ToLower(dest)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip", nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz", nil
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar", nil
+	default:
+		return "", fmt.Errorf("unsupported archive format %q (use .zip, .tar.gz, .tgz, or .tar)", filepath.Ext(dest))
+	}
+}
+
+// newArchiveWriter returns a writer for the given archiveFormat result.
+func newArchiveWriter(format string, w io.Writer) archiveWriter {
+	switch format {
+	case "zip":
+		return &zipArchive{zw: zip.NewWriter(w)}
+	case "tar.gz":
+		gz := gzip.NewWriter(w)
+		return &tarArchive{tw: tar.NewWriter(gz), gz: gz}
+	default:
+		return &tarArchive{tw: tar.NewWriter(w)}
+	}
+}
+
+// writeArchive packages the matched files into dest, preserving their paths
+// relative to root. Directories in paths are skipped; only files are archived.
+func writeArchive(root string, paths []string, dest string) error {
+	format, err := archiveFormat(dest)
+	if err != nil {
+		return err
+	}
+
+	absDest, err := filepath.Abs(dest)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	archive := newArchiveWriter(format, out)
+
+	count := 0
+	for _, path := range paths {
+		// The archive may be created inside the tree it is packaging
+		if path == absDest {
+			continue
+		}
+
+		info, err := statCached(path)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if err := addArchiveFile(archive, filepath.ToSlash(relPath), path, info); err != nil {
+			return fmt.Errorf("failed to add %s: %w", relPath, err)
+		}
+		count++
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	info("Archive written to %s (%d files)", dest, count)
+	return nil
+}
+
+// addArchiveFile copies a single file into the archive.
+func addArchiveFile(archive archiveWriter, name, path string, info os.FileInfo) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return archive.add(name, info, f)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestArchiveFormat(t *testing.T) {
+	tests := []struct {
+		dest        string
+		expected    string
+		expectError bool
+	}{
+		{dest: "out.zip", expected: "zip"},
+		{dest: "OUT.ZIP", expected: "zip"},
+		{dest: "out.tar.gz", expected: "tar.gz"},
+		{dest: "out.tgz", expected: "tar.gz"},
+		{dest: "out.tar", expected: "tar"},
+		{dest: "out.rar", expectError: true},
+		{dest: "out", expectError: true},
+	}
+
+	for _, tt := range tests {
+		result, err := archiveFormat(tt.dest)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("archiveFormat(%q) expected error but got none", tt.dest)
+			}
+			continue
+		}
+		if err != nil || result != tt.expected {
+			t.Errorf("archiveFormat(%q) = %q, %v, expected %q", tt.dest, result, err, tt.expected)
+		}
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	testDir := setupTestDirectory(t)
+	defer os.RemoveAll(testDir)
+
+	outDir, err := os.MkdirTemp("", "wintree_archive_out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outDir)
+
+	paths := []string{
+		filepath.Join(testDir, "main.go"),
+		filepath.Join(testDir, "src"), // directories are skipped
+		filepath.Join(testDir, "src", "app.go"),
+		filepath.Join(testDir, "docs", "api.md"),
+	}
+	expected := []string{"docs/api.md", "main.go", "src/app.go"}
+
+	t.Run("zip", func(t *testing.T) {
+		dest := filepath.Join(outDir, "out.zip")
+		if err := writeArchive(testDir, paths, dest); err != nil {
+			t.Fatalf("writeArchive() error = %v", err)
+		}
+
+		zr, err := zip.OpenReader(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		assertArchiveNames(t, names, expected)
+	})
+
+	t.Run("tar.gz", func(t *testing.T) {
+		dest := filepath.Join(outDir, "out.tar.gz")
+		if err := writeArchive(testDir, paths, dest); err != nil {
+			t.Fatalf("writeArchive() error = %v", err)
+		}
+
+		f, err := os.Open(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		tr := tar.NewReader(gz)
+		for {
+			header, err := tr.Next()
+			if err != nil {
+				break
+			}
+			names = append(names, header.Name)
+		}
+		assertArchiveNames(t, names, expected)
+	})
+}
+
+// assertArchiveNames compares archive entry names ignoring order.
+func assertArchiveNames(t *testing.T, names, expected []string) {
+	t.Helper()
+	sort.Strings(names)
+	if len(names) != len(expected) {
+		t.Fatalf("archive contains %v, expected %v", names, expected)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("archive entry %d = %q, expected %q", i, names[i], expected[i])
+		}
+	}
+}
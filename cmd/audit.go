@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// findAuditPaths resolves the path argument and returns every matched entry
+// beneath it, for the subcommands that audit a tree rather than render it.
+// Like snapshots, audits cover the whole tree unless --depth is given.
+func findAuditPaths(cmd *cobra.Command, args []string) (string, []string, error) {
+	if err := validateFilterFlags(); err != nil {
+		return "", nil, err
+	}
+	startPath, err := resolveStartPath(args)
+	if err != nil {
+		return "", nil, err
+	}
+	if !cmd.Flags().Changed("depth") {
+		maxDepth = -1
+	}
+
+	if useSmartDefaults {
+		applySmartDefaults(startPath)
+	}
+
+	matchingFiles, err := findMatchingFiles(startPath, processFilters(excludePatterns, includePatterns))
+	if err != nil {
+		return "", nil, fmt.Errorf("error finding files: %w", err)
+	}
+	return startPath, matchingFiles, nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchFiles string
+	benchDepth int
+	benchDir   string
+	benchRuns  int
+	benchKeep  bool
+)
+
+// benchFilesPerDir is how many files each generated directory holds on
+// average.
+const benchFilesPerDir = 16
+
+// benchExtensions are cycled through for the generated file names.
+var benchExtensions = []string{".go", ".md", ".txt", ".json", ".js"}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure how fast wintree walks and renders a generated tree.",
+	Long: `Generate a synthetic tree of empty files in a temporary directory, walk and
+render it as wintree does, and report the throughput in entries per second.
+The fastest of --runs runs is reported, along with the version, platform, and
+number of jobs, so that results from different machines and filesystems can
+be compared when reporting a performance problem.
+
+The tree is generated under the system's temporary directory, or under --dir
+to measure another filesystem, such as a network drive, and is removed
+afterwards unless --keep is given.
+
+  wintree bench
+  wintree bench --files 100k --depth 8
+  wintree bench --files 20k --dir Z:\scratch --jobs 32`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := parseCount(benchFiles)
+		if err != nil || files < 1 {
+			cmd.SilenceUsage = false
+			return flagErrorf("invalid --files %q (use a count such as 5000, 10k, or 1M)", benchFiles)
+		}
+		if benchDepth < 1 {
+			cmd.SilenceUsage = false
+			return flagErrorf("invalid --depth %d (use 1 or more)", benchDepth)
+		}
+		if benchRuns < 1 {
+			cmd.SilenceUsage = false
+			return flagErrorf("invalid --runs %d (use 1 or more)", benchRuns)
+		}
+
+		root, err := os.MkdirTemp(benchDir, "wintree-bench-")
+		if err != nil {
+			return fmt.Errorf("failed to create the benchmark directory: %w", err)
+		}
+		if !benchKeep {
+			defer os.RemoveAll(root)
+		}
+
+		started := time.Now()
+		dirs, err := generateBenchTree(root, files, benchDepth)
+		if err != nil {
+			return fmt.Errorf("failed to generate the benchmark tree: %w", err)
+		}
+		entries := files + dirs
+		fmt.Printf("Generated %s and %s, %d levels deep, in %s under %s\n",
+			plural(files, "file", "files"), plural(dirs, "directory", "directories"), benchDepth, time.Since(started).Round(time.Millisecond), root)
+
+		// The whole tree is walked, as dump and the other audits do
+		maxDepth = -1
+		var walk, render time.Duration
+		for run := 0; run < benchRuns; run++ {
+			walkStarted := time.Now()
+			paths, err := findMatchingFiles(root, processFilters(nil, nil))
+			if err != nil {
+				return fmt.Errorf("error finding files: %w", err)
+			}
+			walked := time.Since(walkStarted)
+
+			renderStarted := time.Now()
+			if _, err := renderOutput(root, paths); err != nil {
+				return err
+			}
+			rendered := time.Since(renderStarted)
+
+			if run == 0 || walked < walk {
+				walk = walked
+			}
+			if run == 0 || rendered < render {
+				render = rendered
+			}
+		}
+
+		fmt.Printf("Walk:    %9s  %d entries/s\n", walk.Round(time.Microsecond), throughput(int64(entries), walk))
+		fmt.Printf("Render:  %9s  %d entries/s\n", render.Round(time.Microsecond), throughput(int64(entries), render))
+		fmt.Printf("Total:   %9s  %d entries/s\n", (walk + render).Round(time.Microsecond), throughput(int64(entries), walk+render))
+		fmt.Printf("Fastest of %s; wintree %s, %s/%s, %s, %s\n", plural(benchRuns, "run", "runs"), Version,
+			runtime.GOOS, runtime.GOARCH, plural(runtime.NumCPU(), "CPU", "CPUs"), plural(jobs(), "job", "jobs"))
+		if benchKeep {
+			fmt.Printf("The tree was kept at %s\n", root)
+		}
+		return nil
+	},
+}
+
+// parseCount parses a count such as 5000, 10k, or 1.5M, with k and M standing
+// for thousands and millions.
+func parseCount(value string) (int, error) {
+	number, scale := strings.TrimSpace(value), 1.0
+	switch {
+	case strings.HasSuffix(number, "k"), strings.HasSuffix(number, "K"):
+		number, scale = number[:len(number)-1], 1e3
+	case strings.HasSuffix(number, "m"), strings.HasSuffix(number, "M"):
+		number, scale = number[:len(number)-1], 1e6
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid count %q", value)
+	}
+	return int(n * scale), nil
+}
+
+// generateBenchTree creates files empty files beneath root in directories
+// nested depth levels deep, returning how many directories it created. The
+// directories form a balanced tree, with one branch reaching the full depth
+// however few there are, and the files are spread evenly across them.
+func generateBenchTree(root string, files, depth int) (int, error) {
+	dirCount := max(1, files/benchFilesPerDir)
+
+	// The smallest fanout that fits the directories within the depth
+	fanout := 2
+	for capacity(fanout, depth) < dirCount {
+		fanout++
+	}
+
+	var dirs []string
+	queue := []string{""}
+	for len(queue) > 0 && len(dirs) < dirCount {
+		parent := queue[0]
+		queue = queue[1:]
+		if parent != "" && strings.Count(parent, "/")+1 == depth {
+			continue
+		}
+		for i := 0; i < fanout && len(dirs) < dirCount; i++ {
+			dir := path.Join(parent, fmt.Sprintf("d%02d", i))
+			dirs = append(dirs, dir)
+			queue = append(queue, dir)
+		}
+	}
+	for dir := "d00"; strings.Count(dir, "/") < depth; dir += "/d00" {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			return 0, err
+		}
+	}
+
+	// The root holds files too, as real trees' roots do
+	holders := append([]string{""}, dirs...)
+	for i := 0; i < files; i++ {
+		name := fmt.Sprintf("f%06d%s", i, benchExtensions[i%len(benchExtensions)])
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(holders[i%len(holders)]), name), nil, 0644); err != nil {
+			return 0, err
+		}
+	}
+	return len(dirs), nil
+}
+
+// capacity returns how many directories a tree of the given fanout holds
+// below its root within depth levels.
+func capacity(fanout, depth int) int {
+	total, level := 0, 1
+	for i := 0; i < depth; i++ {
+		level *= fanout
+		total += level
+		if total > 1<<30 {
+			break
+		}
+	}
+	return total
+}
+
+func init() {
+	benchCmd.Flags().StringVarP(&benchFiles, "files", "", "10k", "Number of files to generate (e.g. 5000, 10k, 1M)")
+	benchCmd.Flags().IntVarP(&benchDepth, "depth", "d", 8, "Number of directory levels to generate")
+	benchCmd.Flags().StringVarP(&benchDir, "dir", "", "", "Generate the tree under this directory instead of the system's temporary directory")
+	benchCmd.Flags().IntVarP(&benchRuns, "runs", "", 3, "Number of times to walk and render the tree, reporting the fastest")
+	benchCmd.Flags().BoolVarP(&benchKeep, "keep", "", false, "Keep the generated tree instead of removing it")
+	benchCmd.Flags().IntVarP(&walkJobs, "jobs", "j", 0, "Number of directories to read at once (0 for one per CPU)")
+	rootCmd.AddCommand(benchCmd)
+}
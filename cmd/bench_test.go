@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCount(t *testing.T) {
+	tests := map[string]int{"5000": 5000, "10k": 10000, "1.5K": 1500, "1M": 1000000, " 2m ": 2000000}
+	for value, expected := range tests {
+		if n, err := parseCount(value); err != nil || n != expected {
+			t.Errorf("parseCount(%q) = %d, %v, expected %d", value, n, err, expected)
+		}
+	}
+	for _, value := range []string{"", "k", "ten", "-5"} {
+		if _, err := parseCount(value); err == nil {
+			t.Errorf("parseCount(%q) succeeded, expected an error", value)
+		}
+	}
+}
+
+func TestGenerateBenchTree(t *testing.T) {
+	root := t.TempDir()
+
+	// Too few directories to fill the levels still reach the full depth
+	dirs, err := generateBenchTree(root, 40, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var files, dirsFound, deepest int
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+		relPath, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			dirsFound++
+			deepest = max(deepest, strings.Count(relPath, string(filepath.Separator))+1)
+		} else {
+			files++
+		}
+		return nil
+	})
+	if files != 40 || dirsFound != dirs || deepest != 4 {
+		t.Errorf("generateBenchTree() made %d files and %d directories (reported %d), %d deep; expected 40 files, 4 deep",
+			files, dirsFound, dirs, deepest)
+	}
+}
@@ -55,6 +55,37 @@ func BenchmarkFindMatchingFiles(b *testing.B) {
 	}
 }
 
+func BenchmarkFindMatchingFilesJobs(b *testing.B) {
+	tempDir := b.TempDir()
+	for i := 0; i < 50; i++ {
+		for j := 0; j < 10; j++ {
+			file := filepath.Join(tempDir, fmt.Sprintf("pkg%d", i), fmt.Sprintf("sub%d", j), "file.go")
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				b.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte("content"), 0644); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	originalDepth, originalJobs := maxDepth, walkJobs
+	defer func() { maxDepth, walkJobs = originalDepth, originalJobs }()
+	maxDepth = -1
+	filters := processFilters(nil, nil)
+
+	for _, jobs := range []int{1, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			walkJobs = jobs
+			for i := 0; i < b.N; i++ {
+				if _, err := findMatchingFiles(tempDir, filters); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkBuildTreeOutput(b *testing.B) {
 	tempDir, err := os.MkdirTemp("", "tree_benchmark")
 	if err != nil {
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// binaryThreshold is the --flag-binaries size, such as "100K". Empty disables
+// the check.
+var binaryThreshold string
+
+// defaultBinaryThreshold is the size --flag-binaries uses without a value.
+const defaultBinaryThreshold = "100K"
+
+// dataLanguages are the languageHints of files that do not make a directory a
+// source directory, since documentation and data live alongside binaries.
+var dataLanguages = map[string]bool{
+	"markdown": true, "json": true, "yaml": true, "toml": true, "xml": true,
+}
+
+// flaggedBinaries maps every binary found by findBinaries to its size. It is
+// only populated by --flag-binaries.
+var flaggedBinaries = make(map[string]int64)
+
+// findBinaries walks the whole of root, skipping excluded entries and .git,
+// and records the binary files larger than threshold in a source directory.
+// It returns them relative to root, largest first.
+func findBinaries(root string, threshold int64, f filter) ([]string, error) {
+	clear(flaggedBinaries)
+
+	opts := treeOptions(f)
+	opts.MaxDepth, opts.DirsDepth = -1, 0
+	opts.Exclude = append(slices.Clone(opts.Exclude), ".git")
+	paths, err := tree.NewWalker(opts).Walk(root)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceDirs := make(map[string]bool)
+	var found []string
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Size() <= threshold {
+			continue
+		}
+		dir := filepath.Dir(path)
+		isSource, ok := sourceDirs[dir]
+		if !ok {
+			isSource = isSourceDir(dir)
+			sourceDirs[dir] = isSource
+		}
+		if !isSource || !isBinaryFile(path) {
+			continue
+		}
+		flaggedBinaries[path] = info.Size()
+		found = append(found, path)
+	}
+
+	slices.SortStableFunc(found, func(a, b string) int {
+		return cmp.Compare(flaggedBinaries[b], flaggedBinaries[a])
+	})
+	for i, path := range found {
+		if relPath, err := filepath.Rel(root, path); err == nil {
+			found[i] = filepath.ToSlash(relPath)
+		}
+	}
+	return found, nil
+}
+
+// isSourceDir reports whether dir directly contains any source code file,
+// judged by extension.
+func isSourceDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			if lang := languageHint(entry.Name()); lang != "" && !dataLanguages[lang] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isBinaryFile reports whether a file looks binary: like git, whether its
+// first 8000 bytes contain a NUL byte.
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	head := make([]byte, 8000)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false
+	}
+	return bytes.IndexByte(head[:n], 0) >= 0
+}
+
+// binaryAnnotation marks a flagged binary file with its size.
+func binaryAnnotation(path string) string {
+	size, ok := flaggedBinaries[path]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("[binary, %s]", formatSize(size))
+}
+
+// binaryReport lists the flagged binaries, one per line, for stderr.
+func binaryReport(root string, found []string) string {
+	var report bytes.Buffer
+	report.WriteString("Binary files in source directories:\n")
+	for _, relPath := range found {
+		size := flaggedBinaries[filepath.Join(root, filepath.FromSlash(relPath))]
+		fmt.Fprintf(&report, "  %s (%s)\n", relPath, formatSize(size))
+	}
+	return report.String()
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindBinaries(t *testing.T) {
+	tempDir := t.TempDir()
+	binary := func(size int) []byte {
+		content := make([]byte, size)
+		for i := range content {
+			content[i] = byte(i % 7)
+		}
+		return content
+	}
+	for file, content := range map[string][]byte{
+		"src/main.go":         []byte("package main\n"),
+		"src/tool.exe":        binary(4096),
+		"src/bigger.so":       binary(8192),
+		"src/small.bin":       binary(100),
+		"src/generated.txt":   []byte(strings.Repeat("text\n", 1000)),
+		"assets/logo.png":     binary(8192),
+		"assets/README.md":    []byte("# Assets\n"),
+		"vendor/lib/x.go":     []byte("package lib\n"),
+		"vendor/lib/blob.bin": binary(8192),
+		".git/objects/pack":   binary(8192),
+		".git/hooks.go":       []byte("package hooks\n"),
+	} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer clear(flaggedBinaries)
+
+	found, err := findBinaries(tempDir, 1024, processFilters([]string{"vendor"}, nil))
+	if err != nil {
+		t.Fatalf("findBinaries() error = %v", err)
+	}
+	if expected := []string{"src/bigger.so", "src/tool.exe"}; !reflect.DeepEqual(found, expected) {
+		t.Errorf("findBinaries() = %v, expected %v", found, expected)
+	}
+
+	if annotation := binaryAnnotation(filepath.Join(tempDir, "src", "tool.exe")); annotation != "[binary, 4.0 KB]" {
+		t.Errorf("binaryAnnotation() = %q, expected %q", annotation, "[binary, 4.0 KB]")
+	}
+	if annotation := binaryAnnotation(filepath.Join(tempDir, "assets", "logo.png")); annotation != "" {
+		t.Errorf("binaryAnnotation() outside a source directory = %q, expected none", annotation)
+	}
+
+	expected := "Binary files in source directories:\n  src/bigger.so (8.0 KB)\n  src/tool.exe (4.0 KB)\n"
+	if report := binaryReport(tempDir, found); report != expected {
+		t.Errorf("binaryReport() =\n%s\nexpected:\n%s", report, expected)
+	}
+}
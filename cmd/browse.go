@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/atotto/clipboard"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// openWithCommand is the --open-with command used to open the selected entry.
+var openWithCommand string
+
+// Keys recognized by the browser, besides printable characters.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyInterrupt = "ctrl-c"
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse [path]",
+	Short: "Explore the tree interactively.",
+	Long: `Open the tree in an interactive, full-screen view.
+
+  up/down, j/k    move the selection
+  pgup/pgdn       move a page at a time
+  g/G             jump to the first or last entry
+  /               search names as you type; enter keeps the match, esc cancels
+  n/N             jump to the next or previous match
+  y               copy the selected path to the clipboard
+  o, enter        open the selected entry
+  q               quit
+
+Entries open in the system file manager, or with --open-with in any command,
+such as an editor. The path is appended to the command, or replaces {} if the
+command contains it:
+
+  wintree browse --open-with code
+  wintree browse --open-with "vim -R {}"
+
+Unlike the tree view, the whole tree is loaded unless --depth is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("browse requires an interactive terminal")
+		}
+
+		startPath, err := resolveStartPath(args)
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("depth") {
+			maxDepth = -1
+		}
+
+		if useSmartDefaults {
+			applySmartDefaults(startPath)
+		}
+
+		matchingFiles, err := findMatchingFiles(startPath, processFilters(excludePatterns, includePatterns))
+		if err != nil {
+			return fmt.Errorf("error finding files: %w", err)
+		}
+
+		return newBrowser(buildTreeRows(startPath, matchingFiles)).run()
+	},
+}
+
+// browser is the state of the interactive tree view.
+type browser struct {
+	rows  []treeRow
+	lines []string
+
+	cursor int
+	offset int
+	height int
+
+	searching bool
+	query     string
+	// searchFrom is where the current search started, so that narrowing the
+	// query can move the match back up
+	searchFrom int
+	status     string
+
+	// suspend hands the terminal back for the duration of action, so that
+	// terminal editors opened with --open-with can use it
+	suspend func(action func() error) error
+}
+
+// newBrowser prepares a browser for the given rows, rendered with the same
+// metadata columns as the tree view.
+func newBrowser(rows []treeRow) *browser {
+	lines := strings.Split(strings.TrimSuffix(formatTreeRows(rows), "\n"), "\n")
+	return &browser{rows: rows, lines: lines}
+}
+
+// run takes over the terminal until the user quits.
+func (b *browser) run() error {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	// Use the alternate screen so the shell's scrollback is left untouched
+	fmt.Print("\033[?1049h\033[?25l")
+	defer fmt.Print("\033[?25h\033[?1049l")
+
+	b.suspend = func(action func() error) error {
+		fmt.Print("\033[?25h\033[?1049l")
+		term.Restore(fd, state)
+		err := action()
+		if _, rawErr := term.MakeRaw(fd); rawErr != nil && err == nil {
+			err = rawErr
+		}
+		fmt.Print("\033[?1049h\033[?25l")
+		return err
+	}
+
+	buf := make([]byte, 16)
+	for {
+		_, height, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil || height < 2 {
+			height = 24
+		}
+		b.height = height - 1
+		b.draw()
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			if !b.handleKey(key) {
+				return nil
+			}
+		}
+	}
+}
+
+// parseKeys splits the bytes of a single read into key presses. Escape
+// sequences arrive whole, but fast typing and pasting deliver several
+// characters at once.
+func parseKeys(input []byte) []string {
+	if len(input) > 0 && input[0] == 0x1b {
+		return []string{parseKey(input)}
+	}
+
+	var keys []string
+	for len(input) > 0 {
+		_, size := utf8.DecodeRune(input)
+		keys = append(keys, parseKey(input[:size]))
+		input = input[size:]
+	}
+	return keys
+}
+
+// parseKey translates the bytes of a single key press into a key name, or
+// the character itself for printable keys.
+func parseKey(input []byte) string {
+	switch string(input) {
+	case "\x1b[A", "\x1bOA":
+		return keyUp
+	case "\x1b[B", "\x1bOB":
+		return keyDown
+	case "\x1b[5~":
+		return keyPageUp
+	case "\x1b[6~":
+		return keyPageDown
+	case "\x1b[H", "\x1b[1~", "\x1bOH":
+		return keyHome
+	case "\x1b[F", "\x1b[4~", "\x1bOF":
+		return keyEnd
+	case "\r", "\n":
+		return keyEnter
+	case "\x1b":
+		return keyEscape
+	case "\x7f", "\b":
+		return keyBackspace
+	case "\x03":
+		return keyInterrupt
+	}
+	return string(input)
+}
+
+// handleKey applies a key press and reports whether the browser should keep
+// running.
+func (b *browser) handleKey(key string) bool {
+	b.status = ""
+
+	if key == keyInterrupt {
+		return false
+	}
+
+	if b.searching {
+		switch key {
+		case keyEnter:
+			b.searching = false
+		case keyEscape:
+			b.searching = false
+			b.query = ""
+			b.moveTo(b.searchFrom)
+		case keyBackspace:
+			if b.query != "" {
+				_, size := utf8.DecodeLastRuneInString(b.query)
+				b.query = b.query[:len(b.query)-size]
+				b.jumpToMatch(b.searchFrom, true)
+			}
+		default:
+			if isPrintable(key) {
+				b.query += key
+				b.jumpToMatch(b.searchFrom, true)
+			}
+		}
+		return true
+	}
+
+	switch key {
+	case "q", keyEscape:
+		return false
+	case keyUp, "k":
+		b.moveTo(b.cursor - 1)
+	case keyDown, "j":
+		b.moveTo(b.cursor + 1)
+	case keyPageUp:
+		b.moveTo(b.cursor - b.height)
+	case keyPageDown:
+		b.moveTo(b.cursor + b.height)
+	case keyHome, "g":
+		b.moveTo(0)
+	case keyEnd, "G":
+		b.moveTo(len(b.rows) - 1)
+	case "/":
+		b.searching = true
+		b.query = ""
+		b.searchFrom = b.cursor
+	case "n":
+		b.jumpToMatch(b.cursor+1, true)
+	case "N":
+		b.jumpToMatch(b.cursor-1, false)
+	case "y":
+		if err := clipboard.WriteAll(b.selectedPath()); err != nil {
+			b.status = "Failed to copy: " + err.Error()
+		} else {
+			b.status = "Copied " + b.selectedPath()
+		}
+	case "o", keyEnter:
+		if err := b.openSelected(); err != nil {
+			b.status = "Failed to open: " + err.Error()
+		}
+	}
+	return true
+}
+
+// openSelected opens the selected entry with --open-with, or shows it in the
+// system file manager.
+func (b *browser) openSelected() error {
+	path := b.selectedPath()
+	if openWithCommand == "" {
+		return openInFileManager(path)
+	}
+
+	open := func() error { return runOpenWith(openWithCommand, path) }
+	if b.suspend == nil {
+		return open()
+	}
+	return b.suspend(open)
+}
+
+// moveTo selects the row at index, clamped to the tree, scrolling it into view.
+func (b *browser) moveTo(index int) {
+	b.cursor = max(0, min(index, len(b.rows)-1))
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.height > 0 && b.cursor >= b.offset+b.height {
+		b.offset = b.cursor - b.height + 1
+	}
+}
+
+// jumpToMatch selects the nearest row from index whose name contains the
+// query, searching forward or backward and wrapping around.
+func (b *browser) jumpToMatch(index int, forward bool) {
+	if b.query == "" {
+		b.moveTo(b.searchFrom)
+		return
+	}
+	if match := findMatch(b.rows, b.query, index, forward); match >= 0 {
+		b.moveTo(match)
+	} else {
+		b.status = "No match for " + b.query
+	}
+}
+
+// findMatch returns the index of the first row at or after from (or at or
+// before it, when searching backward) whose name contains query, ignoring
+// case and wrapping around the ends. It returns -1 if nothing matches.
+func findMatch(rows []treeRow, query string, from int, forward bool) int {
+	query = strings.ToLower(query)
+	for i := range rows {
+		index := from + i
+		if !forward {
+			index = from - i
+		}
+		index = ((index % len(rows)) + len(rows)) % len(rows)
+		if strings.Contains(strings.ToLower(rows[index].name), query) {
+			return index
+		}
+	}
+	return -1
+}
+
+// selectedPath returns the path of the selected row.
+func (b *browser) selectedPath() string {
+	return b.rows[b.cursor].path
+}
+
+// draw redraws the visible part of the tree with the selection highlighted,
+// and a status line at the bottom.
+func (b *browser) draw() {
+	var screen strings.Builder
+	screen.WriteString("\033[H\033[2J")
+
+	end := min(b.offset+b.height, len(b.lines))
+	for i := b.offset; i < end; i++ {
+		if i == b.cursor {
+			// Keep the highlight on after the resets that end colored names
+			screen.WriteString("\033[7m" + strings.ReplaceAll(b.lines[i], "\033[0m", "\033[0;7m") + "\033[0m")
+		} else {
+			screen.WriteString(b.lines[i])
+		}
+		screen.WriteString("\r\n")
+	}
+	for i := end - b.offset; i < b.height; i++ {
+		screen.WriteString("\r\n")
+	}
+
+	switch {
+	case b.searching:
+		screen.WriteString("/" + b.query)
+	case b.status != "":
+		screen.WriteString(b.status)
+	default:
+		screen.WriteString(fmt.Sprintf("%d/%d  / search  y copy path  o open  q quit", b.cursor+1, len(b.rows)))
+	}
+
+	fmt.Print(screen.String())
+}
+
+// openInFileManager reveals path in the system file manager: Explorer on
+// Windows, Finder on macOS, and the default handler for its directory
+// elsewhere.
+func openInFileManager(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", "/select,"+path)
+	case "darwin":
+		cmd = exec.Command("open", "-R", path)
+	default:
+		dir := path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+		cmd = exec.Command("xdg-open", dir)
+	}
+	return cmd.Start()
+}
+
+// runOpenWith runs the --open-with command on path, attached to the terminal
+// and waiting for it to exit.
+func runOpenWith(command, path string) error {
+	args := openWithArgs(command, path)
+	if len(args) == 0 {
+		return fmt.Errorf("--open-with command is empty")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// openWithArgs splits command into arguments and substitutes path for every
+// {} placeholder, or appends it if there is none.
+func openWithArgs(command, path string) []string {
+	args := strings.Fields(command)
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{}") {
+			args[i] = strings.ReplaceAll(arg, "{}", path)
+			substituted = true
+		}
+	}
+	if !substituted && len(args) > 0 {
+		args = append(args, path)
+	}
+	return args
+}
+
+// isPrintable reports whether key is a single printable character rather
+// than a control sequence.
+func isPrintable(key string) bool {
+	r, size := utf8.DecodeRuneInString(key)
+	return size == len(key) && r >= ' ' && r != 0x7f
+}
+
+func init() {
+	addFilterFlags(browseCmd.Flags())
+	browseCmd.Flags().StringVarP(&openWithCommand, "open-with", "", "", "Open the selected entry with this command (e.g. code, vim) instead of the file manager")
+	rootCmd.AddCommand(browseCmd)
+}
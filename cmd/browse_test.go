@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"\x1b[A", keyUp},
+		{"\x1bOB", keyDown},
+		{"\x1b[6~", keyPageDown},
+		{"\r", keyEnter},
+		{"\x1b", keyEscape},
+		{"\x7f", keyBackspace},
+		{"\x03", keyInterrupt},
+		{"j", "j"},
+		{"é", "é"},
+	}
+
+	for _, tt := range tests {
+		if result := parseKey([]byte(tt.input)); result != tt.expected {
+			t.Errorf("parseKey(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("/ré\r"))
+	expected := []string{"/", "r", "é", keyEnter}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("parseKeys() = %q, expected %q", keys, expected)
+	}
+
+	if keys := parseKeys([]byte("\x1b[B")); !reflect.DeepEqual(keys, []string{keyDown}) {
+		t.Errorf("parseKeys() split an escape sequence: %q", keys)
+	}
+}
+
+func TestFindMatch(t *testing.T) {
+	rows := []treeRow{
+		{name: "project"},
+		{name: "Main.go"},
+		{name: "src"},
+		{name: "main_test.go"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		from     int
+		forward  bool
+		expected int
+	}{
+		{"case-insensitive forward", "main", 0, true, 1},
+		{"forward from a match skips ahead", "main", 2, true, 3},
+		{"forward wraps around", "main", 3 + 1, true, 1},
+		{"backward", "main", 2, false, 1},
+		{"backward wraps around", "test", 0, false, 3},
+		{"no match", "missing", 0, true, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := findMatch(rows, tt.query, tt.from, tt.forward); result != tt.expected {
+				t.Errorf("findMatch(%q, %d) = %d, expected %d", tt.query, tt.from, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBrowserSearch(t *testing.T) {
+	b := &browser{
+		rows:   []treeRow{{name: "project"}, {name: "lib"}, {name: "src"}, {name: "server.go"}},
+		height: 10,
+	}
+
+	for _, key := range []string{"/", "s", "e"} {
+		b.handleKey(key)
+	}
+	if b.cursor != 3 {
+		t.Errorf("searching %q selected row %d, expected 3", b.query, b.cursor)
+	}
+
+	// Narrowing back to "s" moves to the first match again
+	b.handleKey(keyBackspace)
+	if b.cursor != 2 {
+		t.Errorf("after backspace selected row %d, expected 2", b.cursor)
+	}
+
+	b.handleKey(keyEnter)
+	b.handleKey("n")
+	if b.cursor != 3 {
+		t.Errorf("n selected row %d, expected 3", b.cursor)
+	}
+
+	b.handleKey("/")
+	b.handleKey("d")
+	b.handleKey(keyEscape)
+	if b.cursor != 3 || b.searching {
+		t.Errorf("escape should cancel the search and restore the selection, got row %d", b.cursor)
+	}
+
+	if b.handleKey("q") {
+		t.Error("q should quit the browser")
+	}
+}
+
+func TestOpenWithArgs(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{"code", []string{"code", "/tmp/a b.go"}},
+		{"vim -R", []string{"vim", "-R", "/tmp/a b.go"}},
+		{"code --goto {}:1", []string{"code", "--goto", "/tmp/a b.go:1"}},
+		{"  ", []string{}},
+	}
+
+	for _, tt := range tests {
+		if result := openWithArgs(tt.command, "/tmp/a b.go"); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("openWithArgs(%q) = %q, expected %q", tt.command, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// budgetRules are the --budget values, such as "assets=200MB".
+var budgetRules []string
+
+// sizeBudget is a parsed --budget rule: the most that path may take up.
+type sizeBudget struct {
+	// relPath is the directory the rule names, relative to the root
+	relPath string
+	limit   int64
+}
+
+// budgetUsage is the measured size of a directory with a budget.
+type budgetUsage struct {
+	size, limit int64
+}
+
+// budgetUsages maps each directory with a budget to its measured size. It is
+// populated by checkBudgets.
+var budgetUsages = make(map[string]budgetUsage)
+
+// sizeUnits are the suffixes accepted by parseSize. Like the sizes shown by
+// --size, every unit is a power of 1024.
+var sizeUnits = []struct {
+	suffix string
+	scale  int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30}, {"TIB", 1 << 40},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"TB", 1 << 40},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+	{"B", 1},
+}
+
+// parseSize parses a size such as "200MB", "1.5G", or "4096".
+func parseSize(value string) (int64, error) {
+	number, scale := strings.ToUpper(strings.TrimSpace(value)), int64(1)
+	for _, unit := range sizeUnits {
+		if trimmed, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, scale = strings.TrimSpace(trimmed), unit.scale
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 200MB or 1.5G)", value)
+	}
+	return int64(n * float64(scale)), nil
+}
+
+// parseBudgets parses --budget rules of the form PATH=SIZE.
+func parseBudgets(rules []string) ([]sizeBudget, error) {
+	var budgets []sizeBudget
+	for _, rule := range rules {
+		path, size, ok := strings.Cut(rule, "=")
+		path = strings.Trim(filepath.ToSlash(strings.TrimSpace(path)), "/")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid --budget %q (use PATH=SIZE, e.g. assets=200MB)", rule)
+		}
+		limit, err := parseSize(size)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --budget %q: %w", rule, err)
+		}
+		budgets = append(budgets, sizeBudget{relPath: path, limit: limit})
+	}
+	return budgets, nil
+}
+
+// checkBudgets measures every directory with a budget beneath root, whether
+// or not it is shown, and describes each one over its budget.
+func checkBudgets(root string, budgets []sizeBudget) ([]string, error) {
+	clear(budgetUsages)
+
+	var exceeded []string
+	for _, budget := range budgets {
+		path := filepath.Join(root, filepath.FromSlash(budget.relPath))
+		if _, err := os.Lstat(path); err != nil {
+			return nil, fmt.Errorf("--budget path %s: %w", budget.relPath, err)
+		}
+		size, err := nodeSize(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure %s: %w", budget.relPath, err)
+		}
+
+		budgetUsages[path] = budgetUsage{size: size, limit: budget.limit}
+		if size > budget.limit {
+			exceeded = append(exceeded, fmt.Sprintf("%s is %s, over its budget of %s",
+				budget.relPath, formatSize(size), formatSize(budget.limit)))
+		}
+	}
+	return exceeded, nil
+}
+
+// budgetAnnotation marks a directory with a budget with how much of it is
+// used, or by how much it is exceeded.
+func budgetAnnotation(path string) string {
+	usage, ok := budgetUsages[path]
+	if !ok {
+		return ""
+	}
+	if usage.size > usage.limit {
+		return fmt.Sprintf("[over budget: %s of %s]", formatSize(usage.size), formatSize(usage.limit))
+	}
+	percent := 100.0
+	if usage.limit > 0 {
+		percent = float64(usage.size) / float64(usage.limit) * 100
+	}
+	return fmt.Sprintf("[%.0f%% of %s budget]", percent, formatSize(usage.limit))
+}
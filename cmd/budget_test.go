@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"4096":   4096,
+		"200MB":  200 << 20,
+		"1.5G":   3 << 29,
+		"512kib": 512 << 10,
+		"10 B":   10,
+	}
+	for value, expected := range tests {
+		size, err := parseSize(value)
+		if err != nil {
+			t.Errorf("parseSize(%q) error = %v", value, err)
+		} else if size != expected {
+			t.Errorf("parseSize(%q) = %d, expected %d", value, size, expected)
+		}
+	}
+
+	for _, value := range []string{"", "MB", "-1K", "12XB"} {
+		if _, err := parseSize(value); err == nil {
+			t.Errorf("parseSize(%q) expected an error", value)
+		}
+	}
+	for _, rule := range []string{"assets", "=10MB", "assets=big"} {
+		if _, err := parseBudgets([]string{rule}); err == nil {
+			t.Errorf("parseBudgets(%q) expected an error", rule)
+		}
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, size := range map[string]int{"assets/logo.png": 3000, "assets/img/bg.png": 2000, "src/main.go": 100} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apparentSize = true
+	defer func() {
+		apparentSize = false
+		clear(sizeCache)
+		clear(budgetUsages)
+	}()
+
+	budgets, err := parseBudgets([]string{"assets/=4KB", "src=400"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exceeded, err := checkBudgets(tempDir, budgets)
+	if err != nil {
+		t.Fatalf("checkBudgets() error = %v", err)
+	}
+	if expected := []string{"assets is 4.9 KB, over its budget of 4.0 KB"}; !reflect.DeepEqual(exceeded, expected) {
+		t.Errorf("checkBudgets() = %q, expected %q", exceeded, expected)
+	}
+
+	annotations := map[string]string{
+		filepath.Join(tempDir, "assets"): "[over budget: 4.9 KB of 4.0 KB]",
+		filepath.Join(tempDir, "src"):    "[25% of 400 B budget]",
+		tempDir:                          "",
+	}
+	for path, expected := range annotations {
+		if result := budgetAnnotation(path); result != expected {
+			t.Errorf("budgetAnnotation(%s) = %q, expected %q", path, result, expected)
+		}
+	}
+
+	if _, err := checkBudgets(tempDir, []sizeBudget{{relPath: "missing", limit: 1}}); err == nil {
+		t.Error("checkBudgets() expected an error for a missing directory")
+	}
+}
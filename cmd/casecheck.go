@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// caseConflict is a set of names in one directory that differ only by case.
+type caseConflict struct {
+	dir   string
+	names []string
+}
+
+var caseCheckCmd = &cobra.Command{
+	Use:   "case-check [path]",
+	Short: "Find names that differ only by case.",
+	Long: `Report files and directories whose names differ only by case within the
+same directory. Such trees work on Linux but cannot be checked out correctly
+on Windows or macOS, where one entry silently overwrites the other.
+
+The whole tree is checked unless --depth is given. The command exits with a
+non-zero status when conflicts are found.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		startPath, matchingFiles, err := findAuditPaths(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		conflicts := findCaseConflicts(startPath, matchingFiles)
+		if len(conflicts) == 0 {
+			fmt.Println("No case conflicts found.")
+			return nil
+		}
+
+		for _, conflict := range conflicts {
+			fmt.Printf("%s:\n", conflict.dir)
+			for _, name := range conflict.names {
+				fmt.Printf("  %s\n", name)
+			}
+		}
+		return fmt.Errorf("%d case conflicts found", len(conflicts))
+	},
+}
+
+// findCaseConflicts groups the names in each directory by their lowercase
+// form and returns every group with more than one spelling, sorted by
+// directory. Directories are shown relative to root.
+func findCaseConflicts(root string, paths []string) []caseConflict {
+	groups := make(map[string]map[string][]string)
+	for _, path := range paths {
+		dir, name := filepath.Dir(path), filepath.Base(path)
+		if groups[dir] == nil {
+			groups[dir] = make(map[string][]string)
+		}
+		folded := strings.ToLower(name)
+		groups[dir][folded] = append(groups[dir][folded], name)
+	}
+
+	var conflicts []caseConflict
+	for dir, names := range groups {
+		relDir, err := filepath.Rel(root, dir)
+		if err != nil {
+			relDir = dir
+		}
+		for _, group := range names {
+			if len(group) > 1 {
+				sort.Strings(group)
+				conflicts = append(conflicts, caseConflict{dir: filepath.ToSlash(relDir), names: group})
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].dir != conflicts[j].dir {
+			return conflicts[i].dir < conflicts[j].dir
+		}
+		return conflicts[i].names[0] < conflicts[j].names[0]
+	})
+	return conflicts
+}
+
+func init() {
+	addFilterFlags(caseCheckCmd.Flags())
+	rootCmd.AddCommand(caseCheckCmd)
+}
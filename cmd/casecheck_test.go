@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindCaseConflicts(t *testing.T) {
+	root := filepath.Join("project")
+	var paths []string
+	for _, relPath := range []string{"README.md", "Readme.md", "src", "SRC", "src/a.go", "src/A.go", "src/b.go", "docs/readme.md"} {
+		paths = append(paths, filepath.Join(root, filepath.FromSlash(relPath)))
+	}
+
+	expected := []caseConflict{
+		{dir: ".", names: []string{"README.md", "Readme.md"}},
+		{dir: ".", names: []string{"SRC", "src"}},
+		{dir: "src", names: []string{"A.go", "a.go"}},
+	}
+	if result := findCaseConflicts(root, paths); !reflect.DeepEqual(result, expected) {
+		t.Errorf("findCaseConflicts() = %+v, expected %+v", result, expected)
+	}
+
+	if result := findCaseConflicts(root, paths[6:]); len(result) != 0 {
+		t.Errorf("findCaseConflicts() = %+v, expected no conflicts", result)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"golang.org/x/term"
+)
+
+// charsetMode is the --charset used to draw trees: auto, utf8 (or its alias
+// unicode), or ascii.
+var charsetMode string
+
+// asciiChars is --ascii, shorthand for --charset ascii.
+var asciiChars bool
+
+// glyphs returns the charset for the current output.
+func glyphs() tree.Charset {
+	if asciiOutput() {
+		return tree.ASCII
+	}
+	return tree.UTF8
+}
+
+// asciiOutput reports whether output must stick to ASCII. With --charset auto,
+// that is only the case when writing to a terminal that cannot display UTF-8,
+// such as a Windows console on a legacy code page; files and the clipboard
+// always get UTF-8.
+func asciiOutput() bool {
+	if asciiChars {
+		return true
+	}
+	switch charsetMode {
+	case "ascii":
+		return true
+	case "utf8", "unicode":
+		return false
+	}
+	if copyToClipboard || outputFile != "" || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return false
+	}
+	return !terminalSupportsUTF8()
+}
+
+// localeSupportsUTF8 reports whether the POSIX locale in effect uses UTF-8.
+// An unset locale is assumed to, as modern terminals do.
+func localeSupportsUTF8() bool {
+	if value := envLocale("LC_CTYPE"); value != "" {
+		value = strings.ToLower(value)
+		return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+	}
+	return true
+}
+
+// envLocale returns the locale the environment sets for a category such as
+// LC_CTYPE, taking LC_ALL first and LANG last as setlocale does, or "" if
+// none is set.
+func envLocale(category string) string {
+	for _, name := range []string{"LC_ALL", category, "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cmd
+
+// terminalSupportsUTF8 reports whether the terminal displays UTF-8, going by
+// the locale.
+func terminalSupportsUTF8() bool {
+	return localeSupportsUTF8()
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildTreeOutputASCII(t *testing.T) {
+	originalCharset, originalLabel := charsetMode, treeLabel
+	defer func() { charsetMode, treeLabel = originalCharset, originalLabel }()
+	charsetMode = "ascii"
+	treeLabel = "project"
+
+	root := "project"
+	output := buildTreeOutput(root, []string{
+		filepath.Join(root, "src"),
+		filepath.Join(root, "src", "lib.go"),
+		filepath.Join(root, "src", "main.go"),
+	})
+
+	expected := "project\n" +
+		"`-- src\n" +
+		"    |-- lib.go\n" +
+		"    `-- main.go\n"
+	if output != expected {
+		t.Errorf("buildTreeOutput() =\n%s\nexpected:\n%s", output, expected)
+	}
+
+	output = buildVirtualTreeOutput("all", []string{"a", "b"}, [][]string{
+		{filepath.Join("a", "x.go")},
+		{filepath.Join("b", "y.go")},
+	})
+	expected = "all\n" +
+		"|-- a\n" +
+		"|   `-- x.go\n" +
+		"`-- b\n" +
+		"    `-- y.go\n"
+	if output != expected {
+		t.Errorf("buildVirtualTreeOutput() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestASCIIFallbacks(t *testing.T) {
+	originalCharset := charsetMode
+	defer func() { charsetMode = originalCharset }()
+	charsetMode = "ascii"
+
+	if bar := sizeBar(0.75); bar != "########  " {
+		t.Errorf("sizeBar(0.75) = %q, expected %q", bar, "########  ")
+	}
+	if name := ellipsize("a-very-long-name.txt", 12); name != "a-very-~.txt" {
+		t.Errorf("ellipsize() = %q, expected %q", name, "a-very-~.txt")
+	}
+}
+
+func TestASCIIOutput(t *testing.T) {
+	originalCharset, originalASCII := charsetMode, asciiChars
+	defer func() { charsetMode, asciiChars = originalCharset, originalASCII }()
+
+	charsetMode, asciiChars = "unicode", false
+	if asciiOutput() {
+		t.Error("asciiOutput() with --charset unicode = true, expected false")
+	}
+	charsetMode, asciiChars = "auto", true
+	if !asciiOutput() {
+		t.Error("asciiOutput() with --ascii = false, expected true")
+	}
+}
+
+func TestLocaleSupportsUTF8(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		expected    bool
+	}{
+		{"", "", true},
+		{"", "en_US.UTF-8", true},
+		{"", "de_DE.utf8", true},
+		{"C", "en_US.UTF-8", false},
+		{"", "en_US.ISO-8859-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", tt.lang)
+		if result := localeSupportsUTF8(); result != tt.expected {
+			t.Errorf("localeSupportsUTF8() with LC_ALL=%q LANG=%q = %v, expected %v", tt.lcAll, tt.lang, result, tt.expected)
+		}
+	}
+}
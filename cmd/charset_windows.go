@@ -0,0 +1,16 @@
+package cmd
+
+import "golang.org/x/sys/windows"
+
+// utf8CodePage is the Windows code page number for UTF-8.
+const utf8CodePage = 65001
+
+var procGetConsoleOutputCP = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetConsoleOutputCP")
+
+// terminalSupportsUTF8 reports whether the console displays UTF-8. Consoles
+// left on a legacy OEM code page such as 437 or 850 show box-drawing
+// characters written as UTF-8 as mojibake.
+func terminalSupportsUTF8() bool {
+	cp, _, _ := procGetConsoleOutputCP.Call()
+	return cp == utf8CodePage
+}
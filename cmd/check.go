@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// specFile is the layout spec given with --spec.
+var specFile string
+
+// layoutSpec describes the expected structure of a tree.
+type layoutSpec struct {
+	// Required lists paths or glob patterns that must match at least one
+	// entry. A trailing slash requires a directory.
+	Required []string `json:"required"`
+	// Forbidden lists patterns that no entry may match
+	Forbidden []string     `json:"forbidden"`
+	Naming    []namingRule `json:"naming"`
+}
+
+// namingRule requires the names of the entries matching Paths to match the
+// regular expression Pattern.
+type namingRule struct {
+	Paths   string `json:"paths"`
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "Validate a tree against an expected layout.",
+	Long: `Check that a tree follows the layout described in a spec file and report
+every difference: required paths that are missing, forbidden paths that are
+present, and names that break a naming rule.
+
+  required:
+    - README.md
+    - cmd/            # a trailing slash requires a directory
+    - "*.go"
+  forbidden:
+    - "*.log"
+    - build/tmp
+  naming:
+    - paths: "cmd/*"
+      pattern: "^[a-z0-9_]+(\\.go)?$"
+
+Patterns containing a slash match the whole slash-separated path relative to
+the root; others match an entry's name at any depth. Naming patterns are
+regular expressions matched against the name. The spec may also be written as
+JSON with the same keys.
+
+The whole tree is checked unless --depth is given, and .git is always
+skipped. The command exits with a non-zero status when problems are found.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, err := loadLayoutSpec(specFile)
+		if err != nil {
+			return err
+		}
+
+		// Repository metadata is never part of a layout
+		excludePatterns = append(excludePatterns, ".git")
+		startPath, matchingFiles, err := findAuditPaths(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		problems := checkLayout(startPath, matchingFiles, spec)
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+
+		if len(problems) == 0 {
+			fmt.Println("The tree matches the spec.")
+			return nil
+		}
+		return fmt.Errorf("%d problems found", len(problems))
+	},
+}
+
+// loadLayoutSpec reads a spec from a YAML or JSON file.
+func loadLayoutSpec(name string) (layoutSpec, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return layoutSpec{}, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	var spec layoutSpec
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&spec); err != nil {
+			return layoutSpec{}, fmt.Errorf("invalid spec %s: %w", name, err)
+		}
+	} else if spec, err = parseLayoutSpec(string(data)); err != nil {
+		return layoutSpec{}, fmt.Errorf("invalid spec %s: %w", name, err)
+	}
+
+	for i := range spec.Naming {
+		rule := &spec.Naming[i]
+		if rule.Paths == "" || rule.Pattern == "" {
+			return layoutSpec{}, fmt.Errorf("invalid spec %s: naming rules need both paths and pattern", name)
+		}
+		if rule.re, err = regexp.Compile(rule.Pattern); err != nil {
+			return layoutSpec{}, fmt.Errorf("invalid spec %s: %w", name, err)
+		}
+	}
+	return spec, nil
+}
+
+// parseLayoutSpec parses the YAML form of a spec. Only the subset a spec
+// needs is understood: the three top-level keys, block or flow lists of
+// strings, and for naming, a list of mappings.
+func parseLayoutSpec(text string) (layoutSpec, error) {
+	var spec layoutSpec
+	section := ""
+
+	for i, line := range strings.Split(text, "\n") {
+		lineNum := i + 1
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		content := strings.TrimSpace(line)
+		if content == "" || content == "---" {
+			continue
+		}
+
+		// Top-level keys start a section
+		if line[0] != ' ' && line[0] != '-' {
+			key, value, ok := strings.Cut(content, ":")
+			if !ok {
+				return spec, fmt.Errorf("line %d: expected a key", lineNum)
+			}
+			section = strings.TrimSpace(key)
+			value = strings.TrimSpace(value)
+
+			var list *[]string
+			switch section {
+			case "required":
+				list = &spec.Required
+			case "forbidden":
+				list = &spec.Forbidden
+			case "naming":
+				if value != "" && value != "[]" {
+					return spec, fmt.Errorf("line %d: naming must be a list of rules", lineNum)
+				}
+				continue
+			default:
+				return spec, fmt.Errorf("line %d: unknown key %q", lineNum, section)
+			}
+
+			if value != "" {
+				items, err := parseYAMLFlowList(value)
+				if err != nil {
+					return spec, fmt.Errorf("line %d: %w", lineNum, err)
+				}
+				*list = append(*list, items...)
+			}
+			continue
+		}
+
+		if section == "" {
+			return spec, fmt.Errorf("line %d: unexpected indentation", lineNum)
+		}
+
+		item, isItem := strings.CutPrefix(content, "-")
+		if isItem {
+			item = strings.TrimSpace(item)
+		}
+
+		if section != "naming" {
+			if !isItem {
+				return spec, fmt.Errorf("line %d: expected a list item", lineNum)
+			}
+			value, err := parseYAMLScalar(item)
+			if err != nil {
+				return spec, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			if section == "required" {
+				spec.Required = append(spec.Required, value)
+			} else {
+				spec.Forbidden = append(spec.Forbidden, value)
+			}
+			continue
+		}
+
+		// Each "- " starts a rule, and further keys continue it
+		field := content
+		if isItem {
+			spec.Naming = append(spec.Naming, namingRule{})
+			field = item
+		} else if len(spec.Naming) == 0 {
+			return spec, fmt.Errorf("line %d: expected a list item", lineNum)
+		}
+
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return spec, fmt.Errorf("line %d: expected paths or pattern", lineNum)
+		}
+		parsed, err := parseYAMLScalar(strings.TrimSpace(value))
+		if err != nil {
+			return spec, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		rule := &spec.Naming[len(spec.Naming)-1]
+		switch strings.TrimSpace(key) {
+		case "paths":
+			rule.Paths = parsed
+		case "pattern":
+			rule.Pattern = parsed
+		default:
+			return spec, fmt.Errorf("line %d: unknown naming key %q", lineNum, strings.TrimSpace(key))
+		}
+	}
+
+	return spec, nil
+}
+
+// stripYAMLComment removes a comment from a line: a '#' at the start of the
+// line or after whitespace, outside quotes.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			// Skip the escaped character, which may be a quote
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseYAMLScalar returns the string value of a plain, single-quoted, or
+// double-quoted YAML scalar.
+func parseYAMLScalar(value string) (string, error) {
+	switch {
+	case value == "":
+		return "", fmt.Errorf("expected a value")
+	case value[0] == '"':
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted string %s", value)
+		}
+		return unquoted, nil
+	case value[0] == '\'':
+		if len(value) < 2 || value[len(value)-1] != '\'' {
+			return "", fmt.Errorf("invalid quoted string %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
+
+// parseYAMLFlowList parses a flow list such as [a, "b"]. Items may not
+// contain commas.
+func parseYAMLFlowList(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("expected a list")
+	}
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	if inner == "" {
+		return nil, nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(inner, ",") {
+		parsed, err := parseYAMLScalar(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, parsed)
+	}
+	return items, nil
+}
+
+// checkLayout compares the entries beneath root against a spec and describes
+// every problem found, in the order of the spec and then of the tree.
+func checkLayout(root string, paths []string, spec layoutSpec) []string {
+	type entry struct {
+		relPath string
+		isDir   bool
+	}
+	var entries []entry
+	for _, p := range paths {
+		relPath, err := filepath.Rel(root, p)
+		if err != nil {
+			continue
+		}
+		info, err := lstatCached(p)
+		entries = append(entries, entry{relPath: filepath.ToSlash(relPath), isDir: err == nil && info.IsDir()})
+	}
+
+	var problems []string
+
+	for _, required := range spec.Required {
+		pattern, wantDir := strings.CutSuffix(required, "/")
+		found := false
+		for _, e := range entries {
+			if (!wantDir || e.isDir) && layoutMatch(pattern, e.relPath) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, "missing: "+required)
+		}
+	}
+
+	for _, e := range entries {
+		for _, forbidden := range spec.Forbidden {
+			if layoutMatch(strings.TrimSuffix(forbidden, "/"), e.relPath) {
+				problems = append(problems, fmt.Sprintf("forbidden: %s (matches %s)", e.relPath, forbidden))
+				break
+			}
+		}
+	}
+
+	for _, e := range entries {
+		for _, rule := range spec.Naming {
+			if layoutMatch(rule.Paths, e.relPath) && !rule.re.MatchString(path.Base(e.relPath)) {
+				problems = append(problems, fmt.Sprintf("naming: %s does not match %s (rule for %s)", e.relPath, rule.Pattern, rule.Paths))
+			}
+		}
+	}
+
+	return problems
+}
+
+// layoutMatch reports whether a slash-separated relative path matches a spec
+// pattern. Patterns with a slash match the whole path; others match the name.
+func layoutMatch(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		relPath = path.Base(relPath)
+	}
+	matched, _ := path.Match(pattern, relPath)
+	return matched
+}
+
+func init() {
+	addFilterFlags(checkCmd.Flags())
+	checkCmd.Flags().StringVarP(&specFile, "spec", "", "", "Layout spec to check the tree against (YAML or JSON)")
+	checkCmd.MarkFlagRequired("spec")
+	rootCmd.AddCommand(checkCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLayoutSpec(t *testing.T) {
+	input := `# Layout of the service repositories
+required:
+  - README.md
+  - "cmd/"   # a directory
+forbidden: ["*.log", 'build/tmp']
+naming:
+  - paths: "cmd/*"
+    pattern: "^[a-z#]+(\\.go)?$"
+  - pattern: ^docs$
+    paths: docs
+`
+	spec, err := parseLayoutSpec(input)
+	if err != nil {
+		t.Fatalf("parseLayoutSpec() error = %v", err)
+	}
+
+	expected := layoutSpec{
+		Required:  []string{"README.md", "cmd/"},
+		Forbidden: []string{"*.log", "build/tmp"},
+		Naming: []namingRule{
+			{Paths: "cmd/*", Pattern: `^[a-z#]+(\.go)?$`},
+			{Paths: "docs", Pattern: "^docs$"},
+		},
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Errorf("parseLayoutSpec() = %+v, expected %+v", spec, expected)
+	}
+
+	for _, invalid := range []string{
+		"unknown:\n  - a\n",
+		"  - orphan\n",
+		"required:\n  key: value\n",
+		"naming:\n  - colour: red\n",
+		"required:\n  - \"unterminated\n",
+	} {
+		if _, err := parseLayoutSpec(invalid); err == nil {
+			t.Errorf("parseLayoutSpec(%q) expected an error", invalid)
+		}
+	}
+}
+
+func TestCheckLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, file := range []string{"README.md", "cmd/root.go", "cmd/BadName.go", "build/tmp/out.log", "docs/guide.md"} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	specPath := filepath.Join(t.TempDir(), "layout.json")
+	specJSON := `{
+		"required": ["README.md", "cmd/", "LICENSE", "README.md/"],
+		"forbidden": ["*.log", "build/tmp"],
+		"naming": [{"paths": "cmd/*", "pattern": "^[a-z_]+\\.go$"}]
+	}`
+	if err := os.WriteFile(specPath, []byte(specJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := loadLayoutSpec(specPath)
+	if err != nil {
+		t.Fatalf("loadLayoutSpec() error = %v", err)
+	}
+
+	originalDepth := maxDepth
+	defer func() { maxDepth = originalDepth }()
+	maxDepth = -1
+
+	paths, err := findMatchingFiles(tempDir, processFilters(nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"missing: LICENSE",
+		"missing: README.md/",
+		"forbidden: build/tmp (matches build/tmp)",
+		"forbidden: build/tmp/out.log (matches *.log)",
+		"naming: cmd/BadName.go does not match ^[a-z_]+\\.go$ (rule for cmd/*)",
+	}
+	if problems := checkLayout(tempDir, paths, spec); !reflect.DeepEqual(problems, expected) {
+		t.Errorf("checkLayout() = %q, expected %q", problems, expected)
+	}
+}
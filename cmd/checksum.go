@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+var (
+	// checksumAlgorithm is the --hash algorithm of the checksum column, or ""
+	// when it is not shown.
+	checksumAlgorithm string
+	// checksumMaxSize is the --hash-max-size limit above which files are
+	// not hashed.
+	checksumMaxSize string
+)
+
+// checksumAlgorithms are the hashes --hash can show.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// checksumCache holds the checksum of every file hashed during the current
+// build, or "" for files that could not be read.
+var checksumCache = make(map[string]string)
+
+// checksumLimit is the parsed --hash-max-size, or 0 for no limit.
+var checksumLimit int64
+
+// validateChecksumFlags checks the --hash and --hash-max-size settings.
+func validateChecksumFlags() error {
+	if checksumAlgorithm == "" {
+		return nil
+	}
+	if _, ok := checksumAlgorithms[checksumAlgorithm]; !ok {
+		return fmt.Errorf("invalid --hash %q (use md5, sha1, or sha256)", checksumAlgorithm)
+	}
+	limit, err := parseSize(checksumMaxSize)
+	if err != nil {
+		return fmt.Errorf("invalid --hash-max-size: %w", err)
+	}
+	checksumLimit = limit
+	return nil
+}
+
+// hashNodes hashes every file of the tree small enough for --hash-max-size
+// with a pool of --jobs workers, filling checksumCache.
+func hashNodes(root *tree.Node) {
+	// Checksums are computed afresh for each build, as watch re-renders the tree
+	clear(checksumCache)
+
+	var paths []string
+	root.Walk(func(node *tree.Node, _ string) {
+		if node.Path != "" && node.Info != nil && node.Info.Mode().IsRegular() &&
+			(checksumLimit <= 0 || node.Info.Size() <= checksumLimit) {
+			paths = append(paths, node.Path)
+		}
+	})
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < jobs(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range queue {
+				sum, _ := hashFileWith(path, checksumAlgorithms[checksumAlgorithm])
+				mu.Lock()
+				checksumCache[path] = sum
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range paths {
+		queue <- path
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// checksumColumn returns the checksum of a file, "-" for one over
+// --hash-max-size, "?" for one that could not be read, and nothing for
+// directories and other entries.
+func checksumColumn(node *tree.Node) string {
+	if node == nil || node.Path == "" || node.Info == nil || !node.Info.Mode().IsRegular() {
+		return ""
+	}
+	sum, ok := checksumCache[node.Path]
+	switch {
+	case !ok:
+		return "-"
+	case sum == "":
+		return "?"
+	}
+	return sum
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestChecksumColumn(t *testing.T) {
+	root := t.TempDir()
+	small := filepath.Join(root, "small.txt")
+	large := filepath.Join(root, "large.bin")
+	if err := os.WriteFile(small, []byte("hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(large, make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { checksumAlgorithm, checksumMaxSize, checksumLimit = "", "64M", 0 }()
+	checksumAlgorithm, checksumMaxSize = "md5", "1k"
+	if err := validateChecksumFlags(); err != nil {
+		t.Fatal(err)
+	}
+
+	top := tree.Build(root, []string{small, large}, lstatCached)
+	hashNodes(top)
+
+	// Files over --hash-max-size are skipped, and directories get no checksum
+	expected := map[string]string{
+		root:  "",
+		small: "764efa883dda1e11db47671c4a3bbd9e",
+		large: "-",
+	}
+	top.Walk(func(node *tree.Node, _ string) {
+		if column := checksumColumn(node); column != expected[node.Path] {
+			t.Errorf("checksumColumn(%s) = %q, expected %q", node.Path, column, expected[node.Path])
+		}
+	})
+
+	checksumAlgorithm = "crc32"
+	if err := validateChecksumFlags(); err == nil {
+		t.Error("validateChecksumFlags() accepted an unknown algorithm")
+	}
+}
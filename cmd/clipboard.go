@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atotto/clipboard"
+)
+
+// clipboardLimit is the largest output copied to the clipboard as text, in
+// bytes. Clipboard managers and the programs it is pasted into often truncate
+// or hang on anything much longer.
+var clipboardLimit = 4 << 20
+
+// copyOutput copies output to the clipboard. Output longer than
+// clipboardLimit is written to a temporary file instead, and the file's path
+// is copied.
+func copyOutput(output string) error {
+	text, spilled, err := clipboardText(output)
+	if err != nil {
+		return err
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		if spilled {
+			return fmt.Errorf("output written to %s, but failed to copy its path to clipboard: %w", text, err)
+		}
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+
+	if spilled {
+		fmt.Printf("Output is %s, too large for the clipboard; wrote it to %s and copied the path instead.\n", formatSize(int64(len(output))), text)
+	} else {
+		info("Output copied to clipboard.")
+	}
+	return nil
+}
+
+// clipboardText returns the text to copy for output: output itself, or if it
+// is longer than clipboardLimit, the path of a temporary file holding it, in
+// which case spilled is true.
+func clipboardText(output string) (text string, spilled bool, err error) {
+	if len(output) <= clipboardLimit {
+		return output, false, nil
+	}
+
+	file, err := os.CreateTemp("", "wintree-*.txt")
+	if err != nil {
+		return "", false, fmt.Errorf("output is too large for the clipboard, and a file to hold it could not be created: %w", err)
+	}
+	if _, err := file.WriteString(output); err != nil {
+		file.Close()
+		return "", false, fmt.Errorf("output is too large for the clipboard, and could not be written to %s: %w", file.Name(), err)
+	}
+	if err := file.Close(); err != nil {
+		return "", false, fmt.Errorf("output is too large for the clipboard, and could not be written to %s: %w", file.Name(), err)
+	}
+	return file.Name(), true, nil
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestClipboardText(t *testing.T) {
+	originalLimit := clipboardLimit
+	defer func() { clipboardLimit = originalLimit }()
+	clipboardLimit = 16
+
+	text, spilled, err := clipboardText("short tree\n")
+	if err != nil || spilled || text != "short tree\n" {
+		t.Errorf("clipboardText(short) = %q, %v, %v, expected the output itself", text, spilled, err)
+	}
+
+	output := strings.Repeat("long tree line\n", 4)
+	text, spilled, err = clipboardText(output)
+	if err != nil {
+		t.Fatalf("clipboardText(long) error = %v", err)
+	}
+	defer os.Remove(text)
+	if !spilled {
+		t.Fatalf("clipboardText(long) = %q, expected the path of a temporary file", text)
+	}
+	if data, err := os.ReadFile(text); err != nil || string(data) != output {
+		t.Errorf("temporary file holds %q (%v), expected the output", data, err)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"golang.org/x/term"
+)
+
+// colorMode is the --color setting: auto, always, or never.
+var colorMode string
+
+// defaultColors are the SGR sequences used for keys missing from $LS_COLORS,
+// following the dircolors defaults: directories blue, symlinks cyan,
+// executables green, archives red, images magenta, and audio and video cyan.
+var defaultColors = map[string]string{
+	"di": "01;34",
+	"ln": "01;36",
+	"ex": "01;32",
+}
+
+var defaultExtColors = map[string]string{
+	".7z": "01;31", ".gz": "01;31", ".rar": "01;31", ".tar": "01;31", ".tgz": "01;31", ".xz": "01;31", ".zip": "01;31",
+	".bmp": "01;35", ".gif": "01;35", ".ico": "01;35", ".jpeg": "01;35", ".jpg": "01;35", ".png": "01;35", ".svg": "01;35", ".webp": "01;35",
+	".flac": "00;36", ".mkv": "00;36", ".mov": "00;36", ".mp3": "00;36", ".mp4": "00;36", ".wav": "00;36",
+}
+
+// colorOutput reports whether names should be colored. Files and the
+// clipboard always get plain text; with --color auto, so does anything that is
+// not a terminal, or any output when $NO_COLOR is set.
+func colorOutput() bool {
+	if copyToClipboard || outputFile != "" {
+		return false
+	}
+	switch colorMode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd())) && enableTerminalColors()
+}
+
+// outputColors returns the colors to draw names in, or nil for plain output.
+func outputColors() *lsColors {
+	if !colorOutput() {
+		return nil
+	}
+	colors := parseLSColors(os.Getenv("LS_COLORS"))
+	return &colors
+}
+
+// lsColors holds the type and extension colors parsed from an $LS_COLORS
+// value. Extensions are lowercase and include the leading dot.
+type lsColors struct {
+	types map[string]string
+	exts  map[string]string
+}
+
+// parseLSColors parses an $LS_COLORS value such as "di=01;34:*.go=00;32",
+// layered over the defaults. Malformed entries are ignored, as ls does.
+func parseLSColors(value string) lsColors {
+	colors := lsColors{types: map[string]string{}, exts: map[string]string{}}
+	for key, sgr := range defaultColors {
+		colors.types[key] = sgr
+	}
+	for ext, sgr := range defaultExtColors {
+		colors.exts[ext] = sgr
+	}
+
+	for _, entry := range strings.Split(value, ":") {
+		key, sgr, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			continue
+		}
+		if pattern, isExt := strings.CutPrefix(key, "*"); isExt {
+			if strings.HasPrefix(pattern, ".") {
+				colors.exts[strings.ToLower(pattern)] = sgr
+			}
+			continue
+		}
+		colors.types[key] = sgr
+	}
+	return colors
+}
+
+// nodeColor returns the SGR sequence for a node, or "" if it is left plain.
+// Directories, symlinks, and executables are colored by type; other files by
+// extension, unless --coverage marks them as poorly covered. A color from
+// --annotate-cmd overrides them all.
+func (c lsColors) nodeColor(node *tree.Node) string {
+	if node == nil {
+		return ""
+	}
+	if sgr := pluginColor(node); sgr != "" {
+		return sgr
+	}
+	if node.IsDir {
+		return c.types["di"]
+	}
+	if sgr := coverageColor(node); sgr != "" {
+		return sgr
+	}
+	if node.Info != nil {
+		mode := node.Info.Mode()
+		if mode&os.ModeSymlink != 0 {
+			return c.types["ln"]
+		}
+		if mode.IsRegular() && isExecutable(node.Path, node.Info) {
+			return c.types["ex"]
+		}
+	}
+	return c.exts[strings.ToLower(filepath.Ext(node.Name))]
+}
+
+// colorize wraps name in the given SGR sequence.
+func colorize(name, sgr string) string {
+	if sgr == "" || sgr == "0" || sgr == "00" {
+		return name
+	}
+	return "\033[" + sgr + "m" + name + "\033[0m"
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cmd
+
+// enableTerminalColors reports whether the terminal can show colors, which
+// all terminals outside Windows can.
+func enableTerminalColors() bool {
+	return true
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestNodeColor(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, mode := range map[string]os.FileMode{"main.go": 0644, "photo.PNG": 0644, "run.sh": 0755} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte("test"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	colors := parseLSColors("di=00;33:*.go=00;32:bogus:*.PNG=01;35")
+	node := func(name string) *tree.Node {
+		path := filepath.Join(tempDir, name)
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &tree.Node{Name: name, Path: path, Info: info, IsDir: info.IsDir()}
+	}
+
+	tests := []struct {
+		node     *tree.Node
+		expected string
+	}{
+		{&tree.Node{Name: "src", IsDir: true}, "00;33"},
+		{node("main.go"), "00;32"},
+		{node("photo.PNG"), "01;35"},
+		{&tree.Node{Name: "notes.txt"}, ""},
+		{nil, ""},
+	}
+	if runtime.GOOS != "windows" {
+		tests = append(tests, struct {
+			node     *tree.Node
+			expected string
+		}{node("run.sh"), "01;32"})
+	}
+
+	for _, tt := range tests {
+		if result := colors.nodeColor(tt.node); result != tt.expected {
+			t.Errorf("nodeColor(%v) = %q, expected %q", tt.node, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatTreeRows_Color(t *testing.T) {
+	originalColor, originalOutput := colorMode, outputFile
+	defer func() {
+		colorMode, outputFile = originalColor, originalOutput
+		showInodes = false
+	}()
+	t.Setenv("LS_COLORS", "di=01;34")
+	colorMode = "always"
+	showInodes = true
+
+	tempDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tempDir, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []treeRow{
+		{name: "root", path: tempDir, node: &tree.Node{Name: "root", IsDir: true}},
+		{prefix: "├── ", name: "lib", path: filepath.Join(tempDir, "lib"), node: &tree.Node{Name: "lib", IsDir: true}},
+		{prefix: "└── ", name: "main.go", path: filepath.Join(tempDir, "main.go"), node: &tree.Node{Name: "main.go"}},
+	}
+
+	// Colored names must not shift the columns
+	lines := strings.Split(formatTreeRows(rows), "\n")
+	if !strings.HasPrefix(lines[1], "├── \033[01;34mlib\033[0m") {
+		t.Errorf("directory not colored: %q", lines[1])
+	}
+	plain := strings.NewReplacer("\033[01;34m", "", "\033[0m", "")
+	if first, second := []rune(plain.Replace(lines[1])), []rune(lines[2]); len(first) != len(second) {
+		t.Errorf("columns misaligned by color sequences:\n%s\n%s", lines[1], lines[2])
+	}
+
+	// Colors are never written to a file
+	outputFile = filepath.Join(t.TempDir(), "tree.txt")
+	if output := formatTreeRows(rows); strings.Contains(output, "\033[") {
+		t.Errorf("--out output contains escape sequences:\n%q", output)
+	}
+}
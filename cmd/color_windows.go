@@ -0,0 +1,18 @@
+package cmd
+
+import "golang.org/x/sys/windows"
+
+// enableTerminalColors turns on escape sequence processing for the console,
+// reporting whether colors can be shown. Consoles older than Windows 10
+// cannot process them and get plain output.
+func enableTerminalColors() bool {
+	handle := windows.Stdout
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
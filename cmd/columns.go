@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"golang.org/x/term"
+)
+
+// columnGap separates the tree from the metadata columns and the columns from
+// each other.
+const columnGap = "  "
+
+// treeRow is a single rendered line of the tree before metadata is attached:
+// the indentation and branch prefix, the name, and the path the line describes.
+// Rows drawn from a tree also carry the node and its parent, whose metadata
+// the columns read.
+type treeRow struct {
+	prefix string
+	name   string
+	path   string
+	node   *tree.Node
+	parent *tree.Node
+}
+
+// text returns the tree portion of the row.
+func (r treeRow) text() string {
+	return r.prefix + r.name
+}
+
+// styledText returns the tree portion of the row with the name colored, or
+// plain when colors is nil.
+func (r treeRow) styledText(colors *lsColors) string {
+	if colors == nil {
+		return r.text()
+	}
+	return r.prefix + colorize(r.name, colors.nodeColor(r.node))
+}
+
+// rowsAligned reports whether the current flags attach anything to the rows
+// that is aligned across the whole tree: metadata columns, annotations, or
+// the --perms prefix. Without them, each line only depends on its own row.
+func rowsAligned() bool {
+	return showPerms || columnsShown() || annotationsShown()
+}
+
+// writePlainRows draws the tree under root as writeRows does when rowsAligned
+// is false, writing each line to w as soon as it is drawn.
+func writePlainRows(w io.Writer, root *tree.Node, colors *lsColors) error {
+	icons := outputIcons()
+	width := outputWidth()
+	return eachTreeRow(root, func(row treeRow) error {
+		if icons != nil {
+			if icon := icons.nodeIcon(row.node); icon != "" {
+				row.prefix += icon + " "
+			}
+		}
+		if width > 0 {
+			row.name = ellipsize(row.name, width-utf8.RuneCountInString(row.prefix))
+		}
+		_, err := io.WriteString(w, row.styledText(colors)+"\n")
+		return err
+	})
+}
+
+// columnsShown reports whether nodeColumns returns any columns.
+func columnsShown() bool {
+	return showInodes || sizesShown() || showSizeBars || showMtime || checksumAlgorithm != "" || coverageCounts != nil
+}
+
+// nodeColumns returns the right-justified metadata columns shown for a node,
+// one entry per enabled column, in a fixed order. Every node must return the
+// same number of columns, using an empty string where a value does not apply.
+func nodeColumns(row treeRow) []string {
+	var columns []string
+	if showInodes {
+		columns = append(columns, inodeColumn(row.path))
+	}
+	if sizesShown() {
+		columns = append(columns, sizeColumn(row.node))
+	}
+	if showSizeBars {
+		columns = append(columns, sizeBarColumn(row.node, row.parent))
+	}
+	if showMtime {
+		columns = append(columns, mtimeColumn(row.node))
+	}
+	if checksumAlgorithm != "" {
+		columns = append(columns, checksumColumn(row.node))
+	}
+	if coverageCounts != nil {
+		columns = append(columns, coverageColumn(row.node))
+	}
+	return columns
+}
+
+// inodeColumn returns the inode number (Unix) or file ID (Windows) of a node,
+// so that hard links and junctions pointing at the same file can be spotted.
+func inodeColumn(path string) string {
+	if path == "" {
+		return ""
+	}
+	id, ok := fileID(path)
+	if !ok {
+		return "?"
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+// formatTreeRows renders the rows one per line, colored when the output
+// allows it. Metadata columns are right-justified and aligned to the right of
+// the longest name, with widths computed from this run; annotations follow
+// the columns.
+func formatTreeRows(rows []treeRow) string {
+	return formatRows(rows, outputColors())
+}
+
+// formatRows renders the rows as formatTreeRows does, coloring names with
+// colors unless it is nil.
+func formatRows(rows []treeRow, colors *lsColors) string {
+	var output strings.Builder
+	_ = writeRows(&output, rows, colors)
+	return output.String()
+}
+
+// writeRows renders the rows as formatRows does, writing the lines to w
+// instead of building the whole text first.
+func writeRows(w io.Writer, rows []treeRow, colors *lsColors) error {
+	// Times are measured afresh for each render, as watch re-renders the tree
+	clear(latestCache)
+
+	columns := make([][]string, len(rows))
+	annotations := make([]string, len(rows))
+	leading := make([]string, len(rows))
+	hasMetadata := false
+
+	if showPerms {
+		leading = permsPrefixes(rows)
+	}
+	if icons := outputIcons(); icons != nil {
+		for i := range rows {
+			if icon := icons.nodeIcon(rows[i].node); icon != "" {
+				rows[i].prefix += icon + " "
+			}
+		}
+	}
+	for i, row := range rows {
+		columns[i] = nodeColumns(row)
+		annotations[i] = nodeAnnotations(row.path)
+		if len(columns[i]) > 0 || annotations[i] != "" {
+			hasMetadata = true
+		}
+	}
+
+	// Shorten names so that each line, including its metadata, fits the width
+	if width := outputWidth(); width > 0 {
+		metadataWidth := 0
+		for i := range rows {
+			metadataWidth = max(metadataWidth, rowMetadataWidth(columns[i], annotations[i]))
+		}
+		for i := range rows {
+			available := width - metadataWidth - utf8.RuneCountInString(leading[i]+rows[i].prefix)
+			rows[i].name = ellipsize(rows[i].name, available)
+		}
+	}
+
+	if !hasMetadata {
+		for i, row := range rows {
+			if _, err := io.WriteString(w, leading[i]+row.styledText(colors)+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Compute the width of the tree text and of every column. The root line is
+	// left out of the text width since a full path would push every column right.
+	textWidth := 0
+	var columnWidths []int
+	for i, row := range rows {
+		if i > 0 || len(rows) == 1 {
+			textWidth = max(textWidth, utf8.RuneCountInString(row.text()))
+		}
+		for j, column := range columns[i] {
+			if j >= len(columnWidths) {
+				columnWidths = append(columnWidths, 0)
+			}
+			columnWidths[j] = max(columnWidths[j], utf8.RuneCountInString(column))
+		}
+	}
+
+	for i, row := range rows {
+		var line strings.Builder
+		line.WriteString(leading[i])
+		// Pad by the plain text, since color sequences take up no columns
+		line.WriteString(row.styledText(colors))
+		line.WriteString(strings.TrimPrefix(padRight(row.text(), textWidth), row.text()))
+		for j, column := range columns[i] {
+			line.WriteString(columnGap + padLeft(column, columnWidths[j]))
+		}
+		if annotations[i] != "" {
+			line.WriteString(columnGap + annotations[i])
+		}
+		if _, err := io.WriteString(w, strings.TrimRight(line.String(), " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowMetadataWidth returns the display width taken up by a row's columns and
+// annotations, including the gaps before them.
+func rowMetadataWidth(columns []string, annotation string) int {
+	width := 0
+	for _, column := range columns {
+		width += len(columnGap) + utf8.RuneCountInString(column)
+	}
+	if annotation != "" {
+		width += len(columnGap) + utf8.RuneCountInString(annotation)
+	}
+	return width
+}
+
+// minNameWidth is the narrowest a name is ever shortened to, so that deeply
+// nested entries stay recognizable even if the line then overflows.
+const minNameWidth = 8
+
+// ellipsize shortens name to at most width display columns, replacing the
+// middle with "…" (or "~" when limited to ASCII) and keeping the extension
+// where possible.
+func ellipsize(name string, width int) string {
+	width = max(width, minNameWidth)
+	runes := []rune(name)
+	if len(runes) <= width {
+		return name
+	}
+
+	ext := []rune(filepath.Ext(name))
+	if len(ext) > width/2 {
+		ext = nil
+	}
+	head := width - 1 - len(ext)
+	ellipsis := "…"
+	if asciiOutput() {
+		ellipsis = "~"
+	}
+	return string(runes[:head]) + ellipsis + string(ext)
+}
+
+// padRight pads s with spaces to width display columns.
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// padLeft right-justifies s in width display columns.
+func padLeft(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
+
+// outputWidth returns the width that lines should be shortened to, or 0 if
+// names should not be truncated. --max-width always applies; --truncate uses
+// the terminal width, and only when printing to a terminal.
+func outputWidth() int {
+	if maxLineWidth > 0 {
+		return maxLineWidth
+	}
+	if !truncateNames || copyToClipboard || outputFile != "" {
+		return 0
+	}
+	return terminalWidth()
+}
+
+// terminalWidth returns the width of the terminal attached to stdout, falling
+// back to $COLUMNS, or 0 if neither is available.
+func terminalWidth() int {
+	if fd := int(os.Stdout.Fd()); term.IsTerminal(fd) {
+		if width, _, err := term.GetSize(fd); err == nil && width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFormatTreeRows(t *testing.T) {
+	t.Run("no metadata", func(t *testing.T) {
+		rows := []treeRow{{name: "root"}, {prefix: "└── ", name: "file.go"}}
+		if output := formatTreeRows(rows); output != "root\n└── file.go\n" {
+			t.Errorf("formatTreeRows() = %q, expected rows unchanged", output)
+		}
+	})
+
+	t.Run("annotations are aligned", func(t *testing.T) {
+		tempDir := setupTestDirectory(t)
+		defer os.RemoveAll(tempDir)
+
+		annotateMeta = true
+		defer func() { annotateMeta = false }()
+
+		rows := []treeRow{
+			{name: "a-very-long-root-label-that-is-ignored", path: tempDir},
+			{prefix: "├── ", name: "docs", path: filepath.Join(tempDir, "docs")},
+			{prefix: "└── ", name: "a-much-longer-name", path: filepath.Join(tempDir, "src")},
+		}
+		lines := strings.Split(strings.TrimSuffix(formatTreeRows(rows), "\n"), "\n")
+
+		first := strings.Index(lines[1], "[README")
+		second := strings.Index(lines[2], "[README")
+		if first < 0 || first != second {
+			t.Errorf("annotations should start in the same column:\n%s", strings.Join(lines, "\n"))
+		}
+		if !strings.HasPrefix(lines[0], "a-very-long-root-label-that-is-ignored  [README") {
+			t.Errorf("root line should not be padded to the column when longer:\n%s", lines[0])
+		}
+	})
+}
+
+func TestPadding(t *testing.T) {
+	if result := padRight("├── a", 8); result != "├── a   " {
+		t.Errorf("padRight() = %q, expected padding by display width", result)
+	}
+	if result := padLeft("1.5 KB", 8); result != "  1.5 KB" {
+		t.Errorf("padLeft() = %q, expected right-justified value", result)
+	}
+	if result := padLeft("too long", 3); result != "too long" {
+		t.Errorf("padLeft() = %q, expected value unchanged when wider than width", result)
+	}
+}
+
+func TestEllipsize(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		expected string
+	}{
+		{"short.go", 20, "short.go"},
+		{"exactly-ten", 11, "exactly-ten"},
+		{"a-really-long-generated-file-name.go", 16, "a-really-lon….go"},
+		{"no-extension-but-very-long", 10, "no-extens…"},
+		{"tiny-width-still-readable.txt", 2, "tin….txt"},
+		{"ext.verylongextension", 10, "ext.veryl…"},
+	}
+
+	for _, tt := range tests {
+		result := ellipsize(tt.name, tt.width)
+		if result != tt.expected {
+			t.Errorf("ellipsize(%q, %d) = %q, expected %q", tt.name, tt.width, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatTreeRows_MaxWidth(t *testing.T) {
+	maxLineWidth = 24
+	defer func() { maxLineWidth = 0 }()
+
+	rows := []treeRow{
+		{name: "root"},
+		{prefix: "├── ", name: "short.go"},
+		{prefix: "│   └── ", name: "an-extremely-long-file-name-that-wraps.go"},
+	}
+	output := formatTreeRows(rows)
+
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if width := utf8.RuneCountInString(line); width > 24 {
+			t.Errorf("line %q is %d columns wide, expected at most 24", line, width)
+		}
+	}
+	if !strings.Contains(output, "│   └── an-extremely….go\n") {
+		t.Errorf("long name should be ellipsized:\n%s", output)
+	}
+	if !strings.Contains(output, "├── short.go\n") {
+		t.Errorf("short names should be unchanged:\n%s", output)
+	}
+}
+
+func TestInodeColumn_HardLinks(t *testing.T) {
+	tempDir := t.TempDir()
+	original := filepath.Join(tempDir, "original.txt")
+	if err := os.WriteFile(original, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(tempDir, "link.txt")
+	if err := os.Link(original, link); err != nil {
+		t.Skipf("hard links not supported: %v", err)
+	}
+	other := filepath.Join(tempDir, "other.txt")
+	if err := os.WriteFile(other, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := fileID(original); !ok {
+		t.Skip("file IDs are not available on this platform")
+	}
+
+	if inodeColumn(original) != inodeColumn(link) {
+		t.Errorf("hard links should share an inode: %s != %s", inodeColumn(original), inodeColumn(link))
+	}
+	if inodeColumn(original) == inodeColumn(other) {
+		t.Errorf("distinct files should not share an inode: %s", inodeColumn(original))
+	}
+	if inodeColumn("") != "" {
+		t.Error("inodeColumn() for a virtual node should be empty")
+	}
+}
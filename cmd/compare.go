@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Kinds of difference reported by compareSnapshots, in the order they are printed.
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeType    = "type"
+	changeContent = "content"
+	changeMode    = "mode"
+	changeXattrs  = "xattrs"
+	changeMtime   = "mtime"
+)
+
+// mtimeLayout shows milliseconds so that quick successive writes are distinguishable.
+const mtimeLayout = "2006-01-02 15:04:05.000"
+
+var changeKindOrder = []string{changeAdded, changeRemoved, changeType, changeContent, changeMode, changeXattrs, changeMtime}
+
+var (
+	diffIgnorePatterns []string
+	ignoreMtime        bool
+	ignoreMode         bool
+	compareFormat      string
+)
+
+// patchOperation is a single RFC 6902 JSON Patch operation.
+type patchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// changeKindTitles are the section headings used when printing a comparison.
+var changeKindTitles = map[string]string{
+	changeAdded:   "Added",
+	changeRemoved: "Removed",
+	changeType:    "Type changed",
+	changeContent: "Content drift",
+	changeMode:    "Permission drift",
+	changeXattrs:  "Extended attribute drift",
+	changeMtime:   "Modification time drift",
+}
+
+// entryChange is a single difference between a snapshot and the live tree.
+type entryChange struct {
+	Path   string
+	Kind   string
+	Detail string
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare SNAPSHOT [path]",
+	Short: "Compare a tree against a saved snapshot.",
+	Long: `Compare the live tree against a snapshot saved with "wintree snapshot save"
+and report added and removed entries, content drift, permission drift, and
+extended attribute drift separately. Hashes and extended attributes are only
+compared if the snapshot recorded them.
+
+Known-noisy entries can be left out of the report with --diff-ignore, which
+matches glob patterns against the name of each changed path and each of its
+parent directories, and whole kinds of drift can be skipped with --ignore-mtime
+and --ignore-mode.
+
+With --format json-patch, the differences are written as an RFC 6902 JSON
+Patch instead. The patch applies to a JSON object that maps each path in the
+snapshot to its entry, so "/src~1main.go/mode" is the mode of src/main.go.
+
+The path defaults to the root recorded in the snapshot. The command exits with
+a non-zero status when differences are found.`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		if compareFormat != "text" && compareFormat != "json-patch" {
+			return flagErrorf("invalid --format %q (use text or json-patch)", compareFormat)
+		}
+
+		old, err := loadSnapshot(args[0])
+		if err != nil {
+			return err
+		}
+
+		startPath := old.Root
+		if len(args) > 1 {
+			if startPath, err = resolveStartPath(args[1:]); err != nil {
+				return err
+			}
+		}
+		if _, err := os.Stat(startPath); err != nil {
+			return fmt.Errorf("cannot compare against %s: %w", startPath, err)
+		}
+		if !cmd.Flags().Changed("depth") {
+			maxDepth = -1
+		}
+
+		cur, err := takeSnapshot(startPath, processFilters(excludePatterns, includePatterns), old.Hashes, old.Xattrs)
+		if err != nil {
+			return err
+		}
+
+		changes := filterChanges(compareSnapshots(old, cur))
+
+		if compareFormat == "json-patch" {
+			data, err := json.MarshalIndent(jsonPatch(changes, cur), "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		} else if len(changes) == 0 {
+			fmt.Println("No differences found.")
+		} else {
+			fmt.Print(formatChanges(changes))
+		}
+
+		if len(changes) > 0 {
+			return fmt.Errorf("%d differences found", len(changes))
+		}
+		return nil
+	},
+}
+
+// compareSnapshots returns every difference between old and cur, sorted by
+// kind and then path. An entry can appear once per kind of drift.
+func compareSnapshots(old, cur *snapshot) []entryChange {
+	oldEntries := make(map[string]snapshotEntry, len(old.Entries))
+	for _, entry := range old.Entries {
+		oldEntries[entry.Path] = entry
+	}
+
+	var changes []entryChange
+	seen := make(map[string]bool, len(cur.Entries))
+
+	for _, entry := range cur.Entries {
+		seen[entry.Path] = true
+		before, ok := oldEntries[entry.Path]
+		if !ok {
+			changes = append(changes, entryChange{Path: entry.Path, Kind: changeAdded})
+			continue
+		}
+		changes = append(changes, compareEntries(before, entry, old.Hashes && cur.Hashes, old.Xattrs && cur.Xattrs)...)
+	}
+
+	for _, entry := range old.Entries {
+		if !seen[entry.Path] {
+			changes = append(changes, entryChange{Path: entry.Path, Kind: changeRemoved})
+		}
+	}
+
+	kindRank := make(map[string]int, len(changeKindOrder))
+	for i, kind := range changeKindOrder {
+		kindRank[kind] = i
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return kindRank[changes[i].Kind] < kindRank[changes[j].Kind]
+		}
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// filterChanges drops the changes excluded by --diff-ignore, --ignore-mtime,
+// and --ignore-mode.
+func filterChanges(changes []entryChange) []entryChange {
+	var kept []entryChange
+	for _, change := range changes {
+		if ignoreMtime && change.Kind == changeMtime {
+			continue
+		}
+		if ignoreMode && change.Kind == changeMode {
+			continue
+		}
+		if matchesDiffIgnore(change.Path) {
+			continue
+		}
+		kept = append(kept, change)
+	}
+	return kept
+}
+
+// matchesDiffIgnore reports whether any component of a slash-separated
+// snapshot path matches a --diff-ignore pattern.
+func matchesDiffIgnore(path string) bool {
+	for _, part := range strings.Split(path, "/") {
+		for _, pattern := range diffIgnorePatterns {
+			if matched, _ := filepath.Match(pattern, part); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareEntries reports the drift between two versions of the same path.
+func compareEntries(before, after snapshotEntry, hashes, xattrs bool) []entryChange {
+	if before.Type != after.Type {
+		return []entryChange{{Path: after.Path, Kind: changeType, Detail: before.Type + " -> " + after.Type}}
+	}
+
+	var changes []entryChange
+
+	switch {
+	case before.Size != after.Size:
+		changes = append(changes, entryChange{Path: after.Path, Kind: changeContent,
+			Detail: fmt.Sprintf("size %s -> %s", formatSize(before.Size), formatSize(after.Size))})
+	case hashes && before.SHA256 != after.SHA256:
+		changes = append(changes, entryChange{Path: after.Path, Kind: changeContent, Detail: "sha256 changed"})
+	}
+
+	if before.Mode != after.Mode {
+		changes = append(changes, entryChange{Path: after.Path, Kind: changeMode, Detail: before.Mode + " -> " + after.Mode})
+	}
+
+	if xattrs {
+		if detail := compareXattrs(before.Xattrs, after.Xattrs); detail != "" {
+			changes = append(changes, entryChange{Path: after.Path, Kind: changeXattrs, Detail: detail})
+		}
+	}
+
+	// Directory mtimes change whenever their children do, and content drift
+	// implies a new mtime, so both are already covered
+	contentChanged := len(changes) > 0 && changes[0].Kind == changeContent
+	if after.Type != "dir" && !contentChanged && !before.ModTime.Equal(after.ModTime) {
+		changes = append(changes, entryChange{Path: after.Path, Kind: changeMtime,
+			Detail: before.ModTime.Format(mtimeLayout) + " -> " + after.ModTime.Format(mtimeLayout)})
+	}
+
+	return changes
+}
+
+// compareXattrs describes which extended attributes were added, removed, or
+// changed, or returns an empty string if they are identical.
+func compareXattrs(before, after map[string]string) string {
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var details []string
+	for _, name := range names {
+		oldValue, hadOld := before[name]
+		newValue, hasNew := after[name]
+		switch {
+		case !hadOld:
+			details = append(details, name+" added")
+		case !hasNew:
+			details = append(details, name+" removed")
+		case oldValue != newValue:
+			details = append(details, name+" changed")
+		}
+	}
+
+	return strings.Join(details, ", ")
+}
+
+// formatChanges groups changes under a heading per kind, marking additions with
+// "+", removals with "-", and modifications with "~".
+func formatChanges(changes []entryChange) string {
+	var output strings.Builder
+	lastKind := ""
+
+	for _, change := range changes {
+		if change.Kind != lastKind {
+			if lastKind != "" {
+				output.WriteString("\n")
+			}
+			output.WriteString(changeKindTitles[change.Kind] + ":\n")
+			lastKind = change.Kind
+		}
+
+		marker := "~"
+		switch change.Kind {
+		case changeAdded:
+			marker = "+"
+		case changeRemoved:
+			marker = "-"
+		}
+
+		output.WriteString("  " + marker + " " + change.Path)
+		if change.Detail != "" {
+			output.WriteString(" (" + change.Detail + ")")
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// jsonPatch converts changes into JSON Patch operations that turn the old
+// snapshot's entries into the current ones, taking new values from cur.
+func jsonPatch(changes []entryChange, cur *snapshot) []patchOperation {
+	entries := make(map[string]snapshotEntry, len(cur.Entries))
+	for _, entry := range cur.Entries {
+		entries[entry.Path] = entry
+	}
+
+	operations := []patchOperation{}
+	for _, change := range changes {
+		pointer := "/" + jsonPointerEscape(change.Path)
+		entry := entries[change.Path]
+
+		switch change.Kind {
+		case changeAdded:
+			operations = append(operations, patchOperation{Op: "add", Path: pointer, Value: entry})
+		case changeRemoved:
+			operations = append(operations, patchOperation{Op: "remove", Path: pointer})
+		case changeType:
+			operations = append(operations, patchOperation{Op: "replace", Path: pointer, Value: entry})
+		case changeContent:
+			// Empty files have no size member, and "add" replaces an existing one
+			operations = append(operations, patchOperation{Op: "add", Path: pointer + "/size", Value: entry.Size})
+			if entry.SHA256 != "" {
+				operations = append(operations, patchOperation{Op: "replace", Path: pointer + "/sha256", Value: entry.SHA256})
+			}
+		case changeMode:
+			operations = append(operations, patchOperation{Op: "replace", Path: pointer + "/mode", Value: entry.Mode})
+		case changeXattrs:
+			if len(entry.Xattrs) == 0 {
+				operations = append(operations, patchOperation{Op: "remove", Path: pointer + "/xattrs"})
+			} else {
+				operations = append(operations, patchOperation{Op: "add", Path: pointer + "/xattrs", Value: entry.Xattrs})
+			}
+		case changeMtime:
+			operations = append(operations, patchOperation{Op: "replace", Path: pointer + "/mtime", Value: entry.ModTime})
+		}
+	}
+
+	return operations
+}
+
+// jsonPointerEscape escapes a snapshot path for use as a single JSON Pointer
+// reference token (RFC 6901).
+func jsonPointerEscape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// snapshotCompareCmd is compare under the snapshot command, next to save.
+var snapshotCompareCmd = &cobra.Command{
+	Use:          "compare SNAPSHOT [path]",
+	Short:        "Compare a tree against a saved snapshot (same as wintree compare).",
+	Long:         compareCmd.Long,
+	Args:         compareCmd.Args,
+	SilenceUsage: true,
+	RunE:         compareCmd.RunE,
+}
+
+// addCompareFlags adds the flags of compare and snapshot compare.
+func addCompareFlags(flags *pflag.FlagSet) {
+	addFilterFlags(flags)
+	flags.StringSliceVarP(&diffIgnorePatterns, "diff-ignore", "", []string{}, "Glob patterns for paths to leave out of the report (e.g., *.log, tmp)")
+	flags.BoolVarP(&ignoreMtime, "ignore-mtime", "", false, "Do not report modification time drift")
+	flags.BoolVarP(&ignoreMode, "ignore-mode", "", false, "Do not report permission drift")
+	flags.StringVarP(&compareFormat, "format", "", "text", "Output format: text (grouped report) or json-patch (RFC 6902 JSON Patch)")
+}
+
+func init() {
+	addCompareFlags(compareCmd.Flags())
+	addCompareFlags(snapshotCompareCmd.Flags())
+	rootCmd.AddCommand(compareCmd)
+	snapshotCmd.AddCommand(snapshotCompareCmd)
+}
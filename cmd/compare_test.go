@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareSnapshots(t *testing.T) {
+	mtime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := mtime.Add(time.Hour)
+
+	old := &snapshot{
+		Version: snapshotVersion,
+		Hashes:  true,
+		Xattrs:  true,
+		Entries: []snapshotEntry{
+			{Path: "same.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime, SHA256: "aaa"},
+			{Path: "edited.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime, SHA256: "aaa"},
+			{Path: "run.sh", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime, SHA256: "aaa"},
+			{Path: "tagged.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime, SHA256: "aaa", Xattrs: map[string]string{"user.a": "01", "user.b": "02"}},
+			{Path: "touched.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime, SHA256: "aaa"},
+			{Path: "gone.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime},
+			{Path: "swap", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime},
+		},
+	}
+	cur := &snapshot{
+		Version: snapshotVersion,
+		Hashes:  true,
+		Xattrs:  true,
+		Entries: []snapshotEntry{
+			{Path: "same.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime, SHA256: "aaa"},
+			{Path: "edited.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: later, SHA256: "bbb"},
+			{Path: "run.sh", Type: "file", Size: 4, Mode: "-rwxr-xr-x", ModTime: mtime, SHA256: "aaa"},
+			{Path: "tagged.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime, SHA256: "aaa", Xattrs: map[string]string{"user.a": "ff", "user.c": "03"}},
+			{Path: "touched.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: later, SHA256: "aaa"},
+			{Path: "new.txt", Type: "file", Size: 4, Mode: "-rw-r--r--", ModTime: mtime},
+			{Path: "swap", Type: "dir", Mode: "-rwxr-xr-x", ModTime: mtime},
+		},
+	}
+
+	changes := compareSnapshots(old, cur)
+
+	expected := []entryChange{
+		{Path: "new.txt", Kind: changeAdded},
+		{Path: "gone.txt", Kind: changeRemoved},
+		{Path: "swap", Kind: changeType, Detail: "file -> dir"},
+		{Path: "edited.txt", Kind: changeContent, Detail: "sha256 changed"},
+		{Path: "run.sh", Kind: changeMode, Detail: "-rw-r--r-- -> -rwxr-xr-x"},
+		{Path: "tagged.txt", Kind: changeXattrs, Detail: "user.a changed, user.b removed, user.c added"},
+		{Path: "touched.txt", Kind: changeMtime, Detail: "2025-01-01 12:00:00.000 -> 2025-01-01 13:00:00.000"},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("compareSnapshots() returned %d changes, expected %d:\n%s", len(changes), len(expected), formatChanges(changes))
+	}
+	for i, change := range changes {
+		if change != expected[i] {
+			t.Errorf("change %d = %+v, expected %+v", i, change, expected[i])
+		}
+	}
+
+	t.Run("hashes ignored when not recorded", func(t *testing.T) {
+		withoutHashes := *old
+		withoutHashes.Hashes = false
+		for _, change := range compareSnapshots(&withoutHashes, cur) {
+			if change.Path == "edited.txt" && change.Kind == changeContent {
+				t.Error("content drift should not be based on hashes the snapshot did not record")
+			}
+		}
+	})
+}
+
+func TestFormatChanges(t *testing.T) {
+	output := formatChanges([]entryChange{
+		{Path: "new.txt", Kind: changeAdded},
+		{Path: "gone.txt", Kind: changeRemoved},
+		{Path: "run.sh", Kind: changeMode, Detail: "-rw-r--r-- -> -rwxr-xr-x"},
+	})
+
+	for _, expected := range []string{
+		"Added:\n  + new.txt\n",
+		"Removed:\n  - gone.txt\n",
+		"Permission drift:\n  ~ run.sh (-rw-r--r-- -> -rwxr-xr-x)\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("formatChanges() missing %q in:\n%s", expected, output)
+		}
+	}
+}
+
+func TestFilterChanges(t *testing.T) {
+	changes := []entryChange{
+		{Path: "app.log", Kind: changeContent},
+		{Path: "logs/today.txt", Kind: changeAdded},
+		{Path: "src/main.go", Kind: changeContent},
+		{Path: "src/main.go", Kind: changeMode},
+		{Path: "src/util.go", Kind: changeMtime},
+	}
+
+	defer func() {
+		diffIgnorePatterns = []string{}
+		ignoreMtime, ignoreMode = false, false
+	}()
+
+	diffIgnorePatterns = []string{"*.log", "logs"}
+	ignoreMtime = true
+
+	kept := filterChanges(changes)
+	if len(kept) != 2 || kept[0].Kind != changeContent || kept[1].Kind != changeMode {
+		t.Errorf("filterChanges() = %+v, expected the content and mode changes to src/main.go", kept)
+	}
+
+	ignoreMode = true
+	kept = filterChanges(changes)
+	if len(kept) != 1 || kept[0].Path != "src/main.go" || kept[0].Kind != changeContent {
+		t.Errorf("filterChanges() with --ignore-mode = %+v, expected only the content change", kept)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	mtime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cur := &snapshot{Entries: []snapshotEntry{
+		{Path: "src/new.go", Type: "file", Size: 10, Mode: "-rw-r--r--", ModTime: mtime},
+		{Path: "src/main.go", Type: "file", Size: 20, Mode: "-rwxr-xr-x", ModTime: mtime, SHA256: "bbb"},
+		{Path: "a~b", Type: "file", Mode: "-rw-r--r--", ModTime: mtime},
+	}}
+	changes := []entryChange{
+		{Path: "src/new.go", Kind: changeAdded},
+		{Path: "gone.txt", Kind: changeRemoved},
+		{Path: "src/main.go", Kind: changeContent},
+		{Path: "src/main.go", Kind: changeMode},
+		{Path: "a~b", Kind: changeMtime},
+	}
+
+	data, err := json.Marshal(jsonPatch(changes, cur))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[` +
+		`{"op":"add","path":"/src~1new.go","value":{"path":"src/new.go","type":"file","size":10,"mode":"-rw-r--r--","mtime":"2025-01-01T12:00:00Z"}},` +
+		`{"op":"remove","path":"/gone.txt"},` +
+		`{"op":"add","path":"/src~1main.go/size","value":20},` +
+		`{"op":"replace","path":"/src~1main.go/sha256","value":"bbb"},` +
+		`{"op":"replace","path":"/src~1main.go/mode","value":"-rwxr-xr-x"},` +
+		`{"op":"replace","path":"/a~0b/mtime","value":"2025-01-01T12:00:00Z"}` +
+		`]`
+	if string(data) != expected {
+		t.Errorf("jsonPatch() =\n%s\nexpected:\n%s", data, expected)
+	}
+
+	if data, _ := json.Marshal(jsonPatch(nil, cur)); string(data) != "[]" {
+		t.Errorf("jsonPatch() with no changes = %s, expected []", data)
+	}
+}
+
+func TestSnapshotCompareCommand(t *testing.T) {
+	cmd, _, err := rootCmd.Find([]string{"snapshot", "compare"})
+	if err != nil || cmd != snapshotCompareCmd {
+		t.Fatalf("Find(snapshot compare) = %v, %v", cmd, err)
+	}
+	for _, name := range []string{"diff-ignore", "ignore-mtime", "ignore-mode", "format", "exclude"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Errorf("snapshot compare is missing --%s", name)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"cmp"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/cobra"
+)
+
+// completionDepth is how deep the tree is scanned for --include and --exclude
+// completions, so pressing TAB stays quick in large trees.
+const completionDepth = 3
+
+// completionSkip are directories whose contents are left out of the scan for
+// completions, though their names are still offered to --exclude.
+var completionSkip = []string{".git", ".hg", ".svn"}
+
+// registerPatternCompletions completes --include and --exclude, on cmd and
+// every command beneath it that has them, from the extensions and names
+// actually found in the tree being listed. The rest of "wintree completion"
+// comes with cobra.
+func registerPatternCompletions(cmd *cobra.Command) {
+	for _, name := range []string{"include", "exclude"} {
+		if cmd.Flags().Lookup(name) != nil {
+			_ = cmd.RegisterFlagCompletionFunc(name, patternCompletion(name))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		registerPatternCompletions(sub)
+	}
+}
+
+// patternCompletion returns the completion function of --include or
+// --exclude. Both take comma-separated lists, so only the last pattern of the
+// word is completed.
+func patternCompletion(flag string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		root := "."
+		if len(args) > 0 {
+			root = args[len(args)-1]
+		}
+		done, partial := "", toComplete
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			done, partial = toComplete[:i+1], toComplete[i+1:]
+		}
+
+		var completions []cobra.Completion
+		for _, suggestion := range patternSuggestions(root, flag == "exclude") {
+			if strings.HasPrefix(suggestion.pattern, partial) {
+				completions = append(completions, cobra.CompletionWithDesc(done+suggestion.pattern, suggestion.description))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// patternSuggestion is a pattern offered for completion, with a description
+// of what it matches.
+type patternSuggestion struct {
+	pattern     string
+	description string
+	count       int
+}
+
+// patternSuggestions scans the tree at root for patterns worth completing:
+// *.ext for each extension of its files, and the names of files without one,
+// most common first. With dirs, the names of its directories come first, as
+// they are what is usually excluded. Patterns already given to --exclude are
+// left out of the scan.
+func patternSuggestions(root string, dirs bool) []patternSuggestion {
+	dirCounts := map[string]int{}
+	fileCounts := map[string]int{}
+	walker := tree.NewWalker(tree.Options{
+		Exclude:  append(slices.Clone(excludePatterns), completionSkip...),
+		MaxDepth: completionDepth,
+		Jobs:     jobs(),
+	})
+	walker.OnEntry = func(path string, d fs.DirEntry) {
+		name := d.Name()
+		switch {
+		case path == root || isOSNoise(name):
+		case d.IsDir():
+			dirCounts[name]++
+		case filepath.Ext(name) != "" && filepath.Ext(name) != name:
+			fileCounts["*"+filepath.Ext(name)]++
+		default:
+			fileCounts[name]++
+		}
+	}
+	if _, err := walker.Walk(root); err != nil {
+		return nil
+	}
+
+	var suggestions []patternSuggestion
+	if dirs {
+		suggestions = countedSuggestions(dirCounts, "directory", "directories")
+	}
+	return append(suggestions, countedSuggestions(fileCounts, "file", "files")...)
+}
+
+// countedSuggestions turns counts into suggestions, most common first, and
+// alphabetically among equals.
+func countedSuggestions(counts map[string]int, singular, pluralForm string) []patternSuggestion {
+	suggestions := make([]patternSuggestion, 0, len(counts))
+	for pattern, count := range counts {
+		suggestions = append(suggestions, patternSuggestion{
+			pattern:     pattern,
+			description: plural(count, singular, pluralForm),
+			count:       count,
+		})
+	}
+	slices.SortFunc(suggestions, func(a, b patternSuggestion) int {
+		return cmp.Or(cmp.Compare(b.count, a.count), cmp.Compare(a.pattern, b.pattern))
+	})
+	return suggestions
+}
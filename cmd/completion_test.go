@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPatternCompletion(t *testing.T) {
+	root := t.TempDir()
+	for _, path := range []string{"main.go", "util.go", "notes.md", "Makefile", "node_modules/x/index.js", ".git/hooks/pre-commit.sample"} {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		flag       string
+		toComplete string
+		want       []string
+	}{
+		{"include", "", []string{"*.go\t2 files", "*.js\t1 file", "*.md\t1 file", "Makefile\t1 file"}},
+		{"include", "*.m", []string{"*.md\t1 file"}},
+		{"include", "*.go,*.m", []string{"*.go,*.md\t1 file"}},
+		{"exclude", "", []string{".git\t1 directory", "node_modules\t1 directory", "x\t1 directory", "*.go\t2 files", "*.js\t1 file", "*.md\t1 file", "Makefile\t1 file"}},
+		{"exclude", "n", []string{"node_modules\t1 directory"}},
+	}
+	for _, tt := range tests {
+		got, directive := patternCompletion(tt.flag)(&cobra.Command{}, []string{root}, tt.toComplete)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("--%s %q completed to %q, expected %q", tt.flag, tt.toComplete, got, tt.want)
+		}
+		if directive != cobra.ShellCompDirectiveNoFileComp {
+			t.Errorf("--%s %q completed with directive %v, expected no file completion", tt.flag, tt.toComplete, directive)
+		}
+	}
+}
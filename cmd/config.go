@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configFileName is the name of both the user-level config file in the home
+// directory and the project-level one.
+const configFileName = ".wintree.yaml"
+
+var (
+	noConfig     bool
+	configGlobal bool
+	configForce  bool
+)
+
+// configSetting is one key of a config file with its values. Keys are flag
+// names; list values set the flag once per item.
+type configSetting struct {
+	key    string
+	values []string
+	// source is where the setting was read, for error messages
+	source string
+}
+
+// configTemplate is written by config init.
+const configTemplate = `# wintree settings. Keys are the long names of command-line flags, and flags
+# given on the command line always override them. A project's .wintree.yaml
+# (in the current directory or a parent) overrides ~/.wintree.yaml.
+
+# Patterns to exclude, as with --exclude
+exclude:
+  - .git
+  - node_modules
+
+# Maximum depth of the tree (-1 for unlimited), or a keyword: full, shallow, or
+# files-only. Commands that read the whole tree, such as hash, keep theirs.
+# depth: 2
+
+# What the deepest level counts: all (files and directories) or dirs (only directories)
+# depth-counts: all
+
+# Define or redefine --depth keywords as NAME=N
+# depth-keyword:
+#   - shallow=2
+#   - deep=6
+
+# Output format: tree, json, markdown, markdown-list, narrative, script, powershell, dot, or quickfix
+# format: tree
+
+# Colors: auto, always, or never
+# color: auto
+
+# File-type icons: auto, always, or never, drawn from emoji or nerd (Nerd Font glyphs)
+# icons: auto
+# icon-set: nerd
+# icon:
+#   - .vue=🟩
+
+# full-path: true
+
+# Root line for the current directory: dot (.), name, or full
+# relative-root-label: name
+`
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage wintree config files.",
+	Long: `Default flag values can be stored in a config file: ~/.wintree.yaml for
+every project, and .wintree.yaml in a project directory (found from the
+current directory upwards) for that project, which takes precedence. Flags
+given on the command line always override both. Use --no-config to ignore
+the files.
+
+Keys are the long names of the main command's flags, with a single value or
+a list:
+
+  exclude: [.git, node_modules]
+  depth: 3
+  format: markdown
+  color: always
+
+Subcommands use the settings for the flags they share with the main command.`,
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a config file with the common settings.",
+	Long: `Create .wintree.yaml in the current directory, or ~/.wintree.yaml with
+--global, holding the most common settings to edit. An existing file is only
+replaced with --force.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configFileName
+		if configGlobal {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to find the home directory: %w", err)
+			}
+			path = filepath.Join(home, configFileName)
+		}
+
+		if _, err := os.Stat(path); err == nil && !configForce {
+			return fmt.Errorf("%s already exists (use --force to replace it)", path)
+		}
+		if err := os.WriteFile(path, []byte(configTemplate), 0644); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+		fmt.Printf("Config written to %s\n", path)
+		return nil
+	},
+}
+
+// applyConfig sets every flag of cmd that was not given on the command line
+// from the config files, the user-level one first so that the project-level
+// one overrides it.
+func applyConfig(cmd *cobra.Command) error {
+	if noConfig || cmd.Parent() == configCmd {
+		return nil
+	}
+
+	fromCommandLine := make(map[string]bool)
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		fromCommandLine[flag.Name] = true
+	})
+
+	for _, path := range configPaths() {
+		settings, err := loadConfig(path)
+		if err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		if err := applySettings(cmd, settings, fromCommandLine); err != nil {
+			// The mistake is in the file, not the command line
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
+	return nil
+}
+
+// configPaths returns the config files that exist, in the order they apply:
+// ~/.wintree.yaml, then the nearest .wintree.yaml in the current directory or
+// one of its parents.
+func configPaths() []string {
+	var paths []string
+
+	userPath := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		userPath = filepath.Join(home, configFileName)
+		if _, err := os.Stat(userPath); err == nil {
+			paths = append(paths, userPath)
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return paths
+	}
+	if path := findProjectConfig(dir); path != "" {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// findProjectConfig returns the nearest .wintree.yaml in dir or one of its
+// parents, other than ~/.wintree.yaml, or "" if there is none. The search
+// stops at the home directory's file, as it is not a project's.
+func findProjectConfig(dir string) string {
+	userPath := ""
+	if home, err := os.UserHomeDir(); err == nil {
+		userPath = filepath.Join(home, configFileName)
+	}
+	for {
+		path := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(path); err == nil {
+			if path == userPath {
+				return ""
+			}
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads the settings from a config file.
+func loadConfig(path string) ([]configSetting, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	settings, err := parseConfig(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	for i := range settings {
+		settings[i].source = path
+	}
+	return settings, nil
+}
+
+// parseConfig parses the YAML form of a config file: top-level keys with a
+// scalar, a flow list, or a block list of scalars.
+func parseConfig(text string) ([]configSetting, error) {
+	var settings []configSetting
+
+	for i, line := range strings.Split(text, "\n") {
+		lineNum := i + 1
+		line = strings.TrimRight(stripYAMLComment(line), " \t\r")
+		content := strings.TrimSpace(line)
+		if content == "" || content == "---" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '-' {
+			item, isItem := strings.CutPrefix(content, "-")
+			if !isItem || len(settings) == 0 {
+				return nil, fmt.Errorf("line %d: unexpected indentation", lineNum)
+			}
+			value, err := parseYAMLScalar(strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			last := &settings[len(settings)-1]
+			last.values = append(last.values, value)
+			continue
+		}
+
+		key, value, ok := strings.Cut(content, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("line %d: expected a key", lineNum)
+		}
+		setting := configSetting{key: strings.TrimSpace(key)}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, "["):
+			items, err := parseYAMLFlowList(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			setting.values = items
+		case value != "":
+			parsed, err := parseYAMLScalar(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			setting.values = []string{parsed}
+		}
+		settings = append(settings, setting)
+	}
+
+	return settings, nil
+}
+
+// applySettings sets the flags of cmd from settings, skipping the flags given
+// on the command line. Subcommands only take the settings for flags they
+// share with the main command; a flag with the same name but another meaning,
+// such as the --format of watch, is left alone.
+func applySettings(cmd *cobra.Command, settings []configSetting, fromCommandLine map[string]bool) error {
+	for _, setting := range settings {
+		rootFlag := rootCmd.Flags().Lookup(setting.key)
+		if rootFlag == nil {
+			return fmt.Errorf("%s: unknown setting %q", setting.source, setting.key)
+		}
+
+		flag := cmd.Flags().Lookup(setting.key)
+		if flag == nil || fromCommandLine[flag.Name] || (cmd != rootCmd && flag.Usage != rootFlag.Usage) {
+			continue
+		}
+		if err := setFlag(cmd.Flags(), flag, setting.values); err != nil {
+			return fmt.Errorf("%s: invalid %s: %w", setting.source, setting.key, err)
+		}
+	}
+	return nil
+}
+
+// setFlag sets a flag to the values of a setting. Lists replace the default
+// of a list flag; other flags take a single value. The flag is not marked as
+// changed: commands such as hash and snapshot check Changed to tell a --depth
+// given on the command line from their own defaults, which a setting meant
+// for the tree view should not override.
+func setFlag(flags *pflag.FlagSet, flag *pflag.Flag, values []string) error {
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		return sliceValue.Replace(values)
+	}
+	if len(values) != 1 {
+		return errors.New("expected a single value")
+	}
+	err := flags.Set(flag.Name, values[0])
+	flag.Changed = false
+	return err
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&noConfig, "no-config", "", false, "Ignore ~/.wintree.yaml and the project's .wintree.yaml")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyConfig(cmd); err != nil {
+			return err
+		}
+		// --depth keywords can be defined in the config files, so they are
+		// only resolved once those are applied
+		return asFlagError(resolveDepth())
+	}
+
+	configInitCmd.Flags().BoolVarP(&configGlobal, "global", "", false, "Create ~/.wintree.yaml instead of .wintree.yaml in the current directory")
+	configInitCmd.Flags().BoolVarP(&configForce, "force", "", false, "Replace an existing config file")
+	configCmd.AddCommand(configInitCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseConfig(t *testing.T) {
+	input := `# Project defaults
+exclude:
+  - .git
+  - "node_modules"   # dependencies
+include: ['*.go', "*.md"]
+depth: 3
+full-path: false
+`
+	settings, err := parseConfig(input)
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+
+	expected := []configSetting{
+		{key: "exclude", values: []string{".git", "node_modules"}},
+		{key: "include", values: []string{"*.go", "*.md"}},
+		{key: "depth", values: []string{"3"}},
+		{key: "full-path", values: []string{"false"}},
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("parseConfig() = %+v, expected %+v", settings, expected)
+	}
+
+	for _, invalid := range []string{
+		"  - orphan\n",
+		"exclude:\n  key: value\n",
+		"just a line\n",
+		"depth: \"3\n",
+	} {
+		if _, err := parseConfig(invalid); err == nil {
+			t.Errorf("parseConfig(%q) expected an error", invalid)
+		}
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	var depth int
+	var exclude []string
+	var format string
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().IntVarP(&depth, "depth", "d", 1, rootCmd.Flags().Lookup("depth").Usage)
+	cmd.Flags().StringSliceVarP(&exclude, "exclude", "e", []string{"default"}, rootCmd.Flags().Lookup("exclude").Usage)
+	// Same name as a root flag but a different meaning
+	cmd.Flags().StringVarP(&format, "format", "", "plain", "Event format")
+
+	if err := cmd.Flags().Parse([]string{"-d", "5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := []configSetting{
+		{key: "depth", values: []string{"2"}, source: "user"},
+		{key: "exclude", values: []string{".git", "dist"}, source: "user"},
+		{key: "format", values: []string{"json"}, source: "user"},
+		// Settings for root flags the command does not have are ignored
+		{key: "size", values: []string{"true"}, source: "user"},
+	}
+	if err := applySettings(cmd, settings, map[string]bool{"depth": true}); err != nil {
+		t.Fatalf("applySettings() error = %v", err)
+	}
+
+	if depth != 5 {
+		t.Errorf("depth = %d, expected the command-line value 5", depth)
+	}
+	if !reflect.DeepEqual(exclude, []string{".git", "dist"}) {
+		t.Errorf("exclude = %v, expected the config list to replace the default", exclude)
+	}
+	if format != "plain" {
+		t.Errorf("format = %q, expected the unrelated flag to be left alone", format)
+	}
+
+	for _, invalid := range [][]configSetting{
+		{{key: "bogus", values: []string{"1"}, source: "user"}},
+		{{key: "depth", values: []string{"two"}, source: "user"}},
+	} {
+		if err := applySettings(cmd, invalid, nil); err == nil {
+			t.Errorf("applySettings(%+v) expected an error", invalid)
+		}
+	}
+}
+
+// A project's depth setting is for the tree view: hash and snapshot still
+// cover the whole tree unless --depth is given on the command line.
+func TestConfigDepthWholeTreeCommands(t *testing.T) {
+	defer func(depth int, hashOut, snapshotOut string) {
+		maxDepth, hashOutput, snapshotOutput = depth, hashOut, snapshotOut
+	}(maxDepth, hashOutput, snapshotOutput)
+
+	tempDir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+	t.Chdir(tempDir)
+	if err := os.WriteFile(filepath.Join(tempDir, configFileName), []byte("depth: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deepFile := filepath.Join(tempDir, "a", "b", "deep.txt")
+	if err := os.MkdirAll(filepath.Dir(deepFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(deepFile, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := t.TempDir()
+	hashOutput = filepath.Join(outDir, "manifest.sha256")
+	snapshotOutput = filepath.Join(outDir, "snapshot.json")
+	for _, tt := range []struct {
+		cmd    *cobra.Command
+		output string
+	}{
+		{hashCmd, hashOutput},
+		{snapshotSaveCmd, snapshotOutput},
+	} {
+		maxDepth = 1
+		if err := applyConfig(tt.cmd); err != nil {
+			t.Fatalf("applyConfig() error = %v", err)
+		}
+		if err := tt.cmd.RunE(tt.cmd, []string{tempDir}); err != nil {
+			t.Fatalf("%s: error = %v", tt.cmd.Name(), err)
+		}
+		data, err := os.ReadFile(tt.output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "deep.txt") {
+			t.Errorf("%s left out a/b/deep.txt under a project config with depth: 1:\n%s", tt.cmd.Name(), data)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// noisePatterns match machine-generated files that are skipped in content dumps
+// unless --include-noise is given. They are still shown in the tree itself.
+var noisePatterns = []string{
+	// Lockfiles
+	"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb",
+	"go.sum", "Cargo.lock", "Gemfile.lock", "composer.lock", "poetry.lock", "Pipfile.lock",
+	"pubspec.lock", "mix.lock", "Podfile.lock", "packages.lock.json", "flake.lock",
+	// Minified bundles and source maps
+	"*.min.js", "*.min.mjs", "*.min.css", "*.bundle.js", "*.map",
+}
+
+// isNoiseFile reports whether a file name matches one of the noisePatterns.
+func isNoiseFile(name string) bool {
+	for _, pattern := range noisePatterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// contentSection is the dump of a single file: a metadata header that opens a
+// code fence, the file body, and the closing fence.
+type contentSection struct {
+	relPath string
+	lang    string
+	fence   string
+	header  string
+	body    string
+}
+
+// String renders the full section as it appears in the dump.
+func (s contentSection) String() string {
+	return s.header + s.body + s.footer()
+}
+
+// footer closes the section's code fence, if it has one.
+func (s contentSection) footer() string {
+	if s.fence == "" {
+		return ""
+	}
+	return s.fence + "\n"
+}
+
+// continuedHeader is used in place of header when a section is split across
+// several parts of a --split-tokens dump.
+func (s contentSection) continuedHeader() string {
+	return "\n=== " + s.relPath + " (continued) ===\n" + s.fence + s.lang + "\n"
+}
+
+// languageHints maps file extensions to the language names used on code fences.
+var languageHints = map[string]string{
+	".go": "go", ".js": "javascript", ".mjs": "javascript", ".cjs": "javascript", ".jsx": "jsx",
+	".ts": "typescript", ".tsx": "tsx", ".py": "python", ".rb": "ruby", ".rs": "rust",
+	".java": "java", ".kt": "kotlin", ".swift": "swift", ".c": "c", ".h": "c",
+	".cpp": "cpp", ".cc": "cpp", ".hpp": "cpp", ".cs": "csharp", ".php": "php",
+	".dart": "dart", ".ex": "elixir", ".exs": "elixir", ".sh": "bash", ".bash": "bash",
+	".ps1": "powershell", ".sql": "sql", ".html": "html", ".css": "css", ".scss": "scss",
+	".json": "json", ".yaml": "yaml", ".yml": "yaml", ".toml": "toml", ".xml": "xml",
+	".md": "markdown", ".mod": "go-mod", ".lua": "lua", ".vue": "vue", ".svelte": "svelte",
+}
+
+// languageHint returns the code fence language for a file name, or an empty
+// string if the extension is unknown.
+func languageHint(name string) string {
+	switch name {
+	case "Dockerfile":
+		return "dockerfile"
+	case "Makefile":
+		return "makefile"
+	}
+	return languageHints[strings.ToLower(filepath.Ext(name))]
+}
+
+// codeFence returns a backtick fence long enough to wrap content that itself
+// contains backtick runs, such as Markdown files with code blocks.
+func codeFence(content []byte) string {
+	longest, run := 0, 0
+	for _, b := range content {
+		if b == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// formatSize renders a byte count in human-readable units: powers of 1024,
+// as Explorer shows them, or of 1000 with --si, as macOS Finder does.
+func formatSize(size int64) string {
+	unit, prefixes := int64(1024), "KMGTPE"
+	if siUnits {
+		unit, prefixes = 1000, "kMGTPE"
+	}
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := unit, 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), prefixes[exp])
+}
+
+// lastCommitInfo returns the abbreviated hash, date, and subject of the last
+// git commit that touched path, or an empty string if it cannot be determined.
+func lastCommitInfo(root, path string) string {
+	cmd := exec.Command("git", "log", "-1", "--format=%h %as %s", "--", path)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// defaultMaxDumpSize is the --max-file-size used unless another is given.
+const defaultMaxDumpSize = "256K"
+
+// maxDumpSize is the --max-file-size setting: the largest file whose contents
+// are dumped, or "0" for no limit.
+var maxDumpSize string
+
+// contentSizeLimit returns the largest file size --contents dumps, or -1 if
+// there is no limit.
+func contentSizeLimit() (int64, error) {
+	limit, err := parseSize(maxDumpSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --max-file-size: %w", err)
+	}
+	if limit == 0 {
+		return -1, nil
+	}
+	return limit, nil
+}
+
+// buildContentsOutput appends the contents of every matched file after the tree,
+// each delimited by a header with its path relative to root.
+func buildContentsOutput(root string, paths []string) (string, error) {
+	sections, err := collectContentSections(root, paths)
+	if err != nil {
+		return "", err
+	}
+
+	var output strings.Builder
+	for _, section := range sections {
+		output.WriteString(section.String())
+	}
+	return output.String(), nil
+}
+
+// collectContentSections returns one section per matched file, in the same
+// order as paths.
+func collectContentSections(root string, paths []string) ([]contentSection, error) {
+	limit, err := contentSizeLimit()
+	if err != nil {
+		return nil, err
+	}
+	var sections []contentSection
+
+	for _, path := range paths {
+		info, err := statCached(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if !includeNoise && isNoiseFile(info.Name()) {
+			continue
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			relPath = path
+		}
+		relPath = filepath.ToSlash(relPath)
+		if anonymizing() {
+			relPath = anonymizeRelPath(relPath)
+		}
+
+		// Files too large or binary to be worth reading are listed with the reason
+		skipped := ""
+		if limit >= 0 && info.Size() > limit {
+			skipped = "larger than --max-file-size " + formatSize(limit)
+		} else if isBinaryFile(path) {
+			skipped = "binary"
+		}
+		if skipped != "" {
+			sections = append(sections, contentSection{
+				relPath: relPath,
+				header:  "\n=== " + relPath + " ===\nsize: " + formatSize(info.Size()) + " | skipped: " + skipped + "\n",
+			})
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		// Metadata line: size, language, and optionally the last commit
+		meta := []string{"size: " + formatSize(info.Size())}
+		lang := languageHint(info.Name())
+		if lang != "" {
+			meta = append(meta, "language: "+lang)
+		}
+		if showCommitInfo {
+			if commit := lastCommitInfo(root, path); commit != "" {
+				meta = append(meta, "last commit: "+commit)
+			}
+		}
+
+		fence := codeFence(content)
+		body := string(content)
+		if len(body) > 0 && !strings.HasSuffix(body, "\n") {
+			body += "\n"
+		}
+
+		sections = append(sections, contentSection{
+			relPath: relPath,
+			lang:    lang,
+			fence:   fence,
+			header:  "\n=== " + relPath + " ===\n" + strings.Join(meta, " | ") + "\n" + fence + lang + "\n",
+			body:    body,
+		})
+	}
+
+	return sections, nil
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsNoiseFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"package-lock.json", true},
+		{"go.sum", true},
+		{"yarn.lock", true},
+		{"app.min.js", true},
+		{"styles.min.css", true},
+		{"app.js.map", true},
+		{"main.go", false},
+		{"package.json", false},
+		{"go.mod", false},
+		{"minimal.js", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isNoiseFile(tt.name); result != tt.expected {
+				t.Errorf("isNoiseFile(%q) = %v, expected %v", tt.name, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildContentsOutput(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wintree_contents_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	structure := map[string]string{
+		"main.go":        "package main",
+		"go.sum":         "example.com/mod v1.0.0 h1:abc=",
+		"web/app.min.js": "var a=1;",
+		"web/app.js":     "console.log('hi')\n",
+	}
+
+	var paths []string
+	for file, content := range structure {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, fullPath)
+	}
+	// Directories in the path list must be skipped
+	paths = append(paths, filepath.Join(tempDir, "web"))
+
+	t.Run("noise skipped by default", func(t *testing.T) {
+		includeNoise = false
+
+		output, err := buildContentsOutput(tempDir, paths)
+		if err != nil {
+			t.Fatalf("buildContentsOutput() error = %v", err)
+		}
+
+		for _, expected := range []string{
+			"=== main.go ===\nsize: 12 B | language: go\n```go\npackage main\n```\n",
+			"=== web/app.js ===\nsize: 18 B | language: javascript\n```javascript\nconsole.log('hi')\n```\n",
+		} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("buildContentsOutput() missing %q in:\n%s", expected, output)
+			}
+		}
+		for _, unexpected := range []string{"go.sum", "app.min.js", "=== web ==="} {
+			if strings.Contains(output, unexpected) {
+				t.Errorf("buildContentsOutput() should not contain %q in:\n%s", unexpected, output)
+			}
+		}
+	})
+
+	t.Run("include noise", func(t *testing.T) {
+		includeNoise = true
+		defer func() { includeNoise = false }()
+
+		output, err := buildContentsOutput(tempDir, paths)
+		if err != nil {
+			t.Fatalf("buildContentsOutput() error = %v", err)
+		}
+
+		for _, expected := range []string{"=== go.sum ===", "=== web/app.min.js ==="} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("buildContentsOutput() missing %q in:\n%s", expected, output)
+			}
+		}
+	})
+	t.Run("large and binary files skipped", func(t *testing.T) {
+		originalLimit := maxDumpSize
+		defer func() { maxDumpSize = originalLimit }()
+		maxDumpSize = "16"
+
+		binary := filepath.Join(tempDir, "logo.png")
+		if err := os.WriteFile(binary, []byte("\x89PNG\x00\x01"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		output, err := buildContentsOutput(tempDir, append(paths, binary))
+		if err != nil {
+			t.Fatalf("buildContentsOutput() error = %v", err)
+		}
+
+		for _, expected := range []string{
+			"=== main.go ===\nsize: 12 B | language: go\n```go\npackage main\n```\n",
+			"=== web/app.js ===\nsize: 18 B | skipped: larger than --max-file-size 16 B\n",
+			"=== logo.png ===\nsize: 6 B | skipped: binary\n",
+		} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("buildContentsOutput() missing %q in:\n%s", expected, output)
+			}
+		}
+		if strings.Contains(output, "console.log") || strings.Contains(output, "PNG") {
+			t.Errorf("buildContentsOutput() dumped a skipped file:\n%s", output)
+		}
+
+		maxDumpSize = "0"
+		if output, _ := buildContentsOutput(tempDir, paths); !strings.Contains(output, "console.log") {
+			t.Errorf("buildContentsOutput() with no limit skipped a file:\n%s", output)
+		}
+	})
+}
+
+func TestLanguageHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"main.go", "go"},
+		{"App.TSX", "tsx"},
+		{"script.py", "python"},
+		{"Dockerfile", "dockerfile"},
+		{"notes.unknownext", ""},
+		{"LICENSE", ""},
+	}
+
+	for _, tt := range tests {
+		if result := languageHint(tt.name); result != tt.expected {
+			t.Errorf("languageHint(%q) = %q, expected %q", tt.name, result, tt.expected)
+		}
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"package main", "```"},
+		{"use `code` inline", "```"},
+		{"```go\nfmt.Println()\n```", "````"},
+		{"`````", "``````"},
+	}
+
+	for _, tt := range tests {
+		if result := codeFence([]byte(tt.content)); result != tt.expected {
+			t.Errorf("codeFence(%q) = %q, expected %q", tt.content, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		if result := formatSize(tt.size); result != tt.expected {
+			t.Errorf("formatSize(%d) = %q, expected %q", tt.size, result, tt.expected)
+		}
+	}
+	originalSI := siUnits
+	defer func() { siUnits = originalSI }()
+	siUnits = true
+	for size, expected := range map[int64]string{999: "999 B", 1000: "1.0 kB", 1536: "1.5 kB", 5 * 1024 * 1024: "5.2 MB"} {
+		if result := formatSize(size); result != expected {
+			t.Errorf("formatSize(%d) with --si = %q, expected %q", size, result, expected)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+)
+
+// copyFiles puts the matched files themselves on the clipboard, rather than
+// the tree as text.
+var copyFiles bool
+
+// clipboardFiles returns the regular files among paths. Directories are left
+// out, as pasting one would copy everything in it, matched or not.
+func clipboardFiles(paths []string) []string {
+	var files []string
+	for _, path := range paths {
+		if info, err := lstatCached(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// copyFileReferences puts the files among paths on the clipboard, so they can
+// be pasted into Explorer, Finder, or an email.
+func copyFileReferences(paths []string) error {
+	files := clipboardFiles(paths)
+	if len(files) == 0 {
+		return fmt.Errorf("no files to copy to the clipboard")
+	}
+
+	if err := writeClipboardFiles(files); err != nil {
+		return fmt.Errorf("failed to copy files to clipboard: %w", err)
+	}
+	info("%s copied to clipboard.", plural(len(files), "file", "files"))
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// pasteboardFilesScript writes its arguments to the general pasteboard as file
+// URLs, which Finder and Mail paste as the files themselves.
+const pasteboardFilesScript = `ObjC.import("AppKit");
+function run(paths) {
+	var pasteboard = $.NSPasteboard.generalPasteboard;
+	pasteboard.clearContents;
+	var urls = paths.map(function (path) { return $.NSURL.fileURLWithPath(path); });
+	if (!pasteboard.writeObjects($(urls))) {
+		throw new Error("the pasteboard refused the files");
+	}
+}`
+
+// clipboardFilesSupported reports whether files can be put on the clipboard.
+func clipboardFilesSupported() bool {
+	return true
+}
+
+// writeClipboardFiles puts paths on the pasteboard as file URLs, through
+// osascript so that no cgo is needed.
+func writeClipboardFiles(paths []string) error {
+	args := append([]string{"-l", "JavaScript", "-e", pasteboardFilesScript}, paths...)
+	if output, err := exec.Command("osascript", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("osascript: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !darwin
+
+package cmd
+
+import "errors"
+
+// clipboardFilesSupported reports whether files can be put on the clipboard.
+// Linux desktops disagree on the format, so only text is copied there.
+func clipboardFilesSupported() bool {
+	return false
+}
+
+// writeClipboardFiles is unavailable on this platform.
+func writeClipboardFiles(paths []string) error {
+	return errors.New("copying files to the clipboard is only supported on Windows and macOS")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestClipboardFiles(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "src")
+	file := filepath.Join(dir, "main.go")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(root, "gone.txt")
+
+	got := clipboardFiles([]string{root, dir, file, missing})
+	if want := []string{file}; !slices.Equal(got, want) {
+		t.Errorf("clipboardFiles() = %v, expected only the regular files %v", got, want)
+	}
+
+	if err := copyFileReferences([]string{dir}); err == nil {
+		t.Error("copyFileReferences() with no files returned no error")
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// cfHDROP is the clipboard format Explorer pastes files from.
+const cfHDROP = 15
+
+// gmemMoveable allocates global memory the clipboard can take ownership of.
+const gmemMoveable = 0x0002
+
+var (
+	user32               = windows.NewLazySystemDLL("user32.dll")
+	kernel32             = windows.NewLazySystemDLL("kernel32.dll")
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procEmptyClipboard   = user32.NewProc("EmptyClipboard")
+	procSetClipboardData = user32.NewProc("SetClipboardData")
+	procGlobalAlloc      = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree       = kernel32.NewProc("GlobalFree")
+	procGlobalLock       = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory    = kernel32.NewProc("RtlMoveMemory")
+)
+
+// dropFiles mirrors the Win32 DROPFILES structure that heads CF_HDROP data.
+type dropFiles struct {
+	pFiles uint32
+	ptX    int32
+	ptY    int32
+	fNC    int32
+	fWide  int32
+}
+
+// clipboardFilesSupported reports whether files can be put on the clipboard.
+func clipboardFilesSupported() bool {
+	return true
+}
+
+// writeClipboardFiles puts paths on the clipboard as CF_HDROP, the format
+// Explorer and mail clients paste files from.
+func writeClipboardFiles(paths []string) error {
+	// DROPFILES is followed by the paths, each ending in a NUL, and a final NUL
+	var names []uint16
+	for _, path := range paths {
+		name, err := windows.UTF16FromString(path)
+		if err != nil {
+			return err
+		}
+		names = append(names, name...)
+	}
+	names = append(names, 0)
+
+	header := dropFiles{pFiles: uint32(unsafe.Sizeof(dropFiles{})), fWide: 1}
+	buf := make([]byte, int(header.pFiles)+len(names)*2)
+	*(*dropFiles)(unsafe.Pointer(&buf[0])) = header
+	copy(buf[header.pFiles:], unsafe.Slice((*byte)(unsafe.Pointer(&names[0])), len(names)*2))
+	size := uintptr(len(buf))
+
+	handle, _, err := procGlobalAlloc.Call(gmemMoveable, size)
+	if handle == 0 {
+		return fmt.Errorf("GlobalAlloc: %w", err)
+	}
+	data, _, err := procGlobalLock.Call(handle)
+	if data == 0 {
+		procGlobalFree.Call(handle)
+		return fmt.Errorf("GlobalLock: %w", err)
+	}
+	procRtlMoveMemory.Call(data, uintptr(unsafe.Pointer(&buf[0])), size)
+	procGlobalUnlock.Call(handle)
+
+	// Another program may hold the clipboard for a moment
+	opened := false
+	for attempt := 0; attempt < 10 && !opened; attempt++ {
+		if r, _, _ := procOpenClipboard.Call(0); r != 0 {
+			opened = true
+		} else {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	if !opened {
+		procGlobalFree.Call(handle)
+		return errors.New("the clipboard is in use by another program")
+	}
+	defer procCloseClipboard.Call()
+
+	procEmptyClipboard.Call()
+	if r, _, err := procSetClipboardData.Call(cfHDROP, handle); r == 0 {
+		procGlobalFree.Call(handle)
+		return fmt.Errorf("SetClipboardData: %w", err)
+	}
+	// The clipboard owns the memory now
+	return nil
+}
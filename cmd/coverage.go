@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// coveragePath is the --coverage profile, a Go cover profile or an lcov file.
+var coveragePath string
+
+// Files below these percentages are colored red and yellow by --coverage.
+const (
+	lowCoverage    = 50
+	mediumCoverage = 80
+)
+
+// coverageCount is how many of the statements (Go) or lines (lcov) of a file,
+// or of every file beneath a directory, are covered.
+type coverageCount struct {
+	covered, total int
+}
+
+// coverageCounts maps the absolute path of every file in the profile, and of
+// each of their parent directories, to its coverage. It is nil unless
+// --coverage is given.
+var coverageCounts map[string]coverageCount
+
+// loadCoverage reads a coverage profile into coverageCounts. Go profiles name
+// files by import path, which are found on disk from the go.mod of root;
+// relative lcov paths are taken from the current directory.
+func loadCoverage(profile, root string) error {
+	f, err := os.Open(profile)
+	if err != nil {
+		return fmt.Errorf("failed to open coverage profile: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	head, _ := reader.Peek(512)
+	var files map[string]coverageCount
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(string(head)), "mode:"):
+		files, err = parseGoCoverProfile(reader, goFileResolver(root))
+	case strings.Contains(string(head), "SF:"):
+		files, err = parseLcov(reader)
+	default:
+		return fmt.Errorf("%s is neither a Go cover profile nor an lcov file", profile)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid coverage profile %s: %w", profile, err)
+	}
+
+	coverageCounts = make(map[string]coverageCount)
+	for path, count := range files {
+		for dir := path; ; dir = filepath.Dir(dir) {
+			sum := coverageCounts[dir]
+			coverageCounts[dir] = coverageCount{covered: sum.covered + count.covered, total: sum.total + count.total}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// goFileResolver returns a function that maps the import path of a Go source
+// file to its absolute path, using the module path in the go.mod of root or
+// its nearest parent. Absolute paths are kept as they are.
+func goFileResolver(root string) func(name string) string {
+	modulePath, moduleDir := "", ""
+	for dir := root; ; dir = filepath.Dir(dir) {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+					modulePath, moduleDir = strings.Trim(strings.TrimSpace(rest), `"`), dir
+					break
+				}
+			}
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	return func(name string) string {
+		if filepath.IsAbs(name) {
+			return filepath.Clean(name)
+		}
+		if rest, ok := strings.CutPrefix(name, modulePath+"/"); ok && modulePath != "" {
+			return filepath.Join(moduleDir, filepath.FromSlash(rest))
+		}
+		return ""
+	}
+}
+
+// parseGoCoverProfile reads the statement coverage of each file in a Go cover
+// profile, as written by go test -coverprofile. Blocks listed more than once,
+// as in merged profiles, are counted once and covered if any run covered them.
+// Files that resolve to "" are skipped.
+func parseGoCoverProfile(r io.Reader, resolve func(name string) string) (map[string]coverageCount, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]block)
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// name.go:12.34,15.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.Contains(fields[0], ":") {
+			return nil, fmt.Errorf("line %d: expected FILE:RANGE STATEMENTS COUNT", lineNum)
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("line %d: expected FILE:RANGE STATEMENTS COUNT", lineNum)
+		}
+		previous := blocks[fields[0]]
+		blocks[fields[0]] = block{statements: statements, covered: previous.covered || count > 0}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]coverageCount)
+	for key, block := range blocks {
+		name := key[:strings.LastIndex(key, ":")]
+		path := resolve(name)
+		if path == "" {
+			continue
+		}
+		count := files[path]
+		count.total += block.statements
+		if block.covered {
+			count.covered += block.statements
+		}
+		files[path] = count
+	}
+	return files, nil
+}
+
+// parseLcov reads the line coverage of each source file in an lcov tracefile
+// from its DA records.
+func parseLcov(r io.Reader) (map[string]coverageCount, error) {
+	files := make(map[string]coverageCount)
+	current := ""
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			path, err := filepath.Abs(strings.TrimPrefix(line, "SF:"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			current = path
+		case strings.HasPrefix(line, "DA:") && current != "":
+			// DA:LINE,HITS[,CHECKSUM]
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: expected DA:LINE,HITS", lineNum)
+			}
+			hits, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: expected DA:LINE,HITS", lineNum)
+			}
+			count := files[current]
+			count.total++
+			if hits > 0 {
+				count.covered++
+			}
+			files[current] = count
+		case line == "end_of_record":
+			current = ""
+		}
+	}
+	return files, scanner.Err()
+}
+
+// coverageColumn returns the coverage of a node as a percentage, or "" for
+// nodes the profile does not cover.
+func coverageColumn(node *tree.Node) string {
+	count, ok := coverageCounts[node.Path]
+	if node.Path == "" || !ok || count.total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", count.percent())
+}
+
+// coverageColor returns the SGR sequence for a file with low coverage, or ""
+// if its coverage is fine or unknown.
+func coverageColor(node *tree.Node) string {
+	count, ok := coverageCounts[node.Path]
+	if node.IsDir || !ok || count.total == 0 {
+		return ""
+	}
+	switch percent := count.percent(); {
+	case percent < lowCoverage:
+		return "31"
+	case percent < mediumCoverage:
+		return "33"
+	}
+	return ""
+}
+
+func (c coverageCount) percent() float64 {
+	return float64(c.covered) / float64(c.total) * 100
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestParseGoCoverProfile(t *testing.T) {
+	profile := `mode: set
+example.com/app/main.go:5.13,7.2 2 1
+example.com/app/main.go:9.13,12.2 3 0
+example.com/app/pkg/util.go:3.20,5.2 1 0
+example.com/app/pkg/util.go:3.20,5.2 1 1
+other.org/lib/lib.go:1.1,2.2 4 1
+`
+	resolve := func(name string) string {
+		if rest, ok := strings.CutPrefix(name, "example.com/app/"); ok {
+			return "/src/app/" + rest
+		}
+		return ""
+	}
+
+	files, err := parseGoCoverProfile(strings.NewReader(profile), resolve)
+	if err != nil {
+		t.Fatalf("parseGoCoverProfile() error = %v", err)
+	}
+	expected := map[string]coverageCount{
+		"/src/app/main.go":     {covered: 2, total: 5},
+		"/src/app/pkg/util.go": {covered: 1, total: 1},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("parseGoCoverProfile() = %v, expected %v", files, expected)
+	}
+
+	if _, err := parseGoCoverProfile(strings.NewReader("mode: set\nmain.go 1 1\n"), resolve); err == nil {
+		t.Error("parseGoCoverProfile() expected an error for a malformed line")
+	}
+}
+
+func TestParseLcov(t *testing.T) {
+	dir := t.TempDir()
+	index, util := filepath.Join(dir, "index.js"), filepath.Join(dir, "util.js")
+	tracefile := "TN:\n" +
+		"SF:" + index + "\nDA:1,4\nDA:2,0\nDA:3,1\nLF:3\nLH:2\nend_of_record\n" +
+		"SF:" + util + "\nDA:1,0\nend_of_record\n"
+
+	files, err := parseLcov(strings.NewReader(tracefile))
+	if err != nil {
+		t.Fatalf("parseLcov() error = %v", err)
+	}
+	expected := map[string]coverageCount{
+		index: {covered: 2, total: 3},
+		util:  {covered: 0, total: 1},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("parseLcov() = %v, expected %v", files, expected)
+	}
+}
+
+func TestLoadCoverage(t *testing.T) {
+	tempDir := t.TempDir()
+	for file, content := range map[string]string{
+		"go.mod":        "module example.com/app\n\ngo 1.24\n",
+		"main.go":       "package main\n",
+		"pkg/util.go":   "package pkg\n",
+		"cover.out":     "mode: count\nexample.com/app/main.go:1.1,2.2 9 0\nexample.com/app/main.go:3.1,4.2 1 3\nexample.com/app/pkg/util.go:1.1,2.2 4 2\n",
+		"not-cover.txt": "hello\n",
+	} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() { coverageCounts = nil }()
+
+	if err := loadCoverage(filepath.Join(tempDir, "not-cover.txt"), tempDir); err == nil {
+		t.Error("loadCoverage() expected an error for a file that is not a profile")
+	}
+	if err := loadCoverage(filepath.Join(tempDir, "cover.out"), filepath.Join(tempDir, "pkg")); err != nil {
+		t.Fatalf("loadCoverage() error = %v", err)
+	}
+
+	tests := []struct {
+		path          string
+		isDir         bool
+		expected, sgr string
+	}{
+		{"", true, "35.7%", ""},
+		{"main.go", false, "10.0%", "31"},
+		{"pkg", true, "100.0%", ""},
+		{"pkg/util.go", false, "100.0%", ""},
+		{"go.mod", false, "", ""},
+	}
+	for _, tt := range tests {
+		node := &tree.Node{Path: filepath.Join(tempDir, filepath.FromSlash(tt.path)), IsDir: tt.isDir}
+		if column := coverageColumn(node); column != tt.expected {
+			t.Errorf("coverageColumn(%q) = %q, expected %q", tt.path, column, tt.expected)
+		}
+		if sgr := coverageColor(node); sgr != tt.sgr {
+			t.Errorf("coverageColor(%q) = %q, expected %q", tt.path, sgr, tt.sgr)
+		}
+	}
+}
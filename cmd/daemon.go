@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/cobra"
+)
+
+// daemonSocket is the --socket the daemon listens on and query connects to,
+// or "" for the default.
+var daemonSocket string
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon [ROOT...]",
+	Short: "Keep trees indexed in memory to answer wintree query instantly.",
+	Long: `List every directory beneath the given roots (the current directory by
+default) once, keep the listings in memory, and keep them current by watching
+for changes. "wintree query" then walks the listings instead of the disk,
+which makes repeated trees of a huge monorepo near-instant.
+
+Queries take the usual filters and output flags, and any path beneath one of
+the roots. Directories matching --exclude are left out of the index and read
+from disk when a query asks for them, which keeps the number of watches down
+for trees such as node_modules. The daemon runs until Ctrl+C and listens on
+--socket, by default a local socket in the user's cache directory.
+
+  wintree daemon ~/src/monorepo -e .git -e node_modules
+  wintree query ~/src/monorepo/services -d 2 -i "*.go"`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		socket, err := daemonSocketPath()
+		if err != nil {
+			return err
+		}
+		filters := processFilters(excludePatterns, nil)
+		if filters.err != nil {
+			return asFlagError(filters.err)
+		}
+
+		// Listening first fails fast when a daemon is already running
+		listener, err := listenDaemon(socket)
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("failed to start watcher: %w", err)
+		}
+		defer watcher.Close()
+
+		index := newDaemonIndex(watcher, filters)
+		for _, arg := range args {
+			root, err := resolveStartPath([]string{arg})
+			if err != nil {
+				return err
+			}
+			started := time.Now()
+			if err := index.add(root, root); err != nil {
+				return fmt.Errorf("failed to index %s: %w", root, err)
+			}
+			index.roots = append(index.roots, root)
+			fmt.Printf("Indexed %s in %s.\n", root, time.Since(started).Round(time.Millisecond))
+		}
+
+		fmt.Printf("Listening on %s. Press Ctrl+C to stop.\n", socket)
+
+		go index.serve(listener)
+
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return nil
+				}
+				index.update(event)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return nil
+				}
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			case <-interrupt:
+				return nil
+			}
+		}
+	},
+}
+
+// daemonSocketPath returns --socket, or daemon.sock in the user's cache
+// directory, creating the directory if needed.
+func daemonSocketPath() (string, error) {
+	if daemonSocket != "" {
+		return daemonSocket, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find a directory for the daemon socket (use --socket): %w", err)
+	}
+	dir := filepath.Join(cache, "wintree")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.sock"), nil
+}
+
+// listenDaemon listens on socket, replacing a socket file left behind by a
+// daemon that is no longer running.
+func listenDaemon(socket string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", socket)
+	}
+	if info, err := os.Lstat(socket); err == nil {
+		// --socket may name any file; only a stale socket is replaced
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, fmt.Errorf("failed to remove the stale socket %s: %w", socket, err)
+		}
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	return listener, nil
+}
+
+// daemonRequest asks the daemon to walk Root with the walk options of a query.
+type daemonRequest struct {
+	Root            string   `json:"root"`
+	Exclude         []string `json:"exclude,omitempty"`
+	Include         []string `json:"include,omitempty"`
+	ExcludeRegexp   []string `json:"exclude_regexp,omitempty"`
+	IncludeRegexp   []string `json:"include_regexp,omitempty"`
+	MaxDepth        int      `json:"max_depth"`
+	DepthCountsDirs bool     `json:"depth_counts_dirs,omitempty"`
+	DirsDepth       int      `json:"dirs_depth,omitempty"`
+	ShowOSFiles     bool     `json:"show_os_files,omitempty"`
+	FollowSymlinks  bool     `json:"follow_symlinks,omitempty"`
+}
+
+// daemonResponse is the result of a walk: the matching paths, the metadata
+// of those paths and the directories above them, and what the walk left out.
+type daemonResponse struct {
+	Paths    []string          `json:"paths"`
+	Entries  []daemonEntry     `json:"entries"`
+	Excluded []daemonExclusion `json:"excluded,omitempty"`
+	Repeats  map[string]string `json:"repeats,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// daemonEntry is the metadata of one path, as its directory listing had it.
+type daemonEntry struct {
+	Path    string      `json:"path"`
+	Mode    fs.FileMode `json:"mode"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mtime"`
+}
+
+// daemonExclusion is an entry left out by an exclusion rule.
+type daemonExclusion struct {
+	Path string `json:"path"`
+	Rule string `json:"rule"`
+}
+
+// daemonIndex holds the listing of every watched directory beneath the
+// daemon's roots, keyed by path. A directory that is not in it, because it
+// is excluded or could not be watched, is read from disk instead, so walks
+// are always correct.
+type daemonIndex struct {
+	watcher *fsnotify.Watcher
+	filters filter
+	roots   []string
+
+	mu   sync.RWMutex
+	dirs map[string][]fs.DirEntry
+}
+
+// newDaemonIndex returns an empty index that adds its directories to watcher
+// and leaves out those the filters exclude.
+func newDaemonIndex(watcher *fsnotify.Watcher, filters filter) *daemonIndex {
+	return &daemonIndex{watcher: watcher, filters: filters, dirs: make(map[string][]fs.DirEntry)}
+}
+
+// add lists and watches dir and every directory beneath it that root's
+// filters do not exclude.
+func (ix *daemonIndex) add(root, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		// Unreadable directories are left to be read from disk
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && isExcludedPath(root, path, ix.filters) {
+			return fs.SkipDir
+		}
+		// A directory is only indexed if changes to it will be seen
+		if err := ix.watcher.Add(path); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to watch %s, reading it from disk: %v\n", path, err)
+			return nil
+		}
+		if err := ix.relist(path); err != nil && path == dir {
+			return err
+		}
+		return nil
+	})
+}
+
+// relist replaces the listing of dir with a fresh one, taking each entry's
+// metadata along so that queries need not stat anything.
+func (ix *daemonIndex) relist(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		ix.drop(dir)
+		return err
+	}
+	listing := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil {
+			listing = append(listing, fs.FileInfoToDirEntry(info))
+		}
+	}
+	ix.mu.Lock()
+	ix.dirs[dir] = listing
+	ix.mu.Unlock()
+	return nil
+}
+
+// drop removes dir and everything beneath it from the index.
+func (ix *daemonIndex) drop(dir string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	prefix := dir + string(filepath.Separator)
+	for path := range ix.dirs {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			delete(ix.dirs, path)
+		}
+	}
+}
+
+// indexed reports whether dir is in the index.
+func (ix *daemonIndex) indexed(dir string) bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	_, ok := ix.dirs[dir]
+	return ok
+}
+
+// update applies a filesystem event to the index: the directory holding the
+// changed entry is listed again, and directories created or removed are
+// added or dropped along with their contents.
+func (ix *daemonIndex) update(event fsnotify.Event) {
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		ix.drop(event.Name)
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+			if root := ix.rootOf(event.Name); root != "" {
+				if err := ix.add(root, event.Name); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to index %s: %v\n", event.Name, err)
+				}
+			}
+		}
+	}
+	if parent := filepath.Dir(event.Name); ix.indexed(parent) {
+		if err := ix.relist(parent); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to list %s: %v\n", parent, err)
+		}
+	}
+}
+
+// rootOf returns the root that path is beneath, or "" if there is none.
+func (ix *daemonIndex) rootOf(path string) string {
+	for _, root := range ix.roots {
+		if path == root || isBelow(path, root) {
+			return root
+		}
+	}
+	return ""
+}
+
+// readDir lists dir from the index, or from disk if the index lacks it.
+func (ix *daemonIndex) readDir(dir string) ([]fs.DirEntry, error) {
+	ix.mu.RLock()
+	entries, ok := ix.dirs[dir]
+	ix.mu.RUnlock()
+	if !ok {
+		return os.ReadDir(dir)
+	}
+	return entries, nil
+}
+
+// serve answers each connection's request until the listener is closed.
+func (ix *daemonIndex) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			var request daemonRequest
+			if err := json.NewDecoder(conn).Decode(&request); err != nil {
+				return
+			}
+			json.NewEncoder(conn).Encode(ix.query(request))
+		}()
+	}
+}
+
+// query walks the index as a request asks. Listings are replaced rather than
+// changed in place, so walks need no lock beyond each readDir.
+func (ix *daemonIndex) query(request daemonRequest) daemonResponse {
+	if ix.rootOf(request.Root) == "" {
+		return daemonResponse{Error: fmt.Sprintf("%s is not beneath a root the daemon indexes (%s)", request.Root, strings.Join(ix.roots, ", "))}
+	}
+	opts := tree.Options{
+		Exclude:         request.Exclude,
+		Include:         request.Include,
+		MaxDepth:        request.MaxDepth,
+		DepthCountsDirs: request.DepthCountsDirs,
+		DirsDepth:       request.DirsDepth,
+		ShowOSFiles:     request.ShowOSFiles,
+		FollowSymlinks:  request.FollowSymlinks,
+	}
+	for _, expr := range request.ExcludeRegexp {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return daemonResponse{Error: err.Error()}
+		}
+		opts.ExcludeRegexp = append(opts.ExcludeRegexp, re)
+	}
+	for _, expr := range request.IncludeRegexp {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return daemonResponse{Error: err.Error()}
+		}
+		opts.IncludeRegexp = append(opts.IncludeRegexp, re)
+	}
+
+	var response daemonResponse
+	visited := make(map[string]fs.DirEntry)
+	walker := tree.NewWalker(opts)
+	walker.ReadDir = ix.readDir
+	walker.OnEntry = func(path string, d fs.DirEntry) {
+		visited[path] = d
+	}
+	walker.OnExclude = func(path, rule string) {
+		response.Excluded = append(response.Excluded, daemonExclusion{Path: path, Rule: rule})
+	}
+	paths, err := walker.Walk(request.Root)
+	if err != nil {
+		return daemonResponse{Error: err.Error()}
+	}
+	response.Paths = paths
+	response.Repeats = walker.Repeats
+
+	// The matches, the directories above them, and any ignore files are all
+	// the query needs the metadata of
+	wanted := make(map[string]bool)
+	for path, d := range visited {
+		if d.Name() == ignoreFileName {
+			wanted[path] = true
+		}
+	}
+	for _, path := range paths {
+		for ; path != request.Root && !wanted[path]; path = filepath.Dir(path) {
+			wanted[path] = true
+		}
+	}
+	for path := range wanted {
+		if d, ok := visited[path]; !ok {
+			continue
+		} else if info, err := d.Info(); err == nil {
+			response.Entries = append(response.Entries, daemonEntry{Path: path, Mode: info.Mode(), Size: info.Size(), ModTime: info.ModTime()})
+		}
+	}
+	return response
+}
+
+func init() {
+	daemonCmd.Flags().StringSliceVarP(&excludePatterns, "exclude", "e", []string{}, "Glob patterns to exclude (e.g., .git, *.log, node_modules)")
+	daemonCmd.Flags().StringArrayVarP(&excludeRegexes, "exclude-regex", "", []string{}, "Regular expression to exclude entries by their path relative to the root (e.g., ^build/, _test\\.go$); repeatable")
+	daemonCmd.Flags().StringVarP(&daemonSocket, "socket", "", "", "Local socket to listen on (default daemon.sock in the user cache directory)")
+	rootCmd.AddCommand(daemonCmd)
+}
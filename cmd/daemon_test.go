@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestDaemonQuery(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"src/main.go", "src/lib/util.go", "node_modules/pkg/index.js", "README.md"} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	index := newDaemonIndex(watcher, processFilters([]string{"node_modules"}, nil))
+	if err := index.add(root, root); err != nil {
+		t.Fatal(err)
+	}
+	index.roots = []string{root}
+	if index.indexed(filepath.Join(root, "node_modules")) {
+		t.Error("an excluded directory was indexed")
+	}
+
+	originalSocket := daemonSocket
+	defer func() { daemonSocket = originalSocket }()
+	daemonSocket = filepath.Join(t.TempDir(), "d.sock")
+	listener, err := listenDaemon(daemonSocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go index.serve(listener)
+
+	query := func(opts tree.Options) ([]string, map[string]fs.FileInfo) {
+		t.Helper()
+		infos := make(map[string]fs.FileInfo)
+		walker := tree.NewWalker(opts)
+		walker.OnEntry = func(path string, d fs.DirEntry) {
+			infos[path], _ = d.Info()
+		}
+		paths, err := queryDaemon(root, walker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rel []string
+		for _, path := range paths {
+			rel = append(rel, filepath.ToSlash(strings.TrimPrefix(path, root+string(filepath.Separator))))
+		}
+		return rel, infos
+	}
+
+	// Excluded directories are still read from disk when a query wants them
+	paths, infos := query(tree.Options{MaxDepth: -1})
+	expected := []string{"README.md", "node_modules", "node_modules/pkg", "node_modules/pkg/index.js", "src", "src/lib", "src/lib/util.go", "src/main.go"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("query() = %v, expected %v", paths, expected)
+	}
+	if info := infos[filepath.Join(root, "src", "main.go")]; info == nil || info.Size() != 4 {
+		t.Errorf("query() gave main.go the metadata %v, expected a size of 4", info)
+	}
+
+	paths, _ = query(tree.Options{MaxDepth: -1, Include: []string{"*.go"}, Exclude: []string{"lib"}})
+	if expected := []string{"src/main.go"}; !slices.Equal(paths, expected) {
+		t.Errorf("query(-i *.go -e lib) = %v, expected %v", paths, expected)
+	}
+
+	// Events bring the index up to date
+	added := filepath.Join(root, "src", "new")
+	if err := os.Mkdir(added, 0755); err != nil {
+		t.Fatal(err)
+	}
+	index.update(fsnotify.Event{Name: added, Op: fsnotify.Create})
+	if err := os.Remove(filepath.Join(root, "README.md")); err != nil {
+		t.Fatal(err)
+	}
+	index.update(fsnotify.Event{Name: filepath.Join(root, "README.md"), Op: fsnotify.Remove})
+	paths, _ = query(tree.Options{MaxDepth: 0})
+	if expected := []string{"node_modules", "src"}; !slices.Equal(paths, expected) {
+		t.Errorf("query() after removing README.md = %v, expected %v", paths, expected)
+	}
+	if !index.indexed(added) {
+		t.Error("a created directory was not indexed")
+	}
+
+	walker := tree.NewWalker(tree.Options{})
+	if _, err := queryDaemon(filepath.Dir(root), walker); err == nil {
+		t.Error("queryDaemon() accepted a path outside the daemon's roots")
+	}
+}
+
+func TestListenDaemon(t *testing.T) {
+	dir := t.TempDir()
+
+	// A file that is not a socket is never replaced
+	precious := filepath.Join(dir, "precious.txt")
+	if err := os.WriteFile(precious, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if listener, err := listenDaemon(precious); err == nil {
+		listener.Close()
+		t.Error("listenDaemon() replaced a regular file")
+	}
+	if data, err := os.ReadFile(precious); err != nil || string(data) != "data" {
+		t.Errorf("the regular file was changed: %q, %v", data, err)
+	}
+
+	// A socket left behind by a daemon that is gone is
+	socket := filepath.Join(dir, "d.sock")
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	listener, err := listenDaemon(socket)
+	if err != nil {
+		t.Fatalf("listenDaemon() over a stale socket error = %v", err)
+	}
+	listener.Close()
+}
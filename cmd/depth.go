@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// depthKeywords are the words --depth accepts in place of a number, unless
+// --depth-keyword redefines them.
+var depthKeywords = map[string]int{
+	"full":       -1,
+	"shallow":    1,
+	"files-only": 0,
+}
+
+var (
+	// depthKeyword is the keyword given to --depth, or "" for a number.
+	depthKeyword string
+	// customDepthKeywords holds the NAME=N definitions of --depth-keyword.
+	customDepthKeywords []string
+	// depthCounts is the --depth-counts setting: all, where files and
+	// directories count alike, or dirs, where the deepest level only shows
+	// directories.
+	depthCounts string
+)
+
+// depthValue is the flag value of --depth: a number of levels, or a keyword
+// that resolveDepth turns into one once the config files are applied.
+type depthValue struct {
+	target *int
+}
+
+func (d depthValue) String() string {
+	return strconv.Itoa(*d.target)
+}
+
+func (d depthValue) Set(value string) error {
+	if n, err := strconv.Atoi(value); err == nil {
+		*d.target, depthKeyword = n, ""
+		return nil
+	}
+	// Keywords defined by --depth-keyword are only known later
+	*d.target, depthKeyword = depthKeywords[value], value
+	return nil
+}
+
+func (d depthValue) Type() string {
+	return "int"
+}
+
+// validateDepthCounts checks the --depth-counts setting.
+func validateDepthCounts() error {
+	switch depthCounts {
+	case "all", "dirs":
+		return nil
+	}
+	return fmt.Errorf("invalid --depth-counts %q (use all or dirs)", depthCounts)
+}
+
+// resolveDepth sets --depth from its keyword, if one was given, looking it
+// up in --depth-keyword before the built-in keywords.
+func resolveDepth() error {
+	if depthKeyword == "" {
+		return nil
+	}
+	for _, definition := range customDepthKeywords {
+		name, value, ok := strings.Cut(definition, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || err != nil || n < -1 {
+			return fmt.Errorf("invalid --depth-keyword %q (use NAME=N, e.g. shallow=2)", definition)
+		}
+		if strings.TrimSpace(name) == depthKeyword {
+			maxDepth = n
+			return nil
+		}
+	}
+	if n, ok := depthKeywords[depthKeyword]; ok {
+		maxDepth = n
+		return nil
+	}
+	return fmt.Errorf("invalid --depth %q (use a number, full, shallow, files-only, or a keyword defined with --depth-keyword)", depthKeyword)
+}
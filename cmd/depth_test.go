@@ -0,0 +1,51 @@
+package cmd
+
+import "testing"
+
+func TestDepthKeywords(t *testing.T) {
+	originalDepth := maxDepth
+	defer func() {
+		maxDepth, depthKeyword, customDepthKeywords = originalDepth, "", []string{}
+	}()
+
+	tests := []struct {
+		value    string
+		custom   []string
+		expected int
+	}{
+		{"3", nil, 3},
+		{"full", nil, -1},
+		{"shallow", nil, 1},
+		{"files-only", nil, 0},
+		// Keywords from --depth-keyword override the built-in ones
+		{"shallow", []string{"shallow=2"}, 2},
+		{"deep", []string{"deep = 6"}, 6},
+	}
+	for _, tt := range tests {
+		customDepthKeywords = tt.custom
+		if err := (depthValue{&maxDepth}).Set(tt.value); err != nil {
+			t.Fatalf("Set(%q) error = %v", tt.value, err)
+		}
+		if err := resolveDepth(); err != nil || maxDepth != tt.expected {
+			t.Errorf("--depth %s with %v = %d, %v, expected %d", tt.value, tt.custom, maxDepth, err, tt.expected)
+		}
+	}
+
+	for _, custom := range [][]string{nil, {"deep"}, {"deep=many"}} {
+		customDepthKeywords = custom
+		(depthValue{&maxDepth}).Set("deep")
+		if err := resolveDepth(); err == nil {
+			t.Errorf("resolveDepth() accepted --depth deep with %v", custom)
+		}
+	}
+}
+
+func TestValidateDepthCounts(t *testing.T) {
+	defer func() { depthCounts = "all" }()
+	for value, valid := range map[string]bool{"all": true, "dirs": true, "files": false, "": false} {
+		depthCounts = value
+		if err := validateDepthCounts(); (err == nil) != valid {
+			t.Errorf("validateDepthCounts() with %q = %v, expected valid %v", value, err, valid)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// describeWidth is the longest description shown by --describe, in runes.
+const describeWidth = 60
+
+// describeAnnotation shows the first heading of a directory's README, or its
+// first line of text if it has no heading, as "# description". Directories
+// without a README, and files, are not annotated.
+func describeAnnotation(path string) string {
+	readme := findReadme(path)
+	if readme == "" {
+		return ""
+	}
+	description := readmeDescription(readme)
+	if description == "" {
+		return ""
+	}
+	if utf8.RuneCountInString(description) > describeWidth {
+		description = string([]rune(description)[:describeWidth-1]) + "…"
+	}
+	return "# " + description
+}
+
+// findReadme returns the README in a directory, preferring README.md when
+// there are several, or "" if there is none.
+func findReadme(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	found := ""
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(strings.ToUpper(name), "README") {
+			continue
+		}
+		if strings.EqualFold(name, "README.md") {
+			return filepath.Join(dir, name)
+		}
+		if found == "" {
+			found = filepath.Join(dir, name)
+		}
+	}
+	return found
+}
+
+// readmeDescription returns the first line of text in a README, which is
+// usually its title, without Markdown heading marks. Badges, images, HTML,
+// front matter, and reStructuredText underlines are skipped.
+func readmeDescription(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inFrontMatter := false
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case lineNum == 1 && line == "---":
+			inFrontMatter = true
+		case inFrontMatter:
+			inFrontMatter = line != "---"
+		case line == "" || strings.Trim(line, "=-~^*#") == "":
+		case strings.HasPrefix(line, "<") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "!["):
+		default:
+			return strings.TrimSpace(strings.Trim(line, "#"))
+		}
+	}
+	return ""
+}
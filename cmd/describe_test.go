@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDescribeAnnotation(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"api/README.md":    "[![CI](badge.svg)](ci)\n\n# Public HTTP API #\n\nServes requests.\n",
+		"docs/readme.rst":  "=====\nGuides\n=====\n",
+		"front/README.md":  "---\ntitle: ignored\n---\n<p align=\"center\"><img src=\"logo.png\"></p>\nReact front end\n",
+		"long/README":      strings.Repeat("word ", 20),
+		"empty/README.md":  "\n\n",
+		"noreadme/main.go": "package main\n",
+		"both/README.md":   "# Markdown\n",
+		"both/README.txt":  "Text\n",
+	}
+	for file, content := range files {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		dir      string
+		expected string
+	}{
+		{"api", "# Public HTTP API"},
+		{"docs", "# Guides"},
+		{"front", "# React front end"},
+		{"long", "# " + strings.Repeat("word ", 11) + "word…"},
+		{"empty", ""},
+		{"noreadme", ""},
+		{"both", "# Markdown"},
+		{"api/README.md", ""},
+	}
+	for _, tt := range tests {
+		if annotation := describeAnnotation(filepath.Join(tempDir, tt.dir)); annotation != tt.expected {
+			t.Errorf("describeAnnotation(%s) = %q, expected %q", tt.dir, annotation, tt.expected)
+		}
+	}
+}
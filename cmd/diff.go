@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// diffHashes makes diff compare file contents by SHA-256 as well as by size.
+var diffHashes bool
+
+var diffCmd = &cobra.Command{
+	Use:   "diff PATH_A PATH_B",
+	Short: "Compare two trees, or a tree and a snapshot.",
+	Long: `Compare two directory trees and print the entries added in PATH_B with +,
+those removed from PATH_A with -, and those changed with ~, in tree layout.
+Only changed entries and the directories above them are shown, and the
+contents of an added or removed directory are counted rather than listed.
+
+Either path can instead be a snapshot file saved with "wintree snapshot save",
+to compare a tree against how it used to be. Entries are changed when their
+type, size, permissions, or extended attributes differ; modification times
+are not compared, since copies of a tree rarely keep them. Use --hash to
+compare file contents as well, which snapshots must have recorded with --hash.
+
+The command exits with a non-zero status when differences are found, for use
+in CI.
+
+  wintree diff build/previous build/current
+  wintree diff release.json ./dist --hash`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("depth") {
+			maxDepth = -1
+		}
+
+		// Hashes are only worth computing if every snapshot recorded them
+		hashes := diffHashes
+		sides := make([]*snapshot, 2)
+		for i, arg := range args {
+			if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+				snap, err := loadSnapshot(arg)
+				if err != nil {
+					return err
+				}
+				sides[i] = snap
+				hashes = hashes && snap.Hashes
+			}
+		}
+		for i, arg := range args {
+			if sides[i] != nil {
+				continue
+			}
+			startPath, err := resolveStartPath([]string{arg})
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(startPath); err != nil {
+				return fmt.Errorf("cannot compare %s: %w", arg, err)
+			}
+			if sides[i], err = takeSnapshot(startPath, processFilters(excludePatterns, includePatterns), hashes, false); err != nil {
+				return err
+			}
+		}
+
+		var changes []entryChange
+		for _, change := range filterChanges(compareSnapshots(sides[0], sides[1])) {
+			if change.Kind != changeMtime {
+				changes = append(changes, change)
+			}
+		}
+
+		fmt.Printf("--- %s\n+++ %s\n", args[0], args[1])
+		if len(changes) == 0 {
+			fmt.Println("No differences found.")
+			return nil
+		}
+		fmt.Print(formatDiffTree(filepath.Base(sides[1].Root), sides[0], sides[1], changes))
+		return fmt.Errorf("%d differences found", len(changes))
+	},
+}
+
+// diffMark is how a single path is shown in a diff tree.
+type diffMark struct {
+	marker  string
+	details []string
+	// hidden counts the entries inside an added or removed directory
+	hidden int
+}
+
+// formatDiffTree draws the changed paths and the directories above them as a
+// tree under label, each line led by its +, -, or ~ marker, and ends with a
+// count of each kind of change.
+func formatDiffTree(label string, old, cur *snapshot, changes []entryChange) string {
+	types := make(map[string]string, len(old.Entries)+len(cur.Entries))
+	for _, entry := range old.Entries {
+		types[entry.Path] = entry.Type
+	}
+	for _, entry := range cur.Entries {
+		types[entry.Path] = entry.Type
+	}
+
+	marks := make(map[string]*diffMark)
+	var added, removed, changed int
+	for _, change := range changes {
+		mark := marks[change.Path]
+		if mark == nil {
+			mark = &diffMark{marker: "~"}
+			marks[change.Path] = mark
+			switch change.Kind {
+			case changeAdded:
+				mark.marker = "+"
+				added++
+			case changeRemoved:
+				mark.marker = "-"
+				removed++
+			default:
+				changed++
+			}
+		}
+		if change.Detail != "" {
+			mark.details = append(mark.details, change.Detail)
+		}
+	}
+
+	// The contents of added and removed directories are counted, not listed
+	var changedPaths, paths []string
+	for path := range marks {
+		changedPaths = append(changedPaths, path)
+	}
+	for _, path := range changedPaths {
+		if dir := addedOrRemovedAncestor(path, marks); dir != "" {
+			marks[dir].hidden++
+			continue
+		}
+		paths = append(paths, path)
+		for dir := pathDir(path); dir != "."; dir = pathDir(dir) {
+			if _, ok := marks[dir]; !ok {
+				marks[dir] = &diffMark{marker: " "}
+				paths = append(paths, dir)
+			}
+		}
+	}
+
+	// Sorting by components keeps every directory just before its entries
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.ReplaceAll(paths[i], "/", "\x00") < strings.ReplaceAll(paths[j], "/", "\x00")
+	})
+	entries := make([]treeEntry, len(paths))
+	for i, path := range paths {
+		entries[i] = treeEntry{relPath: path, isDir: types[path] == "dir"}
+	}
+
+	var output strings.Builder
+	for i, row := range treeRows(entriesTree(label, entries)) {
+		if i == 0 {
+			output.WriteString("  " + row.text() + "\n")
+			continue
+		}
+		mark := marks[paths[i-1]]
+		line := mark.marker + " " + row.text()
+		details := mark.details
+		if mark.hidden > 0 {
+			details = append(details, plural(mark.hidden, "entry", "entries")+" inside")
+		}
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		output.WriteString(line + "\n")
+	}
+
+	fmt.Fprintf(&output, "\n%d added, %d removed, %d changed\n", added, removed, changed)
+	return output.String()
+}
+
+// addedOrRemovedAncestor returns the outermost directory above path that was
+// added or removed as a whole, or "" if there is none.
+func addedOrRemovedAncestor(path string, marks map[string]*diffMark) string {
+	outermost := ""
+	for dir := pathDir(path); dir != "."; dir = pathDir(dir) {
+		if mark, ok := marks[dir]; ok && (mark.marker == "+" || mark.marker == "-") {
+			outermost = dir
+		}
+	}
+	return outermost
+}
+
+func init() {
+	addFilterFlags(diffCmd.Flags())
+	diffCmd.Flags().BoolVarP(&diffHashes, "hash", "", false, "Compare file contents by SHA-256 as well as by size")
+	diffCmd.Flags().StringSliceVarP(&diffIgnorePatterns, "diff-ignore", "", []string{}, "Glob patterns for paths to leave out of the report (e.g., *.log, tmp)")
+	diffCmd.Flags().BoolVarP(&ignoreMode, "ignore-mode", "", false, "Do not report permission drift")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import "testing"
+
+func TestFormatDiffTree(t *testing.T) {
+	old := &snapshot{Entries: []snapshotEntry{
+		{Path: "keep", Type: "file", Mode: "-rw-r--r--"},
+		{Path: "src", Type: "dir"},
+		{Path: "src/main.go", Type: "file"},
+		{Path: "src/old", Type: "dir"},
+		{Path: "src/old/a.go", Type: "file"},
+	}}
+	cur := &snapshot{Entries: []snapshotEntry{
+		{Path: "keep", Type: "file", Mode: "-rwxr-xr-x"},
+		{Path: "src", Type: "dir"},
+		{Path: "src/main.go", Type: "file"},
+		{Path: "src/new", Type: "dir"},
+		{Path: "src/new/deep", Type: "dir"},
+		{Path: "src/new/deep/b.go", Type: "file"},
+	}}
+	changes := []entryChange{
+		{Path: "src/new", Kind: changeAdded},
+		{Path: "src/new/deep", Kind: changeAdded},
+		{Path: "src/new/deep/b.go", Kind: changeAdded},
+		{Path: "src/old", Kind: changeRemoved},
+		{Path: "src/old/a.go", Kind: changeRemoved},
+		{Path: "keep", Kind: changeMode, Detail: "-rw-r--r-- -> -rwxr-xr-x"},
+	}
+
+	// Unchanged entries are left out, and added or removed directories are not expanded
+	expected := `  project
+~ ├── keep (-rw-r--r-- -> -rwxr-xr-x)
+  └── src
++     ├── new (2 entries inside)
+-     └── old (1 entry inside)
+
+3 added, 2 removed, 1 changed
+`
+	if output := formatDiffTree("project", old, cur, changes); output != expected {
+		t.Errorf("formatDiffTree() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
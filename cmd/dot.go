@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/pflag"
+)
+
+var (
+	// dotRankdir is the direction Graphviz lays out --format dot graphs in.
+	dotRankdir string
+	// dotDirShape and dotFileShape are the Graphviz node shapes of
+	// directories and files.
+	dotDirShape  string
+	dotFileShape string
+)
+
+// dotRenderer writes the tree as a Graphviz DOT digraph, with an edge from
+// every directory to each of its entries. Nodes carry their tree.NodeID as
+// their id, which Graphviz keeps as the element id in SVG output.
+type dotRenderer struct{}
+
+func (dotRenderer) render(root *tree.Node) (string, error) {
+	var output strings.Builder
+	output.WriteString("digraph " + strconv.Quote(root.Name) + " {\n")
+	output.WriteString("  rankdir=" + strings.ToUpper(dotRankdir) + ";\n")
+	output.WriteString("  node [shape=" + strconv.Quote(dotFileShape) + "];\n")
+
+	// Nodes are numbered, as names repeat across directories
+	next := 0
+	var describe func(node *tree.Node, relPath, parentID string)
+	describe = func(node *tree.Node, relPath, parentID string) {
+		id := "n" + strconv.Itoa(next)
+		next++
+		attrs := "label=" + strconv.Quote(node.Name) + ", id=" + strconv.Quote(tree.NodeID(relPath))
+		if node.IsDir {
+			attrs += ", shape=" + strconv.Quote(dotDirShape)
+		}
+		output.WriteString("  " + id + " [" + attrs + "];\n")
+		if parentID != "" {
+			output.WriteString("  " + parentID + " -> " + id + ";\n")
+		}
+		for _, child := range node.Children {
+			describe(child, tree.ChildPath(relPath, child), id)
+		}
+	}
+	describe(root, ".", "")
+
+	output.WriteString("}\n")
+	return output.String(), nil
+}
+
+// validateDotFlags checks the --dot-rankdir setting.
+func validateDotFlags() error {
+	switch strings.ToUpper(dotRankdir) {
+	case "TB", "LR", "BT", "RL":
+		return nil
+	}
+	return fmt.Errorf("invalid --dot-rankdir %q (use TB, LR, BT, or RL)", dotRankdir)
+}
+
+// addDotFlags adds the flags that style --format dot output.
+func addDotFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&dotRankdir, "dot-rankdir", "", "LR", "Direction of --format dot graphs: TB (top to bottom), LR (left to right), BT, or RL")
+	flags.StringVarP(&dotDirShape, "dot-dir-shape", "", "folder", "Graphviz node shape of directories in --format dot output (e.g. folder, box, ellipse)")
+	flags.StringVarP(&dotFileShape, "dot-file-shape", "", "note", "Graphviz node shape of files in --format dot output (e.g. note, plaintext, box)")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestDotRenderer(t *testing.T) {
+	root := &tree.Node{Name: "project", IsDir: true, Children: []*tree.Node{
+		{Name: "src", IsDir: true, Children: []*tree.Node{{Name: "main.go"}}},
+		{Name: `say "hi".txt`},
+	}}
+
+	originalRankdir, originalDirShape, originalFileShape := dotRankdir, dotDirShape, dotFileShape
+	defer func() { dotRankdir, dotDirShape, dotFileShape = originalRankdir, originalDirShape, originalFileShape }()
+	dotRankdir, dotDirShape, dotFileShape = "tb", "folder", "note"
+
+	// Names are quoted, nodes carry their IDs, and each node is declared before its edge
+	expected := `digraph "project" {
+  rankdir=TB;
+  node [shape="note"];
+  n0 [label="project", id="cdb4ee2aea69", shape="folder"];
+  n1 [label="src", id="25a6634263c1", shape="folder"];
+  n0 -> n1;
+  n2 [label="main.go", id="9e185f29fa35"];
+  n1 -> n2;
+  n3 [label="say \"hi\".txt", id="fef2c86b5a89"];
+  n0 -> n3;
+}
+`
+	if output, _ := (dotRenderer{}).render(root); output != expected {
+		t.Errorf("render() =\n%s\nexpected:\n%s", output, expected)
+	}
+
+	for _, rankdir := range []string{"LR", "bt", "RL"} {
+		dotRankdir = rankdir
+		if err := validateDotFlags(); err != nil {
+			t.Errorf("validateDotFlags() with %q = %v", rankdir, err)
+		}
+	}
+	dotRankdir = "up"
+	if err := validateDotFlags(); err == nil {
+		t.Error("validateDotFlags() accepted an invalid --dot-rankdir")
+	}
+}
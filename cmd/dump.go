@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump [path]",
+	Short: "Print the tree followed by the contents of every file, for LLM prompts.",
+	Long: `Print the tree followed by the contents of every matched file, each under a
+"=== path ===" header, ready to paste into an LLM prompt. This is the same as
+"wintree --contents", except that the whole tree is dumped unless --depth is
+given.
+
+Files larger than --max-file-size and binary files are listed with their size
+but their contents are left out, as are lockfiles and minified bundles unless
+--include-noise is given.
+
+  wintree dump -i "*.go" --copy
+  wintree dump src -e testdata --max-file-size 64K -o prompt.txt`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contentsDump = true
+		startPath, matchingFiles, err := findAuditPaths(cmd, args)
+		if err != nil {
+			return err
+		}
+		if len(matchingFiles) == 0 {
+			return fmt.Errorf("no files found in %s", startPath)
+		}
+
+		output, err := renderOutput(startPath, matchingFiles)
+		if err != nil {
+			return err
+		}
+		return writeOutput(output)
+	},
+}
+
+func init() {
+	addFilterFlags(dumpCmd.Flags())
+	addOutputFlags(dumpCmd.Flags())
+	addContentsFlags(dumpCmd.Flags())
+	// Contents are always dumped
+	dumpCmd.Flags().MarkHidden("contents")
+	rootCmd.AddCommand(dumpCmd)
+}
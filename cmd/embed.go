@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// embedMarker names the markers of the blocks that embed rewrites.
+	embedMarker string
+	// embedList embeds a bullet list instead of a fenced text tree.
+	embedList bool
+)
+
+var embedCmd = &cobra.Command{
+	Use:   "embed <file> [path]",
+	Short: "Keep a tree in a README or other document up to date.",
+	Long: `Rewrite every block between a pair of markers in a Markdown file with a
+freshly rendered tree, so a structure section can be kept up to date with one
+command, for example from a pre-commit hook:
+
+  <!-- wintree:start -->
+  (replaced with the tree)
+  <!-- wintree:end -->
+
+The tree is of the directory holding the file unless a path is given, and is
+written as --format markdown would write it, or as --format markdown-list
+would with --list. The full path is left out unless --full-path is given, as
+it would differ between machines. Use --marker to give a document's blocks
+another name, so that several blocks can show different trees.
+
+  wintree embed README.md -d 2 -e node_modules
+  wintree embed docs/layout.md . --marker layout --list`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		file := args[0]
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		startPath := filepath.Dir(file)
+		if len(args) > 1 {
+			startPath = args[1]
+		}
+		if startPath, err = filepath.Abs(startPath); err != nil {
+			return fmt.Errorf("invalid starting path: %w", err)
+		}
+
+		// A path from the author's machine would change with every run elsewhere
+		if !cmd.Flags().Changed("full-path") {
+			showFullPath = false
+		}
+		if !cmd.Flags().Changed("relative-root-label") && relativeRootLabel == "full" {
+			relativeRootLabel = ""
+		}
+		if useSmartDefaults {
+			applySmartDefaults(startPath)
+		}
+
+		matchingFiles, err := findMatchingFiles(startPath, processFilters(excludePatterns, includePatterns))
+		if err != nil {
+			return fmt.Errorf("error finding files: %w", err)
+		}
+		block, err := markdownRenderer{list: embedList}.render(buildTree(startPath, matchingFiles))
+		if err != nil {
+			return err
+		}
+
+		text, blocks, err := embedBlock(string(data), embedMarker, block)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if text == string(data) {
+			fmt.Printf("%s is up to date\n", file)
+			return nil
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, []byte(text), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		fmt.Printf("Updated %s in %s\n", plural(blocks, "block", "blocks"), file)
+		return nil
+	},
+}
+
+// embedBlock replaces the lines between each <!-- marker:start --> and
+// <!-- marker:end --> comment in text with block, returning the new text and
+// the number of blocks replaced. The marker lines themselves are kept, and
+// block takes on the line endings of text.
+func embedBlock(text, marker, block string) (string, int, error) {
+	start := "<!-- " + marker + ":start -->"
+	end := "<!-- " + marker + ":end -->"
+	if strings.Contains(text, "\r\n") {
+		block = strings.ReplaceAll(block, "\n", "\r\n")
+	}
+
+	var output strings.Builder
+	blocks := 0
+	rest := text
+	for {
+		i := strings.Index(rest, start)
+		if i < 0 {
+			break
+		}
+		if strings.Contains(rest[:i], end) {
+			return "", 0, fmt.Errorf("%s has no matching %s", end, start)
+		}
+		// The block starts on the line after the start marker
+		newline := strings.IndexByte(rest[i:], '\n')
+		if newline < 0 {
+			return "", 0, fmt.Errorf("%s has no matching %s", start, end)
+		}
+		if strings.Contains(rest[i:i+newline], end) {
+			return "", 0, fmt.Errorf("%s and %s must be on separate lines", start, end)
+		}
+		contentStart := i + newline + 1
+
+		j := strings.Index(rest[contentStart:], end)
+		if j < 0 {
+			return "", 0, fmt.Errorf("%s has no matching %s", start, end)
+		}
+		contentEnd := contentStart + j
+		if strings.Contains(rest[contentStart:contentEnd], start) {
+			return "", 0, fmt.Errorf("%s has no matching %s", start, end)
+		}
+		// and ends before the line holding the end marker
+		lineStart := strings.LastIndexByte(rest[:contentEnd], '\n') + 1
+
+		output.WriteString(rest[:contentStart])
+		output.WriteString(block)
+		output.WriteString(rest[lineStart : contentEnd+len(end)])
+		rest = rest[contentEnd+len(end):]
+		blocks++
+	}
+
+	if blocks == 0 {
+		return "", 0, fmt.Errorf("no %s marker found", start)
+	}
+	if strings.Contains(rest, end) {
+		return "", 0, fmt.Errorf("%s has no matching %s", end, start)
+	}
+	output.WriteString(rest)
+	return output.String(), blocks, nil
+}
+
+func init() {
+	addFilterFlags(embedCmd.Flags())
+	embedCmd.Flags().StringVarP(&embedMarker, "marker", "", "wintree", "Name of the markers around the blocks to rewrite, as in <!-- name:start -->")
+	embedCmd.Flags().BoolVarP(&embedList, "list", "", false, "Embed the tree as a nested bullet list instead of a code block")
+	rootCmd.AddCommand(embedCmd)
+}
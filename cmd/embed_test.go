@@ -0,0 +1,64 @@
+package cmd
+
+import "testing"
+
+func TestEmbedBlock(t *testing.T) {
+	block := "```text\nproject\n└── main.go\n```\n"
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		blocks   int
+	}{
+		{
+			name:     "empty block",
+			text:     "# Project\n<!-- wintree:start -->\n<!-- wintree:end -->\nMore\n",
+			expected: "# Project\n<!-- wintree:start -->\n" + block + "<!-- wintree:end -->\nMore\n",
+			blocks:   1,
+		},
+		{
+			name:     "stale block",
+			text:     "<!-- wintree:start -->\nold\ntree\n  <!-- wintree:end -->",
+			expected: "<!-- wintree:start -->\n" + block + "  <!-- wintree:end -->",
+			blocks:   1,
+		},
+		{
+			name:     "several blocks",
+			text:     "<!-- wintree:start -->\n<!-- wintree:end -->\ntext\n<!-- wintree:start -->\nold\n<!-- wintree:end -->\n",
+			expected: "<!-- wintree:start -->\n" + block + "<!-- wintree:end -->\ntext\n<!-- wintree:start -->\n" + block + "<!-- wintree:end -->\n",
+			blocks:   2,
+		},
+		{
+			name:     "CRLF line endings",
+			text:     "<!-- wintree:start -->\r\nold\r\n<!-- wintree:end -->\r\n",
+			expected: "<!-- wintree:start -->\r\n```text\r\nproject\r\n└── main.go\r\n```\r\n<!-- wintree:end -->\r\n",
+			blocks:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, blocks, err := embedBlock(tt.text, "wintree", block)
+			if err != nil {
+				t.Fatalf("embedBlock() error = %v", err)
+			}
+			if text != tt.expected || blocks != tt.blocks {
+				t.Errorf("embedBlock() = %q, %d, expected %q, %d", text, blocks, tt.expected, tt.blocks)
+			}
+		})
+	}
+
+	for _, invalid := range []string{
+		"no markers\n",
+		"<!-- layout:start -->\n<!-- layout:end -->\n",
+		"<!-- wintree:start -->\nunterminated\n",
+		"<!-- wintree:end -->\n<!-- wintree:start -->\n<!-- wintree:end -->\n",
+		"<!-- wintree:start -->\n<!-- wintree:start -->\n<!-- wintree:end -->\n",
+		"<!-- wintree:start --><!-- wintree:end -->\n",
+	} {
+		if _, _, err := embedBlock(invalid, "wintree", block); err == nil {
+			t.Errorf("embedBlock(%q) expected an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// The exit codes of wintree, so scripts can tell failures apart.
+const (
+	exitOK          = 0
+	exitError       = 1 // the tree could not be read, or a check failed
+	exitUsage       = 2 // a flag or argument is invalid
+	exitNoMatches   = 3 // --include or --include-regex matched no files
+	exitInterrupted = 130
+)
+
+// errNoMatches is returned when include mode matches no files, after saying
+// so, unless --quiet.
+var errNoMatches = errors.New("no files found matching the given patterns")
+
+// quiet is the --quiet flag, which leaves out informational messages such as
+// the smart-defaults banner, keeping only the output and errors.
+var quiet bool
+
+// flagError is an error in the flags or arguments a command was given, as
+// opposed to one met while running it.
+type flagError struct {
+	err error
+}
+
+func (e flagError) Error() string { return e.err.Error() }
+func (e flagError) Unwrap() error { return e.err }
+
+// flagErrorf formats a flagError.
+func flagErrorf(format string, a ...any) error {
+	return flagError{fmt.Errorf(format, a...)}
+}
+
+// asFlagError marks err, if any, as a flagError.
+func asFlagError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return flagError{err}
+}
+
+// exitCode returns the code to exit with after a command returned err.
+func exitCode(err error) int {
+	var invalid flagError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errInterrupted):
+		return exitInterrupted
+	case errors.Is(err, errNoMatches):
+		return exitNoMatches
+	case errors.As(err, &invalid):
+		return exitUsage
+	}
+	return exitError
+}
+
+// markFlagErrors makes the flag parsing and argument checks of cmd and every
+// command beneath it return flagErrors.
+func markFlagErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return flagError{err}
+	})
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(cmd *cobra.Command, a []string) error {
+			return asFlagError(args(cmd, a))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markFlagErrors(sub)
+	}
+}
+
+// reportNoMatches says that include mode matched no files, unless --quiet,
+// and returns errNoMatches for the exit code.
+func reportNoMatches(cmd *cobra.Command) error {
+	if !quiet {
+		fmt.Println("No files found matching the given patterns.")
+	}
+	// The message above already says why, in the words users know
+	cmd.SilenceUsage, cmd.SilenceErrors = true, true
+	return errNoMatches
+}
+
+// info prints an informational message, unless --quiet.
+func info(format string, a ...any) {
+	if !quiet {
+		fmt.Printf(format+"\n", a...)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Leave out informational messages such as the smart-defaults banner and the no-matches notice")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, exitOK},
+		{errors.New("permission denied"), exitError},
+		{flagErrorf("invalid --sort %q", "color"), exitUsage},
+		{fmt.Errorf("error finding files: %w", asFlagError(errors.New("invalid --jobs -1"))), exitUsage},
+		{errNoMatches, exitNoMatches},
+		{errInterrupted, exitInterrupted},
+	}
+	for _, tt := range tests {
+		if code := exitCode(tt.err); code != tt.expected {
+			t.Errorf("exitCode(%v) = %d, expected %d", tt.err, code, tt.expected)
+		}
+	}
+	if asFlagError(nil) != nil {
+		t.Error("asFlagError(nil) returned an error")
+	}
+}
+
+func TestMarkFlagErrors(t *testing.T) {
+	parent := &cobra.Command{Use: "parent"}
+	child := &cobra.Command{Use: "child", Args: cobra.NoArgs, RunE: func(*cobra.Command, []string) error { return nil }}
+	child.Flags().Int("count", 0, "")
+	parent.AddCommand(child)
+	markFlagErrors(parent)
+
+	for _, args := range [][]string{{"child", "extra"}, {"child", "--count", "many"}, {"child", "--unknown"}} {
+		parent.SetArgs(args)
+		parent.SilenceErrors, parent.SilenceUsage = true, true
+		if err := parent.Execute(); exitCode(err) != exitUsage {
+			t.Errorf("%v: err = %v, exit code %d, expected %d", args, err, exitCode(err), exitUsage)
+		}
+	}
+}
+
+func TestSubcommandFlagErrors(t *testing.T) {
+	defer func(format, out string, resume bool, runs int, rules []string) {
+		watchFormat, hashOutput, hashResume, benchRuns, nameRuleSpecs = format, out, resume, runs, rules
+	}(watchFormat, hashOutput, hashResume, benchRuns, nameRuleSpecs)
+	defer func(format, output string) { parseFormat, outputFormat = format, output }(parseFormat, outputFormat)
+	watchFormat, parseFormat, outputFormat = "xml", "xml", "xml"
+	hashOutput, hashResume = "", true
+	benchRuns = 0
+	nameRuleSpecs = nil
+
+	for _, cmd := range []*cobra.Command{watchCmd, hashCmd, parseCmd, benchCmd, lintNamesCmd, queryCmd} {
+		if err := cmd.RunE(cmd, nil); exitCode(err) != exitUsage {
+			t.Errorf("%s: err = %v, exit code %d, expected %d", cmd.Name(), err, exitCode(err), exitUsage)
+		}
+	}
+}
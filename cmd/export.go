@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// exportView narrows paths down to what "git archive" would include: files
+// tracked by git that are not marked export-ignore in .gitattributes, either
+// directly or through one of their parent directories. Directories are kept
+// if any file beneath them is.
+func exportView(root string, paths []string) ([]string, error) {
+	tracked, err := gitTrackedFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check every path along with its parent directories, since a directory
+	// marked export-ignore drops everything beneath it
+	relPaths := make(map[string]string, len(paths))
+	var queries []string
+	queried := make(map[string]bool)
+	for _, path := range paths {
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, err
+		}
+		relPath = filepath.ToSlash(relPath)
+		relPaths[path] = relPath
+		for p := relPath; p != "." && !queried[p]; p = pathDir(p) {
+			queried[p] = true
+			queries = append(queries, p)
+		}
+	}
+
+	ignored, err := gitExportIgnored(root, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	// Directories containing a tracked, exported file
+	exportedDirs := make(map[string]bool)
+	for file := range tracked {
+		if isExportIgnored(file, ignored) {
+			continue
+		}
+		for dir := pathDir(file); dir != "."; dir = pathDir(dir) {
+			exportedDirs[dir] = true
+		}
+	}
+
+	var kept []string
+	for _, path := range paths {
+		relPath := relPaths[path]
+		if isExportIgnored(relPath, ignored) {
+			continue
+		}
+		if tracked[relPath] || exportedDirs[relPath] {
+			kept = append(kept, path)
+		}
+	}
+	return kept, nil
+}
+
+// isExportIgnored reports whether a slash-separated path or any of its parent
+// directories is marked export-ignore.
+func isExportIgnored(relPath string, ignored map[string]bool) bool {
+	for p := relPath; p != "."; p = pathDir(p) {
+		if ignored[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// pathDir returns the parent of a slash-separated relative path, or "." at
+// the top.
+func pathDir(relPath string) string {
+	if i := strings.LastIndex(relPath, "/"); i >= 0 {
+		return relPath[:i]
+	}
+	return "."
+}
+
+// gitTrackedFiles returns the files git tracks under root, relative to it.
+func gitTrackedFiles(root string) (map[string]bool, error) {
+	cmd := exec.Command("git", "ls-files", "-z")
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	tracked := make(map[string]bool)
+	for _, file := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		if file != "" {
+			tracked[file] = true
+		}
+	}
+	return tracked, nil
+}
+
+// gitExportIgnored asks git which of the slash-separated relPaths have the
+// export-ignore attribute set.
+func gitExportIgnored(root string, relPaths []string) (map[string]bool, error) {
+	var input bytes.Buffer
+	for _, relPath := range relPaths {
+		input.WriteString(relPath)
+		input.WriteByte(0)
+	}
+
+	cmd := exec.Command("git", "check-attr", "-z", "--stdin", "export-ignore")
+	cmd.Dir = root
+	cmd.Stdin = &input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git check-attr failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	// -z output is three fields per path: path, attribute, value
+	ignored := make(map[string]bool)
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "set" {
+			ignored[fields[i]] = true
+		}
+	}
+	return ignored, nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestExportView(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	tempDir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", tempDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	structure := map[string]string{
+		".gitattributes":   "/tests export-ignore\n*.md export-ignore\n",
+		"main.go":          "package main",
+		"README.md":        "# Readme",
+		"tests/main_test":  "test",
+		"src/app.go":       "package src",
+		"src/notes.md":     "notes",
+		"untracked/new.go": "package untracked",
+	}
+	for file, content := range structure {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add := exec.Command("git", "add", ".gitattributes", "main.go", "README.md", "tests", "src")
+	add.Dir = tempDir
+	if out, err := add.CombinedOutput(); err != nil {
+		t.Fatalf("git add failed: %v: %s", err, out)
+	}
+
+	var paths []string
+	for _, relPath := range []string{".gitattributes", "main.go", "README.md", "tests", "tests/main_test",
+		"src", "src/app.go", "src/notes.md", "untracked", "untracked/new.go"} {
+		paths = append(paths, filepath.Join(tempDir, filepath.FromSlash(relPath)))
+	}
+
+	kept, err := exportView(tempDir, paths)
+	if err != nil {
+		t.Fatalf("exportView() error = %v", err)
+	}
+
+	var relKept []string
+	for _, path := range kept {
+		relPath, _ := filepath.Rel(tempDir, path)
+		relKept = append(relKept, filepath.ToSlash(relPath))
+	}
+	sort.Strings(relKept)
+
+	expected := []string{".gitattributes", "main.go", "src", "src/app.go"}
+	if strings.Join(relKept, ",") != strings.Join(expected, ",") {
+		t.Errorf("exportView() = %v, expected %v", relKept, expected)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build !unix && !windows
+
+package cmd
+
+import "os"
+
+// allocatedSize is unavailable on this platform, so apparent sizes are used.
+func allocatedSize(path string, info os.FileInfo) (int64, bool) {
+	return 0, false
+}
+
+// fileID is unavailable on this platform.
+func fileID(path string) (uint64, bool) {
+	return 0, false
+}
+
+// readACL is unavailable on this platform.
+func readACL(path string) ([]aclEntry, error) {
+	return nil, nil
+}
+
+// fileOwner is unavailable on this platform.
+func fileOwner(path string, info os.FileInfo) (owner, group string) {
+	return "?", "?"
+}
+
+// fileModeString returns the permissions Go reports for a file.
+func fileModeString(info os.FileInfo) string {
+	return unixModeString(info.Mode())
+}
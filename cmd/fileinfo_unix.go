@@ -0,0 +1,76 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// allocatedSize returns the number of bytes allocated to a file on disk, from
+// its block count as du reports it.
+func allocatedSize(path string, info os.FileInfo) (int64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	// st_blocks is always counted in 512-byte units, regardless of the block size
+	return int64(stat.Blocks) * 512, true
+}
+
+// fileID returns the inode number of path, without following symlinks.
+func fileID(path string) (uint64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Ino), true
+}
+
+// readACL returns no entries; Windows ACLs do not apply, and POSIX ACLs are
+// covered by the mode and extended attributes.
+func readACL(path string) ([]aclEntry, error) {
+	return nil, nil
+}
+
+// userNames and groupNames cache ID lookups, which can go over the network
+// with LDAP or NIS.
+var (
+	userNames  = make(map[uint32]string)
+	groupNames = make(map[uint32]string)
+)
+
+// fileOwner returns the names of the user and group owning a file, or their
+// numeric IDs when they have no name.
+func fileOwner(path string, info os.FileInfo) (owner, group string) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "?", "?"
+	}
+
+	uid, gid := uint32(stat.Uid), uint32(stat.Gid)
+	if _, ok := userNames[uid]; !ok {
+		userNames[uid] = strconv.FormatUint(uint64(uid), 10)
+		if u, err := user.LookupId(userNames[uid]); err == nil {
+			userNames[uid] = u.Username
+		}
+	}
+	if _, ok := groupNames[gid]; !ok {
+		groupNames[gid] = strconv.FormatUint(uint64(gid), 10)
+		if g, err := user.LookupGroupId(groupNames[gid]); err == nil {
+			groupNames[gid] = g.Name
+		}
+	}
+	return userNames[uid], groupNames[gid]
+}
+
+// fileModeString returns the permissions of a file as ls -l shows them.
+func fileModeString(info os.FileInfo) string {
+	return unixModeString(info.Mode())
+}
@@ -0,0 +1,198 @@
+//go:build windows
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileStandardInfo mirrors the Win32 FILE_STANDARD_INFO structure.
+type fileStandardInfo struct {
+	AllocationSize int64
+	EndOfFile      int64
+	NumberOfLinks  uint32
+	DeletePending  byte
+	Directory      byte
+}
+
+// openForAttributes opens path for querying its metadata only, without
+// following reparse points such as junctions and symlinks.
+func openForAttributes(path string) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	return windows.CreateFile(name, windows.FILE_READ_ATTRIBUTES,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+}
+
+// allocatedSize returns the number of bytes allocated to a file on disk, which
+// matches the "Size on disk" shown by Explorer for compressed and sparse files.
+func allocatedSize(path string, info os.FileInfo) (int64, bool) {
+	handle, err := openForAttributes(path)
+	if err != nil {
+		return 0, false
+	}
+	defer windows.CloseHandle(handle)
+
+	var standard fileStandardInfo
+	err = windows.GetFileInformationByHandleEx(handle, windows.FileStandardInfo,
+		(*byte)(unsafe.Pointer(&standard)), uint32(unsafe.Sizeof(standard)))
+	if err != nil {
+		return 0, false
+	}
+	return standard.AllocationSize, true
+}
+
+// fileID returns the NTFS file ID of path, which is shared by hard links to
+// the same file.
+func fileID(path string) (uint64, bool) {
+	handle, err := openForAttributes(path)
+	if err != nil {
+		return 0, false
+	}
+	defer windows.CloseHandle(handle)
+
+	var info windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(handle, &info); err != nil {
+		return 0, false
+	}
+	return uint64(info.FileIndexHigh)<<32 | uint64(info.FileIndexLow), true
+}
+
+// aclHeader mirrors the Win32 ACL header, whose fields x/sys keeps unexported.
+type aclHeader struct {
+	AclRevision byte
+	Sbz1        byte
+	AclSize     uint16
+	AceCount    uint16
+	Sbz2        uint16
+}
+
+// aceHeader mirrors the Win32 ACE_HEADER structure. For allow and deny
+// entries it is followed by the access mask and the SID.
+type aceHeader struct {
+	AceType  byte
+	AceFlags byte
+	AceSize  uint16
+}
+
+const (
+	accessAllowedAceType = 0
+	accessDeniedAceType  = 1
+)
+
+// accountNames caches SID lookups, which can go over the network on domain
+// file servers.
+var accountNames = make(map[string]string)
+
+// readACL returns the allow and deny entries in the DACL of path that apply
+// to the entry itself. A missing DACL grants everyone full control.
+func readACL(path string) ([]aclEntry, error) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return nil, err
+	}
+	defer runtime.KeepAlive(sd)
+
+	dacl, _, err := sd.DACL()
+	if errors.Is(err, windows.ERROR_OBJECT_NOT_FOUND) || (err == nil && dacl == nil) {
+		return []aclEntry{{account: "Everyone", mask: accessFullControl}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []aclEntry
+	header := (*aclHeader)(unsafe.Pointer(dacl))
+	ace := unsafe.Add(unsafe.Pointer(dacl), unsafe.Sizeof(aclHeader{}))
+	for i := 0; i < int(header.AceCount); i++ {
+		aceHdr := (*aceHeader)(ace)
+		isEntry := aceHdr.AceType == accessAllowedAceType || aceHdr.AceType == accessDeniedAceType
+		if isEntry && aceHdr.AceFlags&windows.INHERIT_ONLY_ACE == 0 {
+			mask := *(*uint32)(unsafe.Add(ace, unsafe.Sizeof(aceHeader{})))
+			sid := (*windows.SID)(unsafe.Add(ace, unsafe.Sizeof(aceHeader{})+4))
+			entries = append(entries, aclEntry{
+				account: accountName(sid),
+				mask:    mask,
+				deny:    aceHdr.AceType == accessDeniedAceType,
+			})
+		}
+		ace = unsafe.Add(ace, aceHdr.AceSize)
+	}
+
+	return entries, nil
+}
+
+// accountName returns the account name for sid without its domain, or the
+// SID string if it cannot be resolved.
+func accountName(sid *windows.SID) string {
+	key := sid.String()
+	if name, ok := accountNames[key]; ok {
+		return name
+	}
+
+	name := key
+	if account, _, _, err := sid.LookupAccount(""); err == nil {
+		name = account
+	}
+	accountNames[key] = name
+	return name
+}
+
+// fileOwner returns the accounts owning a file and its primary group, as set
+// in its security descriptor.
+func fileOwner(path string, info os.FileInfo) (owner, group string) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.OWNER_SECURITY_INFORMATION|windows.GROUP_SECURITY_INFORMATION)
+	if err != nil {
+		return "?", "?"
+	}
+	defer runtime.KeepAlive(sd)
+
+	owner, group = "?", "?"
+	if sid, _, err := sd.Owner(); err == nil && sid != nil {
+		owner = accountName(sid)
+	}
+	if sid, _, err := sd.Group(); err == nil && sid != nil {
+		group = accountName(sid)
+	}
+	return owner, group
+}
+
+// fileModeString returns the attributes of a file in the form of the Mode
+// column of PowerShell's Get-ChildItem, e.g. d----- or -a-h--, as Windows has
+// no Unix permissions.
+func fileModeString(info os.FileInfo) string {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return unixModeString(info.Mode())
+	}
+
+	flags := []struct {
+		attribute uint32
+		letter    byte
+	}{
+		{windows.FILE_ATTRIBUTE_DIRECTORY, 'd'},
+		{windows.FILE_ATTRIBUTE_ARCHIVE, 'a'},
+		{windows.FILE_ATTRIBUTE_READONLY, 'r'},
+		{windows.FILE_ATTRIBUTE_HIDDEN, 'h'},
+		{windows.FILE_ATTRIBUTE_SYSTEM, 's'},
+		{windows.FILE_ATTRIBUTE_REPARSE_POINT, 'l'},
+	}
+	mode := make([]byte, len(flags))
+	for i, flag := range flags {
+		mode[i] = '-'
+		if data.FileAttributes&flag.attribute != 0 {
+			mode[i] = flag.letter
+		}
+	}
+	return string(mode)
+}
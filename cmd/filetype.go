@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// fileTypes are the --type kinds to keep: f (regular files), d (directories),
+// l (symlinks), and x (executable files). Empty keeps everything.
+var fileTypes []string
+
+// validateFileTypes checks that every --type is a known kind.
+func validateFileTypes() error {
+	for _, kind := range fileTypes {
+		switch kind {
+		case "f", "d", "l", "x":
+		default:
+			return fmt.Errorf("invalid --type %q (use f, d, l, or x)", kind)
+		}
+	}
+	return nil
+}
+
+// filterByType keeps the paths whose kind is one of the --type kinds, the way
+// find -type does. Symlinks are l only, never f or d.
+func filterByType(paths []string) []string {
+	var kept []string
+	for _, path := range paths {
+		info, err := lstatCached(path)
+		if err != nil {
+			continue
+		}
+		for _, kind := range fileTypes {
+			if isFileType(path, info, kind) {
+				kept = append(kept, path)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// isFileType reports whether an entry is of the given --type kind.
+func isFileType(path string, info os.FileInfo, kind string) bool {
+	switch kind {
+	case "f":
+		return info.Mode().IsRegular()
+	case "d":
+		return info.IsDir()
+	case "l":
+		return info.Mode()&os.ModeSymlink != 0
+	case "x":
+		return info.Mode().IsRegular() && isExecutable(path, info)
+	}
+	return false
+}
+
+// isExecutable reports whether a regular file can be run: any execute bit on
+// Unix, or an extension listed in PATHEXT on Windows, which has no such bit.
+func isExecutable(path string, info os.FileInfo) bool {
+	if runtime.GOOS != "windows" {
+		return info.Mode().Perm()&0111 != 0
+	}
+
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".COM;.EXE;.BAT;.CMD"
+	}
+	ext := filepath.Ext(path)
+	for _, executable := range strings.Split(pathExt, ";") {
+		if executable != "" && strings.EqualFold(ext, executable) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	showGitStatus bool
+	gitClean      bool
+)
+
+// gitStates maps the absolute path of every entry that git status reports
+// to its state, such as "modified" or "untracked". Ignored directories are
+// reported as a whole, and stand for everything beneath them. It is only
+// populated by --git-status and --git-clean.
+var gitStates map[string]string
+
+// loadGitStates reads the state of every changed, untracked, and ignored
+// entry beneath root from git status.
+func loadGitStates(root string) error {
+	prefixCmd := exec.Command("git", "rev-parse", "--show-prefix")
+	prefixCmd.Dir = root
+	var stderr bytes.Buffer
+	prefixCmd.Stderr = &stderr
+	prefix, err := prefixCmd.Output()
+	if err != nil {
+		return fmt.Errorf("--git-status and --git-clean require a git repository: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	cmd := exec.Command("git", "status", "--porcelain=v1", "-z", "--ignored=matching", "--untracked-files=all", "--", ".")
+	cmd.Dir = root
+	stderr.Reset()
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git status failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	gitStates = parseGitStatus(root, strings.TrimSpace(string(prefix)), out)
+	return nil
+}
+
+// parseGitStatus maps each entry of git status --porcelain=v1 -z output to
+// its state. Paths are relative to the repository root, so prefix, the path
+// of root within the repository, is removed from each.
+func parseGitStatus(root, prefix string, out []byte) map[string]string {
+	states := make(map[string]string)
+	records := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		x, y, relPath := record[0], record[1], record[3:]
+		if x == 'R' || x == 'C' {
+			// Renames and copies are followed by the original path
+			i++
+		}
+
+		relPath, ok := strings.CutPrefix(relPath, prefix)
+		if !ok {
+			continue
+		}
+		path := filepath.Join(root, filepath.FromSlash(strings.TrimSuffix(relPath, "/")))
+		states[path] = gitState(x, y)
+	}
+	return states
+}
+
+// gitState describes the two-letter status of a porcelain v1 entry.
+func gitState(x, y byte) string {
+	switch {
+	case x == '?':
+		return "untracked"
+	case x == '!':
+		return "ignored"
+	case x == 'U' || y == 'U' || (x == 'A' && y == 'A') || (x == 'D' && y == 'D'):
+		return "conflict"
+	}
+
+	var parts []string
+	switch x {
+	case 'A':
+		parts = append(parts, "added")
+	case 'R':
+		parts = append(parts, "renamed")
+	case 'D':
+		parts = append(parts, "deleted")
+	case 'M', 'T', 'C':
+		parts = append(parts, "staged")
+	}
+	switch y {
+	case 'M', 'T':
+		parts = append(parts, "modified")
+	case 'D':
+		parts = append(parts, "deleted")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// gitStateOf returns the state of path, which is "ignored" beneath an ignored
+// directory, or "" for an unchanged tracked entry.
+func gitStateOf(path string) string {
+	if state, ok := gitStates[path]; ok {
+		return state
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if gitStates[dir] == "ignored" {
+			return "ignored"
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// dropGitIgnored removes the paths git ignores.
+func dropGitIgnored(paths []string) []string {
+	var kept []string
+	for _, path := range paths {
+		if gitStateOf(path) != "ignored" {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// gitStatusAnnotation marks an entry with its git state.
+func gitStatusAnnotation(path string) string {
+	if state := gitStateOf(path); state != "" {
+		return "[" + state + "]"
+	}
+	return ""
+}
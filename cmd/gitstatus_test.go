@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGitStatus(t *testing.T) {
+	root := filepath.FromSlash("/repo/app")
+	out := " M app/main.go\x00" +
+		"MM app/lib/util.go\x00" +
+		"A  app/new.go\x00" +
+		"R  app/renamed.go\x00app/old.go\x00" +
+		"UU app/conflict.go\x00" +
+		"?? app/notes.txt\x00" +
+		"!! app/build/\x00" +
+		" M docs/outside.md\x00"
+
+	states := parseGitStatus(root, "app/", []byte(out))
+	expected := map[string]string{
+		filepath.Join(root, "main.go"):     "modified",
+		filepath.Join(root, "lib/util.go"): "staged, modified",
+		filepath.Join(root, "new.go"):      "added",
+		filepath.Join(root, "renamed.go"):  "renamed",
+		filepath.Join(root, "conflict.go"): "conflict",
+		filepath.Join(root, "notes.txt"):   "untracked",
+		filepath.Join(root, "build"):       "ignored",
+	}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("parseGitStatus() = %v, expected %v", states, expected)
+	}
+
+	originalStates := gitStates
+	defer func() { gitStates = originalStates }()
+	gitStates = states
+
+	paths := []string{
+		filepath.Join(root, "build"),
+		filepath.Join(root, "build", "out", "app.o"),
+		filepath.Join(root, "main.go"),
+		filepath.Join(root, "unchanged.go"),
+	}
+	if kept := dropGitIgnored(paths); !reflect.DeepEqual(kept, paths[2:]) {
+		t.Errorf("dropGitIgnored() = %v, expected %v", kept, paths[2:])
+	}
+	if annotation := gitStatusAnnotation(paths[1]); annotation != "[ignored]" {
+		t.Errorf("gitStatusAnnotation() beneath an ignored directory = %q, expected [ignored]", annotation)
+	}
+	if annotation := gitStatusAnnotation(paths[3]); annotation != "" {
+		t.Errorf("gitStatusAnnotation() of an unchanged file = %q, expected none", annotation)
+	}
+}
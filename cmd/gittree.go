@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/cobra"
+)
+
+// gitTreeEntry is an entry listed by git ls-tree.
+type gitTreeEntry struct {
+	// relPath is slash-separated and relative to the listed tree
+	relPath string
+	// kind is blob, tree, or commit (a submodule)
+	kind string
+}
+
+var gitTreeCmd = &cobra.Command{
+	Use:   "git-tree REF [PATH]",
+	Short: "Show the tree of a git revision without checking it out.",
+	Long: `Render the tree of any commit, branch, or tag straight from the git object
+database of the repository in the current directory, without switching
+branches or checking anything out. PATH is a directory within the repository,
+relative to its root.
+
+  wintree git-tree main
+  wintree git-tree v1.2.0 cmd -d -1
+
+Exclude and include patterns and --depth apply as they do to the working tree.`,
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		ref, path := args[0], ""
+		if len(args) > 1 {
+			path = strings.Trim(filepath.ToSlash(args[1]), "/")
+		}
+
+		entries, err := gitLsTree(".", ref, path)
+		if err != nil {
+			return err
+		}
+
+		label := ref
+		if path != "" {
+			label = ref + ":" + path
+		}
+		if treeLabel != "" {
+			label = treeLabel
+		}
+
+		filters := processFilters(excludePatterns, includePatterns)
+		if filters.err != nil {
+			return filters.err
+		}
+		root := gitTreeNodes(label, entries, treeOptions(filters))
+		return writeOutput(formatTreeRows(treeRows(root)))
+	},
+}
+
+// gitLsTree lists every entry beneath path in the tree of ref, in the
+// repository containing dir.
+func gitLsTree(dir, ref, path string) ([]gitTreeEntry, error) {
+	cmd := exec.Command("git", "ls-tree", "-r", "-t", "-z", ref+":"+path)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	// Each entry is "<mode> <type> <object>\t<path>"
+	var entries []gitTreeEntry
+	for _, record := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		meta, relPath, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) < 2 {
+			continue
+		}
+		entries = append(entries, gitTreeEntry{relPath: relPath, kind: fields[1]})
+	}
+	return entries, nil
+}
+
+// gitTreeNodes arranges the entries that pass the walk options into a tree
+// under a root called label, in the order git lists them. Exclude patterns
+// and OS metadata hide an entry along with everything beneath it.
+func gitTreeNodes(label string, entries []gitTreeEntry, opts tree.Options) *tree.Node {
+	root := &tree.Node{Name: label, IsDir: true}
+	nodes := map[string]*tree.Node{".": root}
+
+	var add func(relPath string, isDir bool) *tree.Node
+	add = func(relPath string, isDir bool) *tree.Node {
+		if node, ok := nodes[relPath]; ok {
+			return node
+		}
+		node := &tree.Node{Name: relPath[strings.LastIndex(relPath, "/")+1:], IsDir: isDir}
+		nodes[relPath] = node
+		parent := add(pathDir(relPath), true)
+		parent.Children = append(parent.Children, node)
+		return node
+	}
+
+	for _, entry := range entries {
+		parts := strings.Split(entry.relPath, "/")
+		depth := len(parts) - 1
+		isDir := entry.kind != "blob"
+
+		if !opts.WithinDepth(depth, isDir) || gitTreeExcluded(parts, opts) {
+			continue
+		}
+		if opts.Including() && (isDir || !gitTreeIncluded(parts, opts)) {
+			continue
+		}
+		add(entry.relPath, isDir)
+	}
+	return root
+}
+
+// gitTreeExcluded reports whether any component of a path is OS metadata or
+// matches an exclude pattern, or the path or one of its directories matches
+// an exclude regex.
+func gitTreeExcluded(parts []string, opts tree.Options) bool {
+	for _, part := range parts {
+		if !opts.ShowOSFiles && tree.IsOSNoise(part) {
+			return true
+		}
+		for _, pattern := range opts.Exclude {
+			if matched, _ := filepath.Match(pattern, part); matched {
+				return true
+			}
+		}
+	}
+	// An excluded directory hides everything beneath it
+	for i := range parts {
+		if opts.ExcludesPath(strings.Join(parts[:i+1], "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitTreeIncluded reports whether a file is listed in include mode: its name
+// matches an include glob, its path matches an include regex, or it lies
+// inside a directory included by name.
+func gitTreeIncluded(parts []string, opts tree.Options) bool {
+	if opts.IncludesPath(strings.Join(parts, "/")) {
+		return true
+	}
+	for _, pattern := range opts.Include {
+		for _, dir := range parts[:len(parts)-1] {
+			if tree.MatchDirName(pattern, dir) {
+				return true
+			}
+		}
+		if matched, _ := filepath.Match(pattern, parts[len(parts)-1]); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	addFilterFlags(gitTreeCmd.Flags())
+	addOutputFlags(gitTreeCmd.Flags())
+	rootCmd.AddCommand(gitTreeCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestGitLsTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	tempDir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = tempDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q")
+
+	for _, file := range []string{"main.go", "src/app.go", "src/lib/util.go"} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("package x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	// Changes in the working tree must not show up in the revision
+	if err := os.Remove(filepath.Join(tempDir, "main.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := gitLsTree(tempDir, "HEAD", "")
+	if err != nil {
+		t.Fatalf("gitLsTree() error = %v", err)
+	}
+	root := gitTreeNodes("HEAD", entries, tree.Options{MaxDepth: -1})
+
+	expected := "HEAD\n" +
+		"├── main.go\n" +
+		"└── src\n" +
+		"    ├── app.go\n" +
+		"    └── lib\n" +
+		"        └── util.go\n"
+	if output := tree.Render(root, tree.UTF8); output != expected {
+		t.Errorf("git tree =\n%s\nexpected:\n%s", output, expected)
+	}
+
+	entries, err = gitLsTree(tempDir, "HEAD", "src")
+	if err != nil {
+		t.Fatalf("gitLsTree() error = %v", err)
+	}
+	root = gitTreeNodes("HEAD:src", entries, tree.Options{MaxDepth: 0})
+	if output := tree.Render(root, tree.UTF8); output != "HEAD:src\n├── app.go\n└── lib\n" {
+		t.Errorf("git tree of src =\n%s", output)
+	}
+
+	if _, err := gitLsTree(tempDir, "no-such-ref", ""); err == nil {
+		t.Error("expected an error for an unknown ref")
+	}
+}
+
+func TestGitTreeNodesFilters(t *testing.T) {
+	entries := []gitTreeEntry{
+		{relPath: ".DS_Store", kind: "blob"},
+		{relPath: "docs", kind: "tree"},
+		{relPath: "docs/guide.md", kind: "blob"},
+		{relPath: "main.go", kind: "blob"},
+		{relPath: "node_modules", kind: "tree"},
+		{relPath: "node_modules/x.js", kind: "blob"},
+		{relPath: "vendor", kind: "commit"},
+	}
+
+	root := gitTreeNodes("HEAD", entries, tree.Options{MaxDepth: -1, Exclude: []string{"node_modules"}})
+	expected := "HEAD\n├── docs\n│   └── guide.md\n├── main.go\n└── vendor\n"
+	if output := tree.Render(root, tree.UTF8); output != expected {
+		t.Errorf("excluded tree =\n%s\nexpected:\n%s", output, expected)
+	}
+
+	root = gitTreeNodes("HEAD", entries, tree.Options{MaxDepth: -1, Include: []string{"*.md"}})
+	expected = "HEAD\n└── docs\n    └── guide.md\n"
+	if output := tree.Render(root, tree.UTF8); output != expected {
+		t.Errorf("included tree =\n%s\nexpected:\n%s", output, expected)
+	}
+}
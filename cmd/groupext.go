@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// groupByExt is the --group-ext flag.
+var groupByExt bool
+
+// noExtension is the group heading for files without an extension.
+const noExtension = "(no extension)"
+
+// groupExtensions replaces the files in every directory of the tree with one
+// line per extension counting them, such as ".go (12)". Subdirectories are
+// listed first, followed by the extensions in order. Extensions are compared
+// case-insensitively.
+func groupExtensions(node *tree.Node) {
+	var dirs []*tree.Node
+	counts := make(map[string]int)
+	for _, child := range node.Children {
+		if child.IsDir {
+			groupExtensions(child)
+			dirs = append(dirs, child)
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(child.Name))
+		if ext == "" || ext == strings.ToLower(child.Name) {
+			ext = noExtension
+		}
+		counts[ext]++
+	}
+
+	exts := make([]string, 0, len(counts))
+	for ext := range counts {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	node.Children = dirs
+	for _, ext := range exts {
+		node.Children = append(node.Children, &tree.Node{Name: fmt.Sprintf("%s (%d)", ext, counts[ext]), Count: counts[ext]})
+	}
+}
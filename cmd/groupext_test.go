@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestGroupExtensions(t *testing.T) {
+	root := &tree.Node{Name: "project", IsDir: true, Children: []*tree.Node{
+		{Name: "Makefile"},
+		{Name: "a.go"},
+		{Name: "b.GO"},
+		{Name: "data", IsDir: true, Children: []*tree.Node{
+			{Name: "1.csv"}, {Name: "2.csv"}, {Name: "notes.md"},
+		}},
+		{Name: ".gitignore"},
+		{Name: "README.md"},
+	}}
+
+	groupExtensions(root)
+
+	expected := "project\n" +
+		"├── data\n" +
+		"│   ├── .csv (2)\n" +
+		"│   └── .md (1)\n" +
+		"├── (no extension) (2)\n" +
+		"├── .go (2)\n" +
+		"└── .md (1)\n"
+	if output := tree.Render(root, tree.UTF8); output != expected {
+		t.Errorf("groupExtensions() rendered\n%s\nexpected:\n%s", output, expected)
+	}
+}
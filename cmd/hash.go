@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	hashOutput string
+	hashResume bool
+)
+
+// hashResult is the outcome of hashing a single file.
+type hashResult struct {
+	relPath string
+	sum     string
+	size    int64
+	err     error
+}
+
+// hashStats summarizes a hashing run.
+type hashStats struct {
+	files  int
+	bytes  int64
+	failed int
+}
+
+var hashCmd = &cobra.Command{
+	Use:   "hash [path]",
+	Short: "Write a SHA-256 manifest of every file in the tree.",
+	Long: `Hash every matched file in parallel and write a manifest in the format of
+sha256sum, so it can be verified with "sha256sum -c".
+
+With --out, each hash is appended to the manifest as soon as it is computed,
+so an interrupted run on a large tree can pick up where it left off with
+--resume. Progress and throughput are shown on the terminal while it runs.
+
+Unlike the tree view, the whole tree is hashed unless --depth is given.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		if hashResume && hashOutput == "" {
+			return flagErrorf("--resume flag requires the --out flag")
+		}
+		startPath, err := resolveStartPath(args)
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("depth") {
+			maxDepth = -1
+		}
+
+		if useSmartDefaults {
+			applySmartDefaults(startPath)
+		}
+
+		matchingFiles, err := findMatchingFiles(startPath, processFilters(excludePatterns, includePatterns))
+		if err != nil {
+			return fmt.Errorf("error finding files: %w", err)
+		}
+
+		var manifest io.Writer = os.Stdout
+		done := make(map[string]bool)
+		if hashOutput != "" {
+			flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+			if hashResume {
+				if done, err = resumeManifest(hashOutput); err != nil {
+					return err
+				}
+				flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+			}
+
+			f, err := os.OpenFile(hashOutput, flags, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to open manifest: %w", err)
+			}
+			defer f.Close()
+			manifest = f
+		}
+
+		// The manifest is never hashed into itself
+		absOutput, _ := filepath.Abs(hashOutput)
+
+		var pending []string
+		resumed := 0
+		for _, path := range matchingFiles {
+			if path == absOutput {
+				continue
+			}
+			info, err := lstatCached(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			relPath, err := filepath.Rel(startPath, path)
+			if err != nil {
+				continue
+			}
+			// Entries of files no longer matched are not counted as resumed
+			if done[filepath.ToSlash(relPath)] {
+				resumed++
+				continue
+			}
+			pending = append(pending, path)
+		}
+
+		var progress io.Writer
+		if term.IsTerminal(int(os.Stderr.Fd())) {
+			progress = os.Stderr
+		}
+
+		start := time.Now()
+		stats, err := hashTree(startPath, pending, jobs(), manifest, progress)
+		if err != nil {
+			return err
+		}
+
+		elapsed := time.Since(start)
+		fmt.Fprintf(os.Stderr, "Hashed %d files (%s) in %s, %s/s", stats.files, formatSize(stats.bytes),
+			elapsed.Round(time.Millisecond), formatSize(throughput(stats.bytes, elapsed)))
+		if resumed > 0 {
+			fmt.Fprintf(os.Stderr, ", %d already in the manifest", resumed)
+		}
+		fmt.Fprintln(os.Stderr)
+
+		if stats.failed > 0 {
+			return fmt.Errorf("%d files could not be hashed", stats.failed)
+		}
+		return nil
+	},
+}
+
+// hashTree hashes paths with a pool of workers, writing a manifest line for
+// each file as soon as it is done. Files that cannot be read are reported on
+// stderr and counted in the stats rather than stopping the run. If progress is
+// not nil, a progress line is redrawn on it every second.
+func hashTree(root string, paths []string, workers int, manifest io.Writer, progress io.Writer) (hashStats, error) {
+	var total int64
+	for _, path := range paths {
+		if info, err := statCached(path); err == nil {
+			total += info.Size()
+		}
+	}
+
+	work := make(chan string)
+	results := make(chan hashResult)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for path := range work {
+				result := hashResult{relPath: path}
+				if relPath, err := filepath.Rel(root, path); err == nil {
+					result.relPath = filepath.ToSlash(relPath)
+				}
+				if info, err := statCached(path); err == nil {
+					result.size = info.Size()
+				}
+				result.sum, result.err = hashFile(path)
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		for _, path := range paths {
+			work <- path
+		}
+		close(work)
+	}()
+
+	var stats hashStats
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for remaining := len(paths); remaining > 0; {
+		select {
+		case result := <-results:
+			remaining--
+			if result.err != nil {
+				stats.failed++
+				fmt.Fprintf(os.Stderr, "\rfailed to hash %s: %v\n", result.relPath, result.err)
+				continue
+			}
+			if _, err := io.WriteString(manifest, manifestLine(result.sum, result.relPath)); err != nil {
+				return stats, fmt.Errorf("failed to write manifest: %w", err)
+			}
+			stats.files++
+			stats.bytes += result.size
+		case <-ticker.C:
+			if progress != nil {
+				fmt.Fprintf(progress, "\r%d/%d files, %s of %s (%s/s)\033[K", stats.files+stats.failed, len(paths),
+					formatSize(stats.bytes), formatSize(total), formatSize(throughput(stats.bytes, time.Since(start))))
+			}
+		}
+	}
+
+	if progress != nil {
+		fmt.Fprint(progress, "\r\033[K")
+	}
+	return stats, nil
+}
+
+// manifestLine formats a manifest entry the way sha256sum does.
+func manifestLine(sum, relPath string) string {
+	return sum + "  " + relPath + "\n"
+}
+
+// resumeManifest returns the paths already recorded in an existing manifest.
+// A line left incomplete by an interrupted run is removed so that appending
+// can continue cleanly.
+func resumeManifest(path string) (map[string]bool, error) {
+	done := make(map[string]bool)
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var complete int64
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		sum, relPath, ok := strings.Cut(strings.TrimSuffix(line, "\n"), "  ")
+		if !ok || len(sum) != 64 {
+			break
+		}
+		done[relPath] = true
+		complete += int64(len(line))
+	}
+	f.Close()
+
+	if err := os.Truncate(path, complete); err != nil {
+		return nil, fmt.Errorf("failed to repair manifest: %w", err)
+	}
+	return done, nil
+}
+
+// throughput returns the bytes processed per second.
+func throughput(bytes int64, elapsed time.Duration) int64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(bytes) / elapsed.Seconds())
+}
+
+func init() {
+	addFilterFlags(hashCmd.Flags())
+	hashCmd.Flags().StringVarP(&hashOutput, "out", "o", "", "Write the manifest to a file, checkpointing after every file")
+	hashCmd.Flags().BoolVarP(&hashResume, "resume", "", false, "Continue an interrupted run, skipping files already in the --out manifest")
+	rootCmd.AddCommand(hashCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestHashTree(t *testing.T) {
+	tempDir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt", "sub/c.txt"} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	var manifest strings.Builder
+	stats, err := hashTree(tempDir, paths, 2, &manifest, nil)
+	if err != nil {
+		t.Fatalf("hashTree() error = %v", err)
+	}
+	if stats.files != 3 || stats.bytes != 15 || stats.failed != 0 {
+		t.Errorf("hashTree() stats = %+v, expected 3 files and 15 bytes", stats)
+	}
+
+	// Workers finish in any order
+	lines := strings.Split(strings.TrimSuffix(manifest.String(), "\n"), "\n")
+	sort.Strings(lines)
+	const helloSum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	expected := []string{helloSum + "  a.txt", helloSum + "  b.txt", helloSum + "  sub/c.txt"}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("manifest =\n%s\nexpected:\n%s", strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestResumeManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.sha256")
+	sum := strings.Repeat("a", 64)
+	complete := manifestLine(sum, "done.txt") + manifestLine(sum, "dir/also done.txt")
+	if err := os.WriteFile(path, []byte(complete+sum[:20]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := resumeManifest(path)
+	if err != nil {
+		t.Fatalf("resumeManifest() error = %v", err)
+	}
+	if len(done) != 2 || !done["done.txt"] || !done["dir/also done.txt"] {
+		t.Errorf("resumeManifest() = %v, expected the two complete entries", done)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != complete {
+		t.Errorf("the incomplete last line should be removed, got %q", data)
+	}
+
+	if done, err := resumeManifest(filepath.Join(t.TempDir(), "missing")); err != nil || len(done) != 0 {
+		t.Errorf("resumeManifest() on a missing file = %v, %v, expected an empty set", done, err)
+	}
+}
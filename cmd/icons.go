@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"golang.org/x/term"
+)
+
+var (
+	// iconMode is the --icons setting: auto, always, or never.
+	iconMode string
+	// iconSet is the --icon-set the icons are drawn from: nerd or emoji.
+	iconSet string
+	// customIcons holds the KEY=ICON definitions of --icon.
+	customIcons []string
+)
+
+// iconSets map the kinds of entries to their icons. Keys are dir, file, link,
+// and exec for the kinds of entries, lowercase file names such as makefile,
+// and lowercase extensions including the leading dot. Names are looked up
+// before extensions, and file is the fallback for other files.
+var iconSets = map[string]map[string]string{
+	// Nerd Font glyphs, which need a patched font such as those from nerdfonts.com
+	"nerd": {
+		"dir": "\uf07b", "file": "\uf15b", "link": "\uf0c1", "exec": "\uf489",
+		".go": "\ue627", "go.mod": "\ue627", "go.sum": "\ue627",
+		".js": "\ue74e", ".mjs": "\ue74e", ".cjs": "\ue74e", ".jsx": "\ue7ba", ".ts": "\ue628", ".tsx": "\ue7ba",
+		".py": "\ue606", ".rs": "\ue7a8", ".java": "\ue738", ".rb": "\ue739", ".c": "\ue61e", ".h": "\ue61e", ".cpp": "\ue61d",
+		".php": "\ue73d", ".lua": "\ue620", ".swift": "\ue755",
+		".sh": "\uf489", ".bash": "\uf489", ".zsh": "\uf489", ".ps1": "\uf489", ".bat": "\uf489", ".cmd": "\uf489",
+		".html": "\ue736", ".css": "\ue749", ".scss": "\ue74b",
+		".json": "\ue60b", ".yaml": "\ue615", ".yml": "\ue615", ".toml": "\ue615", ".ini": "\ue615",
+		".md": "\ue609", ".txt": "\uf15c", ".pdf": "\uf1c1",
+		".png": "\uf1c5", ".jpg": "\uf1c5", ".jpeg": "\uf1c5", ".gif": "\uf1c5", ".svg": "\uf1c5", ".webp": "\uf1c5", ".bmp": "\uf1c5", ".ico": "\uf1c5",
+		".zip": "\uf1c6", ".tar": "\uf1c6", ".gz": "\uf1c6", ".tgz": "\uf1c6", ".xz": "\uf1c6", ".7z": "\uf1c6", ".rar": "\uf1c6",
+		".mp3": "\uf1c7", ".wav": "\uf1c7", ".flac": "\uf1c7", ".mp4": "\uf1c8", ".mkv": "\uf1c8", ".mov": "\uf1c8",
+		"makefile": "\ue615", "dockerfile": "\uf308", ".gitignore": "\ue702", "license": "\uf02d",
+	},
+	// Emoji, which most terminals show without a special font. Each is a single
+	// wide character, so the columns after the names stay aligned.
+	"emoji": {
+		"dir": "📁", "file": "📄", "link": "🔗", "exec": "🔧",
+		".go": "🐹", "go.mod": "🐹", "go.sum": "🐹",
+		".js": "📜", ".mjs": "📜", ".cjs": "📜", ".jsx": "📜", ".ts": "📜", ".tsx": "📜",
+		".py": "🐍", ".rs": "🦀", ".java": "💼", ".rb": "💎", ".c": "💻", ".h": "💻", ".cpp": "💻",
+		".php": "🐘", ".lua": "🌙", ".swift": "🐦",
+		".sh": "💲", ".bash": "💲", ".zsh": "💲", ".ps1": "💲", ".bat": "💲", ".cmd": "💲",
+		".html": "🌐", ".css": "🎨", ".scss": "🎨",
+		".json": "📋", ".yaml": "📋", ".yml": "📋", ".toml": "📋", ".ini": "📋",
+		".md": "📝", ".txt": "📝", ".pdf": "📕",
+		".png": "📷", ".jpg": "📷", ".jpeg": "📷", ".gif": "📷", ".svg": "📷", ".webp": "📷", ".bmp": "📷", ".ico": "📷",
+		".zip": "📦", ".tar": "📦", ".gz": "📦", ".tgz": "📦", ".xz": "📦", ".7z": "📦", ".rar": "📦",
+		".mp3": "🎵", ".wav": "🎵", ".flac": "🎵", ".mp4": "🎬", ".mkv": "🎬", ".mov": "🎬",
+		"makefile": "🔨", "dockerfile": "🐳", ".gitignore": "🙈", "license": "📜",
+	},
+}
+
+// validateIconFlags checks the --icons, --icon-set, and --icon settings.
+func validateIconFlags() error {
+	switch iconMode {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("invalid --icons %q (use auto, always, or never)", iconMode)
+	}
+	if _, ok := iconSets[iconSet]; !ok {
+		return fmt.Errorf("invalid --icon-set %q (use nerd or emoji)", iconSet)
+	}
+	for _, definition := range customIcons {
+		key, icon, ok := strings.Cut(definition, "=")
+		if !ok || strings.TrimSpace(key) == "" || icon == "" {
+			return fmt.Errorf("invalid --icon %q (use KEY=ICON, e.g. .vue=🟩)", definition)
+		}
+	}
+	return nil
+}
+
+// iconOutput reports whether names should have icons. As with colors, files
+// and the clipboard get none unless --icons always is given, and with --icons
+// auto neither does anything that is not a terminal.
+func iconOutput() bool {
+	switch iconMode {
+	case "always":
+		return true
+	case "auto":
+		return !copyToClipboard && outputFile == "" && term.IsTerminal(int(os.Stdout.Fd()))
+	}
+	return false
+}
+
+// outputIcons returns the icons to prefix names with, or nil for none: the
+// chosen --icon-set with the --icon definitions layered over it.
+func outputIcons() iconMap {
+	if !iconOutput() {
+		return nil
+	}
+	return newIconMap(iconSets[iconSet], customIcons)
+}
+
+// iconMap maps the keys described at iconSets to icons.
+type iconMap map[string]string
+
+// newIconMap copies base and applies KEY=ICON definitions over it. Keys are
+// matched case-insensitively, and malformed definitions are ignored, as
+// validateIconFlags has reported them.
+func newIconMap(base map[string]string, definitions []string) iconMap {
+	icons := make(iconMap, len(base)+len(definitions))
+	for key, icon := range base {
+		icons[key] = icon
+	}
+	for _, definition := range definitions {
+		if key, icon, ok := strings.Cut(definition, "="); ok && icon != "" {
+			icons[strings.ToLower(strings.TrimSpace(key))] = icon
+		}
+	}
+	return icons
+}
+
+// nodeIcon returns the icon for a node, or "" for rows without one.
+// Directories, symlinks, and executables get the icon of their kind, unless
+// their name has one of its own; other files are matched by name, then by
+// extension.
+func (m iconMap) nodeIcon(node *tree.Node) string {
+	if node == nil {
+		return ""
+	}
+	name := strings.ToLower(node.Name)
+	if icon, ok := m[name]; ok {
+		return icon
+	}
+	if node.IsDir {
+		return m["dir"]
+	}
+	if node.Info != nil {
+		mode := node.Info.Mode()
+		if mode&os.ModeSymlink != 0 {
+			return m["link"]
+		}
+		if mode.IsRegular() && isExecutable(node.Path, node.Info) {
+			return m["exec"]
+		}
+	}
+	if icon, ok := m[filepath.Ext(name)]; ok {
+		return icon
+	}
+	return m["file"]
+}
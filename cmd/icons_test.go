@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestNodeIcon(t *testing.T) {
+	icons := newIconMap(iconSets["emoji"], []string{".vue=V", "Makefile=M", "dir=D"})
+
+	tests := []struct {
+		node     *tree.Node
+		expected string
+	}{
+		{&tree.Node{Name: "src", IsDir: true}, "D"},
+		{&tree.Node{Name: "main.go"}, "🐹"},
+		{&tree.Node{Name: "Photo.JPG"}, "📷"},
+		{&tree.Node{Name: "App.vue"}, "V"},
+		{&tree.Node{Name: "makefile"}, "M"},
+		{&tree.Node{Name: "notes.unknown"}, "📄"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := icons.nodeIcon(tt.node); got != tt.expected {
+			t.Errorf("nodeIcon(%v) = %q, expected %q", tt.node, got, tt.expected)
+		}
+	}
+}
+
+func TestIconOutput(t *testing.T) {
+	originalMode, originalOut := iconMode, outputFile
+	defer func() { iconMode, outputFile = originalMode, originalOut }()
+
+	outputFile = "tree.txt"
+	iconMode = "auto"
+	if iconOutput() {
+		t.Error("iconOutput() with --icons auto and --out = true, expected false")
+	}
+	iconMode = "always"
+	if !iconOutput() {
+		t.Error("iconOutput() with --icons always and --out = false, expected true")
+	}
+	iconMode = "never"
+	outputFile = ""
+	if iconOutput() {
+		t.Error("iconOutput() with --icons never = true, expected false")
+	}
+}
+
+func TestValidateIconFlags(t *testing.T) {
+	originalMode, originalSet, originalCustom := iconMode, iconSet, customIcons
+	defer func() { iconMode, iconSet, customIcons = originalMode, originalSet, originalCustom }()
+
+	iconMode, iconSet, customIcons = "auto", "nerd", []string{".vue=V"}
+	if err := validateIconFlags(); err != nil {
+		t.Errorf("validateIconFlags() = %v, expected no error", err)
+	}
+	for _, tt := range []struct{ mode, set, custom string }{
+		{"sometimes", "nerd", ".vue=V"},
+		{"auto", "wingdings", ".vue=V"},
+		{"auto", "nerd", ".vue"},
+	} {
+		iconMode, iconSet, customIcons = tt.mode, tt.set, []string{tt.custom}
+		if err := validateIconFlags(); err == nil {
+			t.Errorf("validateIconFlags() accepted --icons %s --icon-set %s --icon %s", tt.mode, tt.set, tt.custom)
+		}
+	}
+}
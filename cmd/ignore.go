@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	ignoredOnly bool
+	// ignoredRules maps absolute paths that git would ignore to the pattern
+	// responsible. It is only populated by the would-ignore subcommand.
+	ignoredRules map[string]string
+)
+
+var wouldIgnoreCmd = &cobra.Command{
+	Use:   "would-ignore [path]",
+	Short: "Show which entries git would ignore.",
+	Long: `Render the tree with every entry that git would ignore marked with the
+.gitignore pattern responsible, or show only those entries with --only.
+
+Tracked files are checked too, so a new .gitignore can be verified before it
+is committed. The path must be inside a git repository.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		startPath, err := resolveStartPath(args)
+		if err != nil {
+			return err
+		}
+
+		if useSmartDefaults {
+			applySmartDefaults(startPath)
+		}
+
+		// The repository's own metadata is never subject to ignore rules
+		filters := processFilters(append(excludePatterns, ".git"), includePatterns)
+		matchingFiles, err := findMatchingFiles(startPath, filters)
+		if err != nil {
+			return fmt.Errorf("error finding files: %w", err)
+		}
+
+		ignoredRules, err = gitIgnoredRules(startPath, matchingFiles)
+		if err != nil {
+			return err
+		}
+		defer func() { ignoredRules = nil }()
+
+		if ignoredOnly {
+			var ignored []string
+			for _, path := range matchingFiles {
+				if _, ok := ignoredRules[path]; ok {
+					ignored = append(ignored, path)
+				}
+			}
+			if len(ignored) == 0 {
+				fmt.Println("No entries would be ignored by git.")
+				return nil
+			}
+			matchingFiles = ignored
+		}
+
+		return writeOutput(buildTreeOutput(startPath, matchingFiles))
+	},
+}
+
+// gitIgnoredRules asks git which of paths it would ignore, ignoring the index
+// so that tracked files are reported as well. The result maps each ignored
+// path to the pattern that matched it.
+func gitIgnoredRules(root string, paths []string) (map[string]string, error) {
+	var input bytes.Buffer
+	for _, path := range paths {
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, err
+		}
+		input.WriteString(filepath.ToSlash(relPath))
+		input.WriteByte(0)
+	}
+
+	cmd := exec.Command("git", "check-ignore", "--no-index", "--verbose", "--stdin", "-z")
+	cmd.Dir = root
+	cmd.Stdin = &input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means none of the paths are ignored
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("git check-ignore failed: %s", strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	// Verbose -z output is four fields per path: source, line number, pattern, path
+	rules := make(map[string]string)
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i+3 < len(fields); i += 4 {
+		pattern, relPath := fields[i+2], fields[i+3]
+		rules[filepath.Join(root, filepath.FromSlash(relPath))] = pattern
+	}
+
+	return rules, nil
+}
+
+// ignoreAnnotation marks a path that git would ignore with the matching pattern.
+func ignoreAnnotation(path string) string {
+	if pattern, ok := ignoredRules[path]; ok {
+		return "[ignored: " + pattern + "]"
+	}
+	return ""
+}
+
+func init() {
+	addFilterFlags(wouldIgnoreCmd.Flags())
+	addOutputFlags(wouldIgnoreCmd.Flags())
+	wouldIgnoreCmd.Flags().BoolVarP(&noPager, "no-pager", "", false, "Print long output directly instead of through $PAGER")
+	wouldIgnoreCmd.Flags().BoolVarP(&ignoredOnly, "only", "", false, "Show only the entries git would ignore")
+	rootCmd.AddCommand(wouldIgnoreCmd)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitIgnoredRules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	tempDir, err := os.MkdirTemp("", "wintree_ignore_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if out, err := exec.Command("git", "init", "-q", tempDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+
+	structure := map[string]string{
+		".gitignore":       "build/\n*.log\n",
+		"main.go":          "package main",
+		"app.log":          "log entry",
+		"build/output.bin": "binary",
+	}
+
+	for file, content := range structure {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := []string{
+		filepath.Join(tempDir, ".gitignore"),
+		filepath.Join(tempDir, "main.go"),
+		filepath.Join(tempDir, "app.log"),
+		filepath.Join(tempDir, "build"),
+		filepath.Join(tempDir, "build", "output.bin"),
+	}
+
+	rules, err := gitIgnoredRules(tempDir, paths)
+	if err != nil {
+		t.Fatalf("gitIgnoredRules() error = %v", err)
+	}
+
+	expected := map[string]string{
+		filepath.Join(tempDir, "app.log"):             "*.log",
+		filepath.Join(tempDir, "build"):               "build/",
+		filepath.Join(tempDir, "build", "output.bin"): "build/",
+	}
+
+	if len(rules) != len(expected) {
+		t.Errorf("gitIgnoredRules() returned %d rules, expected %d: %v", len(rules), len(expected), rules)
+	}
+	for path, pattern := range expected {
+		if rules[path] != pattern {
+			t.Errorf("gitIgnoredRules()[%q] = %q, expected %q", path, rules[path], pattern)
+		}
+	}
+
+	t.Run("nothing ignored", func(t *testing.T) {
+		rules, err := gitIgnoredRules(tempDir, []string{filepath.Join(tempDir, "main.go")})
+		if err != nil {
+			t.Fatalf("gitIgnoredRules() error = %v", err)
+		}
+		if len(rules) != 0 {
+			t.Errorf("gitIgnoredRules() returned %v, expected no rules", rules)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the file whose patterns are added to --exclude for the
+// directory holding it and everything beneath it.
+const ignoreFileName = ".wintreeignore"
+
+// noIgnoreFile disables .wintreeignore files.
+var noIgnoreFile bool
+
+// readIgnoreFile returns the patterns in dir's .wintreeignore, one per line,
+// skipping blank lines and # comments, with braces expanded as for
+// --exclude. A missing or unreadable file has no patterns.
+func readIgnoreFile(dir string) []string {
+	file, err := os.Open(filepath.Join(dir, ignoreFileName))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, expandBraces(line)...)
+	}
+	return patterns
+}
+
+// dropNestedIgnored removes the paths beneath root that match the
+// .wintreeignore of a directory below root, along with everything beneath
+// them. dirs lists the directories that hold one.
+func dropNestedIgnored(root string, paths, dirs []string) []string {
+	rules := make(map[string][]string)
+	for _, dir := range dirs {
+		if patterns := readIgnoreFile(dir); len(patterns) > 0 {
+			rules[dir] = patterns
+		}
+	}
+	if len(rules) == 0 {
+		return paths
+	}
+
+	var kept []string
+	for _, path := range paths {
+		if !nestedIgnored(root, path, rules) {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
+
+// nestedIgnored reports whether path, or any directory between it and root,
+// matches a pattern of a directory above it in rules.
+func nestedIgnored(root, path string, rules map[string][]string) bool {
+	for entry := path; entry != root && entry != filepath.Dir(entry); entry = filepath.Dir(entry) {
+		name := filepath.Base(entry)
+		for dir := filepath.Dir(entry); ; dir = filepath.Dir(dir) {
+			for _, pattern := range rules[dir] {
+				if matched, _ := filepath.Match(pattern, name); matched {
+					return true
+				}
+			}
+			if dir == root || dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	return false
+}
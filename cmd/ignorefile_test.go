@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIgnoreFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	for file, content := range map[string]string{
+		".wintreeignore":      "# generated output\n\ndist\n*.{log,tmp}\n",
+		"dist/app.js":         "",
+		"debug.log":           "",
+		"main.go":             "",
+		"web/.wintreeignore":  "fixtures\n",
+		"web/fixtures/a.json": "",
+		"web/index.js":        "",
+		"fixtures/b.json":     "",
+	} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if patterns, expected := readIgnoreFile(tempDir), []string{"dist", "*.log", "*.tmp"}; !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("readIgnoreFile() = %v, expected %v", patterns, expected)
+	}
+
+	originalDepth, originalNoIgnore := maxDepth, noIgnoreFile
+	defer func() { maxDepth, noIgnoreFile = originalDepth, originalNoIgnore }()
+	maxDepth = -1
+
+	tests := []struct {
+		name     string
+		noIgnore bool
+		expected []string
+	}{
+		{
+			// A nested file's patterns only apply beneath its directory
+			name:     "root and nested",
+			expected: []string{".wintreeignore", "fixtures", "fixtures/b.json", "main.go", "web", "web/.wintreeignore", "web/index.js"},
+		},
+		{
+			name:     "--no-ignore-file",
+			noIgnore: true,
+			expected: []string{".wintreeignore", "debug.log", "dist", "dist/app.js", "fixtures", "fixtures/b.json", "main.go", "web", "web/.wintreeignore", "web/fixtures", "web/fixtures/a.json", "web/index.js"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noIgnoreFile = tt.noIgnore
+			paths, err := findMatchingFiles(tempDir, processFilters(nil, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var relPaths []string
+			for _, path := range paths {
+				relPath, _ := filepath.Rel(tempDir, path)
+				relPaths = append(relPaths, filepath.ToSlash(relPath))
+			}
+			if !reflect.DeepEqual(relPaths, tt.expected) {
+				t.Errorf("findMatchingFiles() = %v, expected %v", relPaths, tt.expected)
+			}
+		})
+	}
+}
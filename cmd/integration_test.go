@@ -3,6 +3,8 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -444,3 +446,188 @@ func TestFilepathFlag(t *testing.T) {
 		}
 	})
 }
+
+func TestFindMatchingFiles_DirsDepth(t *testing.T) {
+	testDir := setupTestDirectory(t)
+	defer os.RemoveAll(testDir)
+
+	originalMaxDepth := maxDepth
+	defer func() {
+		maxDepth = originalMaxDepth
+		dirsDepth = 0
+	}()
+
+	// --depth is ignored while --dirs-depth is set
+	maxDepth = 0
+	dirsDepth = 1
+
+	matchingFiles, err := findMatchingFiles(testDir, processFilters([]string{}, []string{}))
+	if err != nil {
+		t.Fatalf("findMatchingFiles() error = %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, file := range matchingFiles {
+		relPath, _ := filepath.Rel(testDir, file)
+		found[filepath.ToSlash(relPath)] = true
+	}
+
+	for _, expected := range []string{"main.go", "src", "src/app.go", "docs/api.md", "node_modules"} {
+		if !found[expected] {
+			t.Errorf("Expected %q to be listed", expected)
+		}
+	}
+	for _, unexpected := range []string{"node_modules/package", "node_modules/package/index.js"} {
+		if found[unexpected] {
+			t.Errorf("Expected %q to be beyond --dirs-depth", unexpected)
+		}
+	}
+}
+
+func TestFindMatchingFiles_OSNoise(t *testing.T) {
+	testDir := t.TempDir()
+	for _, name := range []string{"main.go", ".DS_Store", "docs/Thumbs.db", "docs/guide.md", "DESKTOP.INI", "$RECYCLE.BIN/deleted.txt"} {
+		path := filepath.Join(testDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	originalMaxDepth := maxDepth
+	defer func() {
+		maxDepth = originalMaxDepth
+		showOSFiles = false
+	}()
+	maxDepth = -1
+
+	names := func(filters filter) map[string]bool {
+		matchingFiles, err := findMatchingFiles(testDir, filters)
+		if err != nil {
+			t.Fatalf("findMatchingFiles() error = %v", err)
+		}
+		found := make(map[string]bool)
+		for _, file := range matchingFiles {
+			found[filepath.Base(file)] = true
+		}
+		return found
+	}
+
+	found := names(processFilters([]string{}, []string{}))
+	for _, noise := range []string{".DS_Store", "Thumbs.db", "DESKTOP.INI", "$RECYCLE.BIN", "deleted.txt"} {
+		if found[noise] {
+			t.Errorf("OS metadata %q should be hidden by default", noise)
+		}
+	}
+	if !found["main.go"] || !found["guide.md"] {
+		t.Errorf("regular files should still be listed, got %v", found)
+	}
+
+	// Directories included by name are walked separately
+	if found := names(processFilters([]string{}, []string{"docs"})); found["Thumbs.db"] || !found["guide.md"] {
+		t.Errorf("OS metadata inside an included directory should be hidden, got %v", found)
+	}
+
+	showOSFiles = true
+	if found := names(processFilters([]string{}, []string{})); !found[".DS_Store"] || !found["deleted.txt"] {
+		t.Errorf("--show-os-files should list OS metadata, got %v", found)
+	}
+}
+
+func TestFindMatchingFiles_Type(t *testing.T) {
+	testDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(testDir, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(testDir, "bin", "run.sh"), []byte("#!/bin/sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(testDir, "link.go")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	originalMaxDepth := maxDepth
+	defer func() {
+		maxDepth = originalMaxDepth
+		fileTypes = nil
+	}()
+	maxDepth = -1
+
+	tests := []struct {
+		types    []string
+		expected []string
+	}{
+		{[]string{"f"}, []string{"bin/run.sh", "main.go"}},
+		{[]string{"d"}, []string{"bin"}},
+		{[]string{"l"}, []string{"link.go"}},
+		{[]string{"d", "l"}, []string{"bin", "link.go"}},
+	}
+	// Windows decides by extension rather than the execute bit
+	if runtime.GOOS != "windows" {
+		tests = append(tests, tests[0])
+		tests[len(tests)-1].types = []string{"x"}
+		tests[len(tests)-1].expected = []string{"bin/run.sh"}
+	}
+
+	for _, tt := range tests {
+		fileTypes = tt.types
+		matchingFiles, err := findMatchingFiles(testDir, processFilters([]string{}, []string{}))
+		if err != nil {
+			t.Fatalf("findMatchingFiles() error = %v", err)
+		}
+
+		var found []string
+		for _, file := range matchingFiles {
+			relPath, _ := filepath.Rel(testDir, file)
+			found = append(found, filepath.ToSlash(relPath))
+		}
+		if !reflect.DeepEqual(found, tt.expected) {
+			t.Errorf("--type %v = %v, expected %v", tt.types, found, tt.expected)
+		}
+	}
+
+	fileTypes = []string{"q"}
+	if err := validateFilterFlags(); exitCode(err) != exitUsage {
+		t.Errorf("validateFilterFlags() = %v for an unknown --type, expected a flag error", err)
+	}
+}
+
+func TestFindMatchingFiles_MinDepth(t *testing.T) {
+	testDir := setupTestDirectory(t)
+	defer os.RemoveAll(testDir)
+
+	originalMaxDepth := maxDepth
+	defer func() {
+		maxDepth = originalMaxDepth
+		minDepth = 0
+	}()
+	maxDepth = -1
+	minDepth = 1
+
+	matchingFiles, err := findMatchingFiles(testDir, processFilters([]string{}, []string{}))
+	if err != nil {
+		t.Fatalf("findMatchingFiles() error = %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, file := range matchingFiles {
+		relPath, _ := filepath.Rel(testDir, file)
+		found[filepath.ToSlash(relPath)] = true
+	}
+
+	for _, expected := range []string{"src/app.go", "docs/api.md", "node_modules/package", "node_modules/package/index.js"} {
+		if !found[expected] {
+			t.Errorf("Expected %q to be listed", expected)
+		}
+	}
+	for _, unexpected := range []string{"main.go", "src", "docs", "node_modules"} {
+		if found[unexpected] {
+			t.Errorf("Expected %q to be shallower than --min-depth", unexpected)
+		}
+	}
+}
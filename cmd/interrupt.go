@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+)
+
+// interruptMarker ends the output of a walk stopped by Ctrl+C, so a partial
+// tree is not mistaken for a complete one.
+const interruptMarker = "-- interrupted --"
+
+// errInterrupted ends a run stopped by Ctrl+C once its partial output is
+// written. The process exits with 130, as shells report an interrupt.
+var errInterrupted = errors.New("interrupted")
+
+// walkStop is closed when Ctrl+C is pressed while catchInterrupt is in
+// effect, stopping the walk of findMatchingFiles.
+var walkStop chan struct{}
+
+// catchInterrupt makes the first Ctrl+C stop the walk instead of the process,
+// until release is called. A second Ctrl+C exits as usual, in case the walk
+// is stuck reading a directory.
+func catchInterrupt() (release func()) {
+	stop := make(chan struct{})
+	walkStop = stop
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		walkStop = nil
+	}
+}
+
+// markInterrupted appends the interrupt marker to output, or prints it on
+// stderr for the formats that other programs parse.
+func markInterrupted(output string) string {
+	switch outputFormat {
+	case "json", "script", "powershell", "dot", "quickfix":
+		os.Stderr.WriteString(interruptMarker + "\n")
+		return output
+	}
+	if output != "" && output[len(output)-1] != '\n' {
+		output += "\n"
+	}
+	return output + interruptMarker + "\n"
+}
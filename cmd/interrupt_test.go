@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInterruptedWalk(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A walk stopped before it starts finds nothing, but is not a failure
+	walkStop = make(chan struct{})
+	close(walkStop)
+	defer func() { walkStop = nil }()
+	paths, err := findMatchingFiles(tempDir, processFilters(nil, nil))
+	if !errors.Is(err, errInterrupted) || len(paths) != 0 {
+		t.Errorf("findMatchingFiles() = %v, %v, expected no paths and errInterrupted", paths, err)
+	}
+
+	originalFormat := outputFormat
+	defer func() { outputFormat = originalFormat }()
+	outputFormat = "tree"
+	if output := markInterrupted("root\n└── a"); output != "root\n└── a\n"+interruptMarker+"\n" {
+		t.Errorf("markInterrupted() = %q, expected the marker on its own line", output)
+	}
+	// Output other programs parse is left intact
+	outputFormat = "json"
+	if output := markInterrupted("{}\n"); output != "{}\n" {
+		t.Errorf("markInterrupted() with --format json = %q, expected it unchanged", output)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// showLatest is the --latest flag.
+var showLatest bool
+
+// latestCache holds the newest modification time beneath each directory
+// measured so far in the current render. Directories without any matching
+// file map to the zero time.
+var latestCache = make(map[string]time.Time)
+
+// latestAnnotation returns the modification time of the newest file anywhere
+// beneath a directory, however deep, counting only files the current
+// filters would show. Files and empty directories are not annotated.
+func latestAnnotation(path string) string {
+	if path == "" {
+		return ""
+	}
+	info, err := lstatCached(path)
+	if err != nil || !info.IsDir() {
+		return ""
+	}
+
+	latest := latestModTime(path)
+	if latest.IsZero() {
+		return ""
+	}
+	return "[latest " + latest.Local().Format("2006-01-02 15:04") + "]"
+}
+
+// latestModTime walks dir once, recording the newest file beneath every
+// subdirectory along the way, so that the rest of the tree is served from
+// the cache. Files count if the tree would show them at any depth: they pass
+// the walk's filters, those of fileFilters, and --sample.
+func latestModTime(dir string) time.Time {
+	if latest, ok := latestCache[dir]; ok {
+		return latest
+	}
+
+	filters := processFilters(excludePatterns, includePatterns)
+	if !noIgnoreFile {
+		filters.excludeGlobs = append(slices.Clip(filters.excludeGlobs), readIgnoreFile(dir)...)
+	}
+	latest := map[string]time.Time{dir: {}}
+	var files, ignoreDirs []string
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are left out rather than failing the tree
+			if d != nil && d.IsDir() && p != dir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !noIgnoreFile && d.Name() == ignoreFileName && filepath.Dir(p) != dir {
+			ignoreDirs = append(ignoreDirs, filepath.Dir(p))
+		}
+		if p != dir && isExcludedPath(dir, p, filters) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if _, ok := latest[p]; !ok {
+				latest[p] = time.Time{}
+			}
+			return nil
+		}
+		if matchesIncludePath(dir, p, filters) {
+			files = append(files, p)
+		}
+		return nil
+	})
+
+	for _, ff := range fileFilters(dir, ignoreDirs) {
+		files = ff.keep(files)
+	}
+	if limits.sampleFraction > 0 || sampleCount > 0 {
+		files = sampleFiles(dir, files, limits.sampleFraction)
+	}
+
+	for _, p := range files {
+		info, err := lstatCached(p)
+		if err != nil {
+			continue
+		}
+		modTime := info.ModTime()
+		for parent := filepath.Dir(p); ; parent = filepath.Dir(parent) {
+			if modTime.After(latest[parent]) {
+				latest[parent] = modTime
+			}
+			if parent == dir {
+				break
+			}
+		}
+	}
+
+	for p, modTime := range latest {
+		latestCache[p] = modTime
+	}
+	return latest[dir]
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// lineEndings is the --line-endings setting for file and clipboard output:
+// lf, crlf, or auto.
+var lineEndings string
+
+// convertLineEndings returns text with the line endings --line-endings asks
+// for. With auto, text is converted to CRLF on Windows, where Notepad and
+// other tools expect it, except for shell scripts, which need LF; elsewhere
+// it is left as it is.
+func convertLineEndings(text string) (string, error) {
+	crlf := false
+	switch lineEndings {
+	case "lf":
+	case "crlf":
+		crlf = true
+	case "auto":
+		if runtime.GOOS != "windows" || outputFormat == "script" {
+			return text, nil
+		}
+		crlf = true
+	default:
+		return "", fmt.Errorf("invalid --line-endings %q (use lf, crlf, or auto)", lineEndings)
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if crlf {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	return text, nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestConvertLineEndings(t *testing.T) {
+	originalEndings, originalFormat := lineEndings, outputFormat
+	defer func() { lineEndings, outputFormat = originalEndings, originalFormat }()
+
+	input := "project\n└── main.go\r\n"
+	outputFormat = "tree"
+	autoExpected := input
+	if runtime.GOOS == "windows" {
+		autoExpected = "project\r\n└── main.go\r\n"
+	}
+
+	tests := []struct {
+		endings  string
+		format   string
+		expected string
+	}{
+		{"lf", "tree", "project\n└── main.go\n"},
+		{"crlf", "tree", "project\r\n└── main.go\r\n"},
+		{"auto", "tree", autoExpected},
+		// Shell scripts break with CRLF, so auto never gives them one
+		{"auto", "script", input},
+	}
+	for _, tt := range tests {
+		lineEndings, outputFormat = tt.endings, tt.format
+		if result, err := convertLineEndings(input); err != nil || result != tt.expected {
+			t.Errorf("convertLineEndings() with %s and --format %s = %q, %v, expected %q", tt.endings, tt.format, result, err, tt.expected)
+		}
+	}
+
+	lineEndings = "cr"
+	if _, err := convertLineEndings(input); err == nil {
+		t.Error("convertLineEndings() expected an error for an unknown setting")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cmd
+
+// userLocale returns the locale that dates are shown in, going by the
+// environment.
+func userLocale() string {
+	return envLocale("LC_TIME")
+}
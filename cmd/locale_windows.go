@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// localeNameMaxLength is LOCALE_NAME_MAX_LENGTH, including the terminating NUL.
+const localeNameMaxLength = 85
+
+var procGetUserDefaultLocaleName = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+
+// userLocale returns the locale that dates are shown in: the region format
+// chosen in Windows settings, such as "en-GB", unless the environment sets
+// one, as shells such as Git Bash do.
+func userLocale() string {
+	if locale := envLocale("LC_TIME"); locale != "" {
+		return locale
+	}
+	var name [localeNameMaxLength]uint16
+	if n, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&name[0])), localeNameMaxLength); n == 0 {
+		return ""
+	}
+	return windows.UTF16ToString(name[:])
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/spf13/cobra"
+)
+
+var longPathLimit int
+
+// longPath is a path that exceeds the --over limit.
+type longPath struct {
+	relPath string
+	length  int
+}
+
+var longPathsCmd = &cobra.Command{
+	Use:   "longpaths [path]",
+	Short: "List paths that are too long for Windows.",
+	Long: `List every path longer than --over characters, longest first, with its
+length. Lengths are measured relative to the tree root in UTF-16 code units,
+as Windows counts them. The default of 240 leaves room under the classic
+260-character MAX_PATH limit for the directory the tree is checked out into.
+
+The whole tree is checked unless --depth is given. The command exits with a
+non-zero status when long paths are found.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		startPath, matchingFiles, err := findAuditPaths(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		long := findLongPaths(startPath, matchingFiles, longPathLimit)
+		if len(long) == 0 {
+			fmt.Printf("No paths longer than %d characters.\n", longPathLimit)
+			return nil
+		}
+
+		width := len(fmt.Sprint(long[0].length))
+		for _, path := range long {
+			fmt.Printf("%*d  %s\n", width, path.length, path.relPath)
+		}
+		return fmt.Errorf("%d paths longer than %d characters", len(long), longPathLimit)
+	},
+}
+
+// findLongPaths returns the paths whose Windows-style relative form is longer
+// than limit, longest first.
+func findLongPaths(root string, paths []string, limit int) []longPath {
+	var long []longPath
+	for _, path := range paths {
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		length := len(utf16.Encode([]rune(relPath)))
+		if length > limit {
+			long = append(long, longPath{relPath: filepath.ToSlash(relPath), length: length})
+		}
+	}
+
+	sort.Slice(long, func(i, j int) bool {
+		if long[i].length != long[j].length {
+			return long[i].length > long[j].length
+		}
+		return long[i].relPath < long[j].relPath
+	})
+	return long
+}
+
+func init() {
+	addFilterFlags(longPathsCmd.Flags())
+	longPathsCmd.Flags().IntVarP(&longPathLimit, "over", "", 240, "Report paths longer than this many characters")
+	rootCmd.AddCommand(longPathsCmd)
+}
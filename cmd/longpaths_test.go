@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindLongPaths(t *testing.T) {
+	root := "project"
+	paths := []string{
+		filepath.Join(root, "short.txt"),
+		filepath.Join(root, strings.Repeat("a", 10), "exactly.txt"),
+		filepath.Join(root, strings.Repeat("b", 20)),
+		// Characters outside the BMP take two UTF-16 code units on Windows
+		filepath.Join(root, strings.Repeat("😀", 10)),
+	}
+
+	// Longest first, then by path
+	expected := []longPath{
+		{relPath: strings.Repeat("a", 10) + "/exactly.txt", length: 22},
+		{relPath: strings.Repeat("b", 20), length: 20},
+		{relPath: strings.Repeat("😀", 10), length: 20},
+	}
+
+	if result := findLongPaths(root, paths, 15); !reflect.DeepEqual(result, expected) {
+		t.Errorf("findLongPaths() = %+v, expected %+v", result, expected)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// markdownRenderer writes the tree for pasting into Markdown documents: the
+// text tree in a fenced code block, or with list set, a nested bullet list
+// with each name in backticks. With --full-path, the root's full path is
+// written as a heading line above it and the tree starts from its name.
+type markdownRenderer struct {
+	list bool
+}
+
+func (r markdownRenderer) render(root *tree.Node) (string, error) {
+	var output strings.Builder
+
+	if treeLabel == "" && root.Path != "" && rootLabelStyle(root.Path) == "full" {
+		output.WriteString("**" + markdownCode(fullPathLabel(root.Path)) + "**\n\n")
+		root.Name = displayName(root.Path)
+	}
+
+	if r.list {
+		root.Walk(func(node *tree.Node, relPath string) {
+			depth := 0
+			if relPath != "." {
+				depth = strings.Count(relPath, "/") + 1
+			}
+			name := node.Name
+			if node.IsDir {
+				name += "/"
+			}
+			output.WriteString(strings.Repeat("  ", depth) + "- " + markdownCode(name) + "\n")
+		})
+		return output.String(), nil
+	}
+
+	// Colors would show up as escape codes in the document
+	text := formatRows(treeRows(root), nil)
+	fence := codeFence([]byte(text))
+	output.WriteString(fence + "text\n" + text + fence + "\n")
+	return output.String(), nil
+}
+
+// markdownCode wraps s in a code span, using a longer backtick delimiter
+// (padded with spaces) when s itself contains backticks.
+func markdownCode(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	if longest == 0 {
+		return "`" + s + "`"
+	}
+	delimiter := strings.Repeat("`", longest+1)
+	return delimiter + " " + s + " " + delimiter
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMarkdownRenderer(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"src/main.go", "odd`name.md"} {
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{
+		filepath.Join(root, "odd`name.md"),
+		filepath.Join(root, "src"),
+		filepath.Join(root, "src", "main.go"),
+	}
+
+	originalFullPath, originalCharset := showFullPath, charsetMode
+	defer func() { showFullPath, charsetMode = originalFullPath, originalCharset }()
+	charsetMode = "utf8"
+
+	t.Run("code block with path header", func(t *testing.T) {
+		showFullPath = true
+		expected := "**`" + root + "`**\n\n" +
+			"```text\n" +
+			filepath.Base(root) + "\n" +
+			"├── odd`name.md\n" +
+			"└── src\n" +
+			"    └── main.go\n" +
+			"```\n"
+		if output, _ := (markdownRenderer{}).render(buildTree(root, paths)); output != expected {
+			t.Errorf("render() =\n%s\nexpected\n%s", output, expected)
+		}
+	})
+
+	t.Run("bullet list", func(t *testing.T) {
+		showFullPath = false
+		expected := "- `" + filepath.Base(root) + "/`\n" +
+			"  - `` odd`name.md ``\n" +
+			"  - `src/`\n" +
+			"    - `main.go`\n"
+		if output, _ := (markdownRenderer{list: true}).render(buildTree(root, paths)); output != expected {
+			t.Errorf("render() =\n%s\nexpected\n%s", output, expected)
+		}
+	})
+}
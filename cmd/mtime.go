@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// defaultTimeFormat is the --time-format used unless another is given.
+const defaultTimeFormat = "2006-01-02 15:04"
+
+// timeFormatPresets are the names --time-format accepts besides a Go time
+// layout. "unix" is handled by mtimeColumn, as no layout produces it, and
+// "locale" by timeLayout.
+var timeFormatPresets = map[string]string{
+	"iso":      time.RFC3339,
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02 15:04:05",
+	"unix":     "",
+	"locale":   "",
+}
+
+// localeTimeLayouts are the layouts for "--time-format locale", keyed by
+// language and region as in "en_US", or by language alone for the regions
+// that share one.
+var localeTimeLayouts = map[string]string{
+	"en_US": "01/02/2006 3:04 PM",
+	"en_PH": "01/02/2006 3:04 PM",
+	"en_CA": "2006-01-02 3:04 PM",
+	"en":    "02/01/2006 15:04",
+	"fr_CA": "2006-01-02 15:04",
+	"fr":    "02/01/2006 15:04",
+	"es":    "02/01/2006 15:04",
+	"it":    "02/01/2006 15:04",
+	"pt":    "02/01/2006 15:04",
+	"el":    "02/01/2006 15:04",
+	"nl":    "02-01-2006 15:04",
+	"de":    "02.01.2006 15:04",
+	"ru":    "02.01.2006 15:04",
+	"uk":    "02.01.2006 15:04",
+	"pl":    "02.01.2006 15:04",
+	"cs":    "02.01.2006 15:04",
+	"fi":    "02.01.2006 15:04",
+	"nb":    "02.01.2006 15:04",
+	"da":    "02.01.2006 15:04",
+	"tr":    "02.01.2006 15:04",
+	"sv":    "2006-01-02 15:04",
+	"lt":    "2006-01-02 15:04",
+	"hu":    "2006. 01. 02. 15:04",
+	"ko":    "2006. 01. 02. 15:04",
+	"ja":    "2006/01/02 15:04",
+	"zh":    "2006/01/02 15:04",
+}
+
+// timeLayout returns the Go time layout for --time-format.
+func timeLayout() string {
+	if timeFormat == "locale" {
+		return localeTimeLayout(userLocale())
+	}
+	if layout, ok := timeFormatPresets[timeFormat]; ok {
+		return layout
+	}
+	return timeFormat
+}
+
+// localeTimeLayout returns the date and time layout used in a locale, given
+// as in $LANG ("de_DE.UTF-8") or Windows ("de-DE"), or defaultTimeFormat for
+// locales without a known layout, such as C and POSIX.
+func localeTimeLayout(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "-", "_")
+
+	if layout, ok := localeTimeLayouts[locale]; ok {
+		return layout
+	}
+	language, _, _ := strings.Cut(locale, "_")
+	if layout, ok := localeTimeLayouts[strings.ToLower(language)]; ok {
+		return layout
+	}
+	return defaultTimeFormat
+}
+
+// validateTimeFormat reports a --time-format that is neither a preset nor a
+// layout with any of the elements of Go's reference time.
+func validateTimeFormat() error {
+	if _, ok := timeFormatPresets[timeFormat]; ok {
+		return nil
+	}
+	// Two times that differ in every field format alike only without elements
+	first := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	second := time.Date(2011, time.March, 7, 8, 9, 10, 0, time.UTC)
+	if first.Format(timeFormat) == second.Format(timeFormat) {
+		return fmt.Errorf("invalid --time-format %q (use iso, date, datetime, unix, locale, or a Go layout such as \"Jan 02 15:04\")", timeFormat)
+	}
+	return nil
+}
+
+// mtimeColumn returns the last-modified time of a node in the local time
+// zone, formatted by --time-format. Nodes that do not exist on disk have
+// none.
+func mtimeColumn(node *tree.Node) string {
+	if node == nil || node.Info == nil {
+		return ""
+	}
+	modTime := node.Info.ModTime()
+	if timeFormat == "unix" {
+		return strconv.FormatInt(modTime.Unix(), 10)
+	}
+	return modTime.Local().Format(timeLayout())
+}
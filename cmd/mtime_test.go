@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestMtimeColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.Local)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := &tree.Node{Name: "file.txt", Path: path, Info: info}
+
+	originalFormat := timeFormat
+	defer func() { timeFormat = originalFormat }()
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{defaultTimeFormat, "2024-03-05 14:30"},
+		{"date", "2024-03-05"},
+		{"unix", strconv.FormatInt(modTime.Unix(), 10)},
+		{"Jan _2 15:04", "Mar  5 14:30"},
+	}
+	for _, tt := range tests {
+		timeFormat = tt.format
+		if err := validateTimeFormat(); err != nil {
+			t.Errorf("validateTimeFormat(%q) error = %v", tt.format, err)
+		}
+		if column := mtimeColumn(node); column != tt.expected {
+			t.Errorf("mtimeColumn() with %q = %q, expected %q", tt.format, column, tt.expected)
+		}
+	}
+
+	if column := mtimeColumn(&tree.Node{Name: "virtual"}); column != "" {
+		t.Errorf("mtimeColumn() of a node not on disk = %q, expected none", column)
+	}
+
+	timeFormat = "modified"
+	if err := validateTimeFormat(); err == nil {
+		t.Error("validateTimeFormat() expected an error for a layout without time elements")
+	}
+}
+
+func TestLocaleTimeLayout(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"en_US.UTF-8", "01/02/2006 3:04 PM"},
+		{"en-GB", "02/01/2006 15:04"},
+		{"de_DE.UTF-8@euro", "02.01.2006 15:04"},
+		{"fr-CA", "2006-01-02 15:04"},
+		{"ja_JP.eucJP", "2006/01/02 15:04"},
+		{"C", defaultTimeFormat},
+		{"", defaultTimeFormat},
+	}
+	for _, tt := range tests {
+		if layout := localeTimeLayout(tt.locale); layout != tt.expected {
+			t.Errorf("localeTimeLayout(%q) = %q, expected %q", tt.locale, layout, tt.expected)
+		}
+	}
+}
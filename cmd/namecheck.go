@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// windowsReservedNames are device names that Windows reserves in every
+// directory, with or without an extension.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsForbiddenChars cannot appear in Windows file names.
+const windowsForbiddenChars = `<>:"\|?*`
+
+var nameCheckCmd = &cobra.Command{
+	Use:   "name-check [path]",
+	Short: "Find names that are invalid on Windows.",
+	Long: `Report files and directories whose names are invalid or problematic on
+Windows: reserved device names such as CON and NUL (even with an extension),
+names ending in a dot or space, and names containing control characters or
+any of < > : " \ | ? *. Run it on Linux or macOS to keep cross-platform
+trees checkout-able everywhere.
+
+The whole tree is checked unless --depth is given. The command exits with a
+non-zero status when problems are found.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		startPath, matchingFiles, err := findAuditPaths(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, path := range matchingFiles {
+			problem := windowsNameProblem(filepath.Base(path))
+			if problem == "" {
+				continue
+			}
+			relPath, err := filepath.Rel(startPath, path)
+			if err != nil {
+				relPath = path
+			}
+			fmt.Printf("%s: %s\n", filepath.ToSlash(relPath), problem)
+			count++
+		}
+
+		if count == 0 {
+			fmt.Println("No invalid names found.")
+			return nil
+		}
+		return fmt.Errorf("%d invalid names found", count)
+	},
+}
+
+// windowsNameProblem describes why name cannot be used on Windows, or returns
+// an empty string if it can.
+func windowsNameProblem(name string) string {
+	base, _, _ := strings.Cut(name, ".")
+	if windowsReservedNames[strings.ToUpper(strings.TrimRight(base, " "))] {
+		return "reserved name " + strings.ToUpper(strings.TrimRight(base, " "))
+	}
+
+	for _, r := range name {
+		if r < 0x20 {
+			return fmt.Sprintf("control character %U", r)
+		}
+		if strings.ContainsRune(windowsForbiddenChars, r) {
+			return fmt.Sprintf("forbidden character %q", r)
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(name, "."):
+		return "ends with a dot"
+	case strings.HasSuffix(name, " "):
+		return "ends with a space"
+	}
+	return ""
+}
+
+func init() {
+	addFilterFlags(nameCheckCmd.Flags())
+	rootCmd.AddCommand(nameCheckCmd)
+}
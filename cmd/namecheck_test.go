@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestWindowsNameProblem(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"main.go", ""},
+		{"console.log", ""},
+		{"CON", "reserved name CON"},
+		{"nul.txt", "reserved name NUL"},
+		{"com1.tar.gz", "reserved name COM1"},
+		{"aux .md", "reserved name AUX"},
+		{"what?.txt", `forbidden character '?'`},
+		{`back\slash`, `forbidden character '\\'`},
+		{"tab\there", "control character U+0009"},
+		{"draft.", "ends with a dot"},
+		{"notes ", "ends with a space"},
+		{".gitignore", ""},
+	}
+
+	for _, tt := range tests {
+		if result := windowsNameProblem(tt.name); result != tt.expected {
+			t.Errorf("windowsNameProblem(%q) = %q, expected %q", tt.name, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// nameRuleSpecs are the --rule values given to lint-names.
+var nameRuleSpecs []string
+
+// nameRule is a parsed naming convention. check describes how a name breaks
+// it, or returns an empty string if the name follows it.
+type nameRule struct {
+	spec  string
+	dirs  bool
+	files bool
+	check func(name string) string
+}
+
+// caseStyles match the stem of a name (the part before its first dot,
+// ignoring leading dots) for each supported case style.
+var caseStyles = map[string]*regexp.Regexp{
+	"kebab-case": regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`),
+	"snake_case": regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`),
+	"camelCase":  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"PascalCase": regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+}
+
+var lintNamesCmd = &cobra.Command{
+	Use:   "lint-names [path]",
+	Short: "Find names that break naming conventions.",
+	Long: `Report files and directories whose names break the naming conventions given
+with --rule. Each rule may end with "dirs" or "files" to apply to only one
+kind of entry:
+
+  kebab-case, snake_case, camelCase, PascalCase
+      the name, up to its first dot, uses this case style
+  lowercase      the name has no uppercase letters
+  no spaces      the name has no whitespace
+  no non-ascii   the name is plain ASCII
+  no chars SET   the name has none of the characters in SET
+  max length N   the name is at most N characters long
+
+  wintree lint-names --rule "kebab-case dirs" --rule "no spaces"
+  wintree lint-names src --rule "max length 40 files" --rule "no chars #%&"
+
+The whole tree is checked unless --depth is given, and .git is always
+skipped. The command exits with a non-zero status when problems are found.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(nameRuleSpecs) == 0 {
+			return flagErrorf("at least one --rule is required")
+		}
+		var rules []nameRule
+		for _, spec := range nameRuleSpecs {
+			rule, err := parseNameRule(spec)
+			if err != nil {
+				return asFlagError(err)
+			}
+			rules = append(rules, rule)
+		}
+
+		// Repository metadata is not subject to the project's conventions
+		excludePatterns = append(excludePatterns, ".git")
+		startPath, matchingFiles, err := findAuditPaths(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, path := range matchingFiles {
+			info, err := lstatCached(path)
+			if err != nil {
+				continue
+			}
+			problems := nameProblems(filepath.Base(path), info.IsDir(), rules)
+			if len(problems) == 0 {
+				continue
+			}
+			relPath, err := filepath.Rel(startPath, path)
+			if err != nil {
+				relPath = path
+			}
+			fmt.Printf("%s: %s\n", filepath.ToSlash(relPath), strings.Join(problems, "; "))
+			count++
+		}
+
+		if count == 0 {
+			fmt.Println("No naming problems found.")
+			return nil
+		}
+		return fmt.Errorf("%d names break the rules", count)
+	},
+}
+
+// parseNameRule parses a --rule value such as "kebab-case dirs".
+func parseNameRule(spec string) (nameRule, error) {
+	rule := nameRule{spec: spec, dirs: true, files: true}
+	words := strings.Fields(spec)
+	if len(words) > 1 {
+		switch words[len(words)-1] {
+		case "dirs":
+			rule.files = false
+			words = words[:len(words)-1]
+		case "files":
+			rule.dirs = false
+			words = words[:len(words)-1]
+		}
+	}
+	invalid := fmt.Errorf("invalid --rule %q (see wintree lint-names --help)", spec)
+	if len(words) == 0 {
+		return rule, invalid
+	}
+
+	switch {
+	case len(words) == 1 && caseStyles[words[0]] != nil:
+		style, re := words[0], caseStyles[words[0]]
+		rule.check = func(name string) string {
+			stem, _, _ := strings.Cut(strings.TrimLeft(name, "."), ".")
+			if stem != "" && !re.MatchString(stem) {
+				return "not " + style
+			}
+			return ""
+		}
+	case len(words) == 1 && words[0] == "lowercase":
+		rule.check = func(name string) string {
+			if name != strings.ToLower(name) {
+				return "not lowercase"
+			}
+			return ""
+		}
+	case len(words) == 2 && words[0] == "no" && words[1] == "spaces":
+		rule.check = func(name string) string {
+			if strings.ContainsAny(name, " \t") {
+				return "contains spaces"
+			}
+			return ""
+		}
+	case len(words) == 2 && words[0] == "no" && words[1] == "non-ascii":
+		rule.check = func(name string) string {
+			for _, r := range name {
+				if r >= utf8.RuneSelf {
+					return fmt.Sprintf("contains non-ASCII character %q", r)
+				}
+			}
+			return ""
+		}
+	case len(words) == 3 && words[0] == "no" && words[1] == "chars":
+		forbidden := words[2]
+		rule.check = func(name string) string {
+			if i := strings.IndexAny(name, forbidden); i >= 0 {
+				r, _ := utf8.DecodeRuneInString(name[i:])
+				return fmt.Sprintf("contains forbidden character %q", r)
+			}
+			return ""
+		}
+	case len(words) == 3 && words[0] == "max" && words[1] == "length":
+		limit, err := strconv.Atoi(words[2])
+		if err != nil || limit <= 0 {
+			return rule, invalid
+		}
+		rule.check = func(name string) string {
+			if length := utf8.RuneCountInString(name); length > limit {
+				return fmt.Sprintf("%d characters long (max %d)", length, limit)
+			}
+			return ""
+		}
+	default:
+		return rule, invalid
+	}
+	return rule, nil
+}
+
+// nameProblems returns how a name breaks each rule that applies to its kind
+// of entry.
+func nameProblems(name string, isDir bool, rules []nameRule) []string {
+	var problems []string
+	for _, rule := range rules {
+		if (isDir && !rule.dirs) || (!isDir && !rule.files) {
+			continue
+		}
+		if problem := rule.check(name); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+func init() {
+	addFilterFlags(lintNamesCmd.Flags())
+	lintNamesCmd.Flags().StringArrayVarP(&nameRuleSpecs, "rule", "", nil, `Naming rule to enforce, such as "kebab-case dirs" or "no spaces"; can be used multiple times`)
+	rootCmd.AddCommand(lintNamesCmd)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNameProblems(t *testing.T) {
+	var rules []nameRule
+	for _, spec := range []string{"kebab-case dirs", "no spaces", "max length 12 files", "no chars #%"} {
+		rule, err := parseNameRule(spec)
+		if err != nil {
+			t.Fatalf("parseNameRule(%q) error = %v", spec, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	tests := []struct {
+		name     string
+		isDir    bool
+		expected []string
+	}{
+		{"my-component", true, nil},
+		{".github", true, nil},
+		{"MyComponent", true, []string{"not kebab-case"}},
+		{"Widget.tsx", false, nil},
+		{"bad name", true, []string{"not kebab-case", "contains spaces"}},
+		{"a-very-long-name.go", false, []string{"19 characters long (max 12)"}},
+		{"a-very-long-name", true, nil},
+		{"50%.txt", false, []string{"contains forbidden character '%'"}},
+	}
+
+	for _, tt := range tests {
+		if problems := nameProblems(tt.name, tt.isDir, rules); !reflect.DeepEqual(problems, tt.expected) {
+			t.Errorf("nameProblems(%q) = %q, expected %q", tt.name, problems, tt.expected)
+		}
+	}
+}
+
+func TestParseNameRule(t *testing.T) {
+	styles := map[string][2]string{
+		"snake_case":         {"my_module.py", "myModule.py"},
+		"camelCase":          {"myModule.js", "my-module.js"},
+		"PascalCase":         {"Widget.tsx", "widget.tsx"},
+		"lowercase":          {"readme.md", "README.md"},
+		"no non-ascii files": {"cafe.txt", "café.txt"},
+	}
+	for spec, names := range styles {
+		rule, err := parseNameRule(spec)
+		if err != nil {
+			t.Fatalf("parseNameRule(%q) error = %v", spec, err)
+		}
+		if problem := rule.check(names[0]); problem != "" {
+			t.Errorf("%s: %q reported as %q", spec, names[0], problem)
+		}
+		if problem := rule.check(names[1]); problem == "" {
+			t.Errorf("%s: %q not reported", spec, names[1])
+		}
+	}
+
+	for _, spec := range []string{"", "dirs", "Kebab-Case", "max length", "max length -1", "no tabs"} {
+		if _, err := parseNameRule(spec); err == nil {
+			t.Errorf("parseNameRule(%q) expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// narrativeRenderer describes the tree in plain sentences, one per directory,
+// indented by depth, for screen readers, which read box-drawing characters
+// aloud or skip them.
+type narrativeRenderer struct{}
+
+func (narrativeRenderer) render(root *tree.Node) (string, error) {
+	var output strings.Builder
+	var describe func(node *tree.Node, depth int)
+	describe = func(node *tree.Node, depth int) {
+		output.WriteString(strings.Repeat("  ", depth) + narrativeSentence(node) + "\n")
+		for _, child := range node.Children {
+			if child.IsDir {
+				describe(child, depth+1)
+			}
+		}
+	}
+	describe(root, 0)
+
+	if !noReport {
+		output.WriteString("\nIn total, " + treeReport(root) + ".\n")
+	}
+	return output.String(), nil
+}
+
+// narrativeSentence describes what a directory holds, such as "src contains
+// 1 directory, lib, and 2 files, main.go and util.go."
+func narrativeSentence(node *tree.Node) string {
+	var dirs, files []string
+	fileCount := 0
+	for _, child := range node.Children {
+		switch {
+		case child.IsDir:
+			dirs = append(dirs, child.Name)
+		case child.Count > 0:
+			// A --group-ext line stands for several files
+			files = append(files, child.Name)
+			fileCount += child.Count
+		default:
+			files = append(files, child.Name)
+			fileCount++
+		}
+	}
+
+	var parts []string
+	if len(dirs) > 0 {
+		parts = append(parts, plural(len(dirs), "directory", "directories")+", "+joinNarrative(dirs))
+	}
+	if len(files) > 0 {
+		parts = append(parts, plural(fileCount, "file", "files")+", "+joinNarrative(files))
+	}
+	if len(parts) == 0 {
+		return node.Name + " has no entries listed."
+	}
+	return node.Name + " contains " + strings.Join(parts, ", and ") + "."
+}
+
+// joinNarrative joins names as an English list: "a", "a and b", or "a, b,
+// and c".
+func joinNarrative(names []string) string {
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestNarrativeRenderer(t *testing.T) {
+	root := &tree.Node{Name: "project", IsDir: true, Children: []*tree.Node{
+		{Name: "README.md"},
+		{Name: "docs", IsDir: true},
+		{Name: "go.mod"},
+		{Name: "main.go"},
+		{Name: "src", IsDir: true, Children: []*tree.Node{
+			{Name: "lib", IsDir: true, Children: []*tree.Node{{Name: "util.go"}}},
+			{Name: ".go (3)", Count: 3},
+		}},
+	}}
+
+	originalReport := noReport
+	defer func() { noReport = originalReport }()
+	noReport = false
+
+	expected := "project contains 2 directories, docs and src, and 3 files, README.md, go.mod, and main.go.\n" +
+		"  docs has no entries listed.\n" +
+		"  src contains 1 directory, lib, and 3 files, .go (3).\n" +
+		"    lib contains 1 file, util.go.\n" +
+		"\nIn total, 3 directories, 7 files.\n"
+	if output, _ := (narrativeRenderer{}).render(root); output != expected {
+		t.Errorf("render() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
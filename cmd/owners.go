@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// codeownersLocations are where GitHub and GitLab look for a CODEOWNERS file,
+// relative to the repository root, in the order they look.
+var codeownersLocations = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// ownerRule is one line of a CODEOWNERS file.
+type ownerRule struct {
+	re *regexp.Regexp
+	// dirOnly is set for patterns ending in "/", which match directories
+	// and their contents but not files of that name
+	dirOnly bool
+	owners  []string
+}
+
+// codeowners is a parsed CODEOWNERS file. Its patterns are relative to root,
+// the repository root.
+type codeowners struct {
+	root  string
+	rules []ownerRule
+}
+
+// codeownersCache maps each directory looked up by codeownersFor to the
+// CODEOWNERS that applies to it, or nil if there is none.
+var codeownersCache = make(map[string]*codeowners)
+
+// ownersAnnotation marks a directory with its owners, as "[@org/team]". Files
+// are only marked where their owners differ from their directory's, so
+// ownership boundaries stand out. Unowned entries are not marked.
+func ownersAnnotation(path string) string {
+	info, err := lstatCached(path)
+	if err != nil {
+		return ""
+	}
+	file := codeownersFor(filepath.Dir(path))
+	if info.IsDir() {
+		file = codeownersFor(path)
+	}
+	if file == nil {
+		return ""
+	}
+
+	owners := file.owners(path, info.IsDir())
+	if len(owners) == 0 {
+		return ""
+	}
+	if !info.IsDir() && slices.Equal(owners, file.owners(filepath.Dir(path), true)) {
+		return ""
+	}
+	return "[" + strings.Join(owners, ", ") + "]"
+}
+
+// codeownersFor returns the CODEOWNERS that applies to dir: the one of the
+// repository containing it, found from the directory holding .git. Outside a
+// repository, the CODEOWNERS of the topmost directory that has one applies.
+// It returns nil if there is none.
+func codeownersFor(dir string) *codeowners {
+	if file, ok := codeownersCache[dir]; ok {
+		return file
+	}
+
+	var file *codeowners
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+		if file = loadCodeowners(dir); file == nil {
+			// A repository without one is unowned, whatever its parents say
+			file = &codeowners{root: dir}
+		}
+	} else {
+		if parent := filepath.Dir(dir); parent != dir {
+			file = codeownersFor(parent)
+		}
+		if file == nil {
+			file = loadCodeowners(dir)
+		}
+	}
+
+	codeownersCache[dir] = file
+	return file
+}
+
+// loadCodeowners reads the CODEOWNERS of a repository rooted at dir, or
+// returns nil if it has none.
+func loadCodeowners(dir string) *codeowners {
+	for _, location := range codeownersLocations {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(location)))
+		if err == nil {
+			return &codeowners{root: dir, rules: parseCodeowners(string(data))}
+		}
+	}
+	return nil
+}
+
+// parseCodeowners parses the rules of a CODEOWNERS file. Comments, GitLab
+// section headers, and invalid patterns are skipped.
+func parseCodeowners(text string) []ownerRule {
+	var rules []ownerRule
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") ||
+			strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+
+		var owners []string
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			owners = append(owners, owner)
+		}
+
+		pattern := fields[0]
+		re, err := regexp.Compile(codeownersRegexp(pattern))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, ownerRule{re: re, dirOnly: strings.HasSuffix(pattern, "/"), owners: owners})
+	}
+	return rules
+}
+
+// codeownersRegexp translates a CODEOWNERS pattern, which follows .gitignore
+// rules, into a regular expression over slash-separated relative paths. A
+// pattern also matches everything beneath the directories it matches, which
+// the final group captures.
+func codeownersRegexp(pattern string) string {
+	// A slash anywhere but at the end anchors the pattern to the root
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var re strings.Builder
+	re.WriteString("^")
+	if !anchored {
+		re.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**"):
+			re.WriteString("(?:/.*)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			re.WriteString("[^/]*")
+		case pattern[i] == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	re.WriteString("(/.*)?$")
+	return re.String()
+}
+
+// owners returns the owners of path: those of the last rule that matches it.
+func (c *codeowners) owners(path string, isDir bool) []string {
+	relPath, err := filepath.Rel(c.root, path)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		rule := c.rules[i]
+		match := rule.re.FindStringSubmatch(relPath)
+		if match == nil || (rule.dirOnly && !isDir && match[1] == "") {
+			continue
+		}
+		return rule.owners
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeownersOwners(t *testing.T) {
+	rules := parseCodeowners(`# Default owners
+*                 @org/core
+/api/             @org/backend
+*_test.go         @org/qa
+docs/             @org/docs @alice  # writers
+build/**/out      @org/release
+/scripts/*.sh
+
+[Frontend]
+web/              @org/frontend
+`)
+	file := &codeowners{root: "/repo", rules: rules}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected []string
+	}{
+		{"/repo", true, []string{"@org/core"}},
+		{"/repo/api", true, []string{"@org/backend"}},
+		{"/repo/api/v1/handler.go", false, []string{"@org/backend"}},
+		{"/repo/api/v1/handler_test.go", false, []string{"@org/qa"}},
+		{"/repo/sub/api/main.go", false, []string{"@org/core"}},
+		{"/repo/docs/guide.md", false, []string{"@org/docs", "@alice"}},
+		{"/repo/pkg/docs/guide.md", false, []string{"@org/docs", "@alice"}},
+		{"/repo/docs", false, []string{"@org/core"}},
+		{"/repo/build/linux/amd64/out", true, []string{"@org/release"}},
+		{"/repo/build/out/bin", false, []string{"@org/release"}},
+		{"/repo/scripts/deploy.sh", false, nil},
+		{"/repo/scripts/lib/deploy.sh", false, []string{"@org/core"}},
+		{"/repo/web/app.js", false, []string{"@org/frontend"}},
+		{"/elsewhere/main.go", false, nil},
+	}
+	for _, tt := range tests {
+		path := filepath.FromSlash(tt.path)
+		if owners := file.owners(path, tt.isDir); !reflect.DeepEqual(owners, tt.expected) {
+			t.Errorf("owners(%s) = %v, expected %v", tt.path, owners, tt.expected)
+		}
+	}
+}
+
+func TestOwnersAnnotation(t *testing.T) {
+	tempDir := t.TempDir()
+	for file, content := range map[string]string{
+		".github/CODEOWNERS": "* @org/core\n/api/ @org/backend\n*_test.go @org/qa\n",
+		"api/main.go":        "",
+		"api/main_test.go":   "",
+		"web/app.js":         "",
+	} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	clear(codeownersCache)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"", "[@org/core]"},
+		{"api", "[@org/backend]"},
+		{"api/main.go", ""},
+		{"api/main_test.go", "[@org/qa]"},
+		{"web/app.js", ""},
+	}
+	for _, tt := range tests {
+		if annotation := ownersAnnotation(filepath.Join(tempDir, tt.path)); annotation != tt.expected {
+			t.Errorf("ownersAnnotation(%q) = %q, expected %q", tt.path, annotation, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// pageOutput shows output through the pager when stdout is a terminal and the
+// output is taller than it, as git does. It reports whether the pager was
+// used; if not, the caller should print the output itself.
+func pageOutput(output string) bool {
+	pager := openPager(strings.Count(output, "\n"))
+	if pager == nil {
+		return false
+	}
+	_, _ = io.WriteString(pager, output)
+	_ = pager.Close()
+	return true
+}
+
+// openPager starts the pager for output of the given number of lines when
+// stdout is a terminal shorter than that, and returns its input, to be closed
+// once everything is written. It returns nil when the output should be
+// printed directly.
+func openPager(lines int) io.WriteCloser {
+	if noPager {
+		return nil
+	}
+
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return nil
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || lines < height {
+		return nil
+	}
+
+	pager := pagerCommand()
+	if pager == nil {
+		return nil
+	}
+
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Let less keep short output on screen and pass colors through, like git
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	input, err := cmd.StdinPipe()
+	if err != nil {
+		return nil
+	}
+	if err := cmd.Start(); err != nil {
+		return nil
+	}
+	return &pagerInput{input: input, cmd: cmd}
+}
+
+// pagerInput writes to a running pager. Quitting the pager early is not an
+// error, so whatever is written after that is dropped.
+type pagerInput struct {
+	input io.WriteCloser
+	cmd   *exec.Cmd
+	quit  bool
+}
+
+func (p *pagerInput) Write(b []byte) (int, error) {
+	if !p.quit {
+		if _, err := p.input.Write(b); err != nil {
+			p.quit = true
+		}
+	}
+	return len(b), nil
+}
+
+// Close ends the pager's input and waits for it to exit.
+func (p *pagerInput) Close() error {
+	p.input.Close()
+	// The pager exits non-zero when quit early, which is not an error
+	_ = p.cmd.Wait()
+	return nil
+}
+
+// pagerCommand returns the pager from $PAGER, defaulting to less (more on
+// Windows). An empty $PAGER or "cat" disables paging.
+func pagerCommand() []string {
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = "less"
+		if runtime.GOOS == "windows" {
+			pager = "more"
+		}
+	}
+
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return nil
+	}
+	return fields
+}
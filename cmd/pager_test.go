@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		pager    string
+		expected []string
+	}{
+		{"custom pager with arguments", "less -S", []string{"less", "-S"}},
+		{"empty disables paging", "", nil},
+		{"cat disables paging", "cat", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAGER", tt.pager)
+			if result := pagerCommand(); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("pagerCommand() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/cobra"
+)
+
+// parseFormat is the --format the parsed tree is re-rendered in.
+var parseFormat string
+
+// treeEntry is an entry of a tree that need not exist on disk, identified by
+// its slash-separated path relative to the root.
+type treeEntry struct {
+	relPath string
+	isDir   bool
+}
+
+// Indentation and connectors drawn by wintree and GNU tree, in both its
+// Unicode and --charset ascii styles. GNU tree pads with non-breaking spaces,
+// which are normalized to spaces before matching.
+var (
+	treeIndents    = []string{"│   ", "|   ", "    "}
+	treeConnectors = []string{"├── ", "└── ", "|-- ", "`-- ", "+-- ", `\-- `}
+)
+
+// treeSummaryLine matches the "3 directories, 5 files" line GNU tree ends with.
+var treeSummaryLine = regexp.MustCompile(`^\d+ director(y|ies)`)
+
+var parseCmd = &cobra.Command{
+	Use:   "parse FILE",
+	Short: "Read tree-formatted text back into a tree.",
+	Long: `Parse a tree drawn by wintree or GNU tree, such as one pasted into
+documentation or an issue, and render it again. Use "-" to read from stdin.
+
+Entries with children, or whose names end in "/", are directories; all others
+are files. Symlink targets ("name -> target") and GNU tree's closing summary
+are ignored.
+
+  wintree parse layout.txt --format script > scaffold.sh`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch parseFormat {
+		case "tree", "json", "markdown", "markdown-list", "narrative", "script", "powershell":
+		case "dot":
+			if err := validateDotFlags(); err != nil {
+				return asFlagError(err)
+			}
+		default:
+			return flagErrorf("invalid --format %q (use tree, json, markdown, markdown-list, narrative, script, powershell, or dot)", parseFormat)
+		}
+
+		var input io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", args[0], err)
+			}
+			defer f.Close()
+			input = f
+		}
+
+		label, entries, err := parseTree(input)
+		if err != nil {
+			return err
+		}
+
+		output, err := rendererFor(parseFormat).render(entriesTree(label, entries))
+		if err != nil {
+			return err
+		}
+		return writeOutput(output)
+	},
+}
+
+// parseTree reads tree-formatted text and returns the label of its root line
+// and its entries in the order they appear.
+func parseTree(r io.Reader) (string, []treeEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	label := ""
+	var entries []treeEntry
+	index := make(map[string]int)
+	var stack []string
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if label == "" {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if _, _, ok := splitTreeLine(line); ok {
+				return "", nil, fmt.Errorf("line %d: expected the root of the tree, got an entry", lineNum)
+			}
+			label = strings.TrimSpace(line)
+			continue
+		}
+
+		// GNU tree separates its summary from the tree with a blank line
+		if strings.TrimSpace(line) == "" || treeSummaryLine.MatchString(line) {
+			break
+		}
+
+		depth, name, ok := splitTreeLine(line)
+		if !ok {
+			return "", nil, fmt.Errorf("line %d: not a tree entry: %q", lineNum, line)
+		}
+		if depth > len(stack) {
+			return "", nil, fmt.Errorf("line %d: entry is indented deeper than its parent", lineNum)
+		}
+
+		name, _, _ = strings.Cut(name, " -> ")
+		isDir := strings.HasSuffix(name, "/")
+		name = strings.TrimRight(name, "/")
+		if name == "" {
+			return "", nil, fmt.Errorf("line %d: entry has no name", lineNum)
+		}
+
+		stack = append(stack[:depth], name)
+		if depth > 0 {
+			entries[index[strings.Join(stack[:depth], "/")]].isDir = true
+		}
+
+		relPath := strings.Join(stack, "/")
+		if i, seen := index[relPath]; seen {
+			entries[i].isDir = entries[i].isDir || isDir
+			continue
+		}
+		index[relPath] = len(entries)
+		entries = append(entries, treeEntry{relPath: relPath, isDir: isDir})
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to read tree: %w", err)
+	}
+	if label == "" {
+		return "", nil, fmt.Errorf("input contains no tree")
+	}
+	return label, entries, nil
+}
+
+// entriesTree arranges parsed entries under a root node called label, keeping
+// the order they were read in. Parents always precede their children.
+func entriesTree(label string, entries []treeEntry) *tree.Node {
+	root := &tree.Node{Name: label, IsDir: true}
+	nodes := map[string]*tree.Node{".": root}
+	for _, entry := range entries {
+		name := entry.relPath[strings.LastIndex(entry.relPath, "/")+1:]
+		node := &tree.Node{Name: name, IsDir: entry.isDir}
+		nodes[entry.relPath] = node
+		parent := nodes[pathDir(entry.relPath)]
+		parent.Children = append(parent.Children, node)
+	}
+	return root
+}
+
+// splitTreeLine splits a line of a drawn tree into its depth below the root
+// and the entry name. It reports false if the line has no connector.
+func splitTreeLine(line string) (int, string, bool) {
+	rest := []rune(line)
+	for depth := 0; len(rest) >= 4; depth++ {
+		chunk := strings.ReplaceAll(string(rest[:4]), "\u00a0", " ")
+		for _, connector := range treeConnectors {
+			if chunk == connector {
+				return depth, strings.TrimSpace(string(rest[4:])), true
+			}
+		}
+
+		indented := false
+		for _, indent := range treeIndents {
+			if chunk == indent {
+				indented = true
+				break
+			}
+		}
+		if !indented {
+			return 0, "", false
+		}
+		rest = rest[4:]
+	}
+	return 0, "", false
+}
+
+func init() {
+	addOutputFlags(parseCmd.Flags())
+	addDotFlags(parseCmd.Flags())
+	parseCmd.Flags().BoolVarP(&noReport, "no-report", "", false, "Omit the summary of directory and file counts after the tree")
+	parseCmd.Flags().StringVarP(&parseFormat, "format", "", "tree", "Output format: tree, json (nested objects), markdown (fenced code block), markdown-list (nested bullets), narrative (plain sentences for screen readers), script (POSIX shell recreating the skeleton), powershell, or dot (Graphviz graph)")
+	rootCmd.AddCommand(parseCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		label    string
+		expected []treeEntry
+	}{
+		{
+			name: "wintree output",
+			input: "project\n" +
+				"└── src\n" +
+				"    ├── lib\n" +
+				"    │   └── util.go\n" +
+				"    └── main.go\n",
+			label: "project",
+			expected: []treeEntry{
+				{relPath: "src", isDir: true},
+				{relPath: "src/lib", isDir: true},
+				{relPath: "src/lib/util.go"},
+				{relPath: "src/main.go"},
+			},
+		},
+		{
+			name: "GNU tree with non-breaking spaces and summary",
+			input: ".\n" +
+				"├── docs/\n" +
+				"├── src\n" +
+				"│\u00a0\u00a0 └── main.go\n" +
+				"└── link -> src/main.go\n" +
+				"\n" +
+				"2 directories, 2 files\n",
+			label: ".",
+			expected: []treeEntry{
+				{relPath: "docs", isDir: true},
+				{relPath: "src", isDir: true},
+				{relPath: "src/main.go"},
+				{relPath: "link"},
+			},
+		},
+		{
+			name: "GNU tree ascii charset",
+			input: ".\r\n" +
+				"|-- a\r\n" +
+				"|   `-- b\r\n" +
+				"`-- c\r\n",
+			label: ".",
+			expected: []treeEntry{
+				{relPath: "a", isDir: true},
+				{relPath: "a/b"},
+				{relPath: "c"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, entries, err := parseTree(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("parseTree() error = %v", err)
+			}
+			if label != tt.label {
+				t.Errorf("label = %q, expected %q", label, tt.label)
+			}
+			if !reflect.DeepEqual(entries, tt.expected) {
+				t.Errorf("entries = %+v, expected %+v", entries, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseTreeErrors(t *testing.T) {
+	inputs := []string{
+		"",
+		"└── orphan\n",
+		"root\n└── a\n        └── too-deep\n",
+		"root\nnot a tree line\n",
+	}
+	for _, input := range inputs {
+		if _, _, err := parseTree(strings.NewReader(input)); err == nil {
+			t.Errorf("parseTree(%q) expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/cobra"
+)
+
+// pathsEnv is the environment variable whose directory list paths renders.
+var pathsEnv string
+
+var pathsCmd = &cobra.Command{
+	Use:   "paths [-]",
+	Short: "Show the contents of each directory in PATH or another path list.",
+	Long: `Render each directory of a path list, such as $PATH, with its contents, in
+the order the list gives them. Entries that do not exist, are not
+directories, or repeat an earlier entry are marked, which helps find why a
+command resolves to the wrong binary or is not found at all.
+
+The list is read from the environment variable named by --env, or from stdin
+with "-", where entries may be separated by the path list separator (":" on
+Unix, ";" on Windows) or by newlines.
+
+  wintree paths
+  wintree paths --env GOPATH -d 2
+  wintree paths --type x
+  echo "$LD_LIBRARY_PATH" | wintree paths -`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		var entries []string
+		label := "$" + pathsEnv
+		switch {
+		case len(args) == 1 && args[0] == "-":
+			var err error
+			if entries, err = readPathList(os.Stdin); err != nil {
+				return fmt.Errorf("failed to read path list: %w", err)
+			}
+			label = "stdin"
+		case len(args) == 1:
+			return fmt.Errorf("unexpected argument %q (use - to read the list from stdin)", args[0])
+		default:
+			value, ok := os.LookupEnv(pathsEnv)
+			if !ok {
+				return fmt.Errorf("environment variable %s is not set", pathsEnv)
+			}
+			entries = splitPathList(value)
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("%s has no entries", label)
+		}
+
+		root, err := buildPathsTree(label, entries, processFilters(excludePatterns, includePatterns))
+		if err != nil {
+			return err
+		}
+		output, err := textRenderer{}.render(root)
+		if err != nil {
+			return err
+		}
+		return writeOutput(output)
+	},
+}
+
+// splitPathList splits a path list on the platform's separator, dropping
+// empty entries.
+func splitPathList(list string) []string {
+	var entries []string
+	for _, entry := range filepath.SplitList(list) {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// readPathList reads a path list whose entries are separated by the platform's
+// separator, newlines, or both.
+func readPathList(r io.Reader) ([]string, error) {
+	var entries []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		entries = append(entries, splitPathList(scanner.Text())...)
+	}
+	return entries, scanner.Err()
+}
+
+// buildPathsTree arranges the contents of each entry of a path list under a
+// node called label. Entries are labelled as written; those that cannot be
+// listed are shown with the reason instead of contents.
+func buildPathsTree(label string, entries []string, filters filter) (*tree.Node, error) {
+	top := &tree.Node{Name: label, IsDir: true}
+	seen := make(map[string]int)
+
+	for i, entry := range entries {
+		name := entry
+		if anonymizing() {
+			name = anonymizePath(entry)
+		}
+
+		dir, err := filepath.Abs(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %w", entry, err)
+		}
+		if first, ok := seen[dir]; ok {
+			top.Children = append(top.Children, &tree.Node{Name: fmt.Sprintf("%s [duplicate of entry %d]", name, first), IsDir: true})
+			continue
+		}
+		seen[dir] = i + 1
+
+		info, err := os.Stat(dir)
+		switch {
+		case os.IsNotExist(err):
+			top.Children = append(top.Children, &tree.Node{Name: name + " [missing]", IsDir: true})
+			continue
+		case err != nil:
+			top.Children = append(top.Children, &tree.Node{Name: name + " [unreadable]", IsDir: true})
+			continue
+		case !info.IsDir():
+			top.Children = append(top.Children, &tree.Node{Name: name + " [not a directory]"})
+			continue
+		}
+
+		matchingFiles, err := findMatchingFiles(dir, filters)
+		if err != nil {
+			return nil, fmt.Errorf("error finding files in %s: %w", entry, err)
+		}
+		subtree := buildTree(dir, matchingFiles)
+		subtree.Name = name
+		top.Children = append(top.Children, subtree)
+	}
+	return top, nil
+}
+
+func init() {
+	addFilterFlags(pathsCmd.Flags())
+	addOutputFlags(pathsCmd.Flags())
+	pathsCmd.Flags().StringVarP(&pathsEnv, "env", "", "PATH", "Environment variable holding the path list")
+	rootCmd.AddCommand(pathsCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPathList(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	input := "/usr/bin" + sep + sep + "/bin\n\n  /opt/tools  \n"
+
+	entries, err := readPathList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readPathList() error = %v", err)
+	}
+	expected := []string{"/usr/bin", "/bin", "/opt/tools"}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("readPathList() = %v, expected %v", entries, expected)
+	}
+}
+
+func TestBuildPathsTree(t *testing.T) {
+	tempDir := t.TempDir()
+	bin := filepath.Join(tempDir, "bin")
+	if err := os.Mkdir(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(bin, "tool"), filepath.Join(tempDir, "notes.txt")} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(tempDir, "missing")
+	notDir := filepath.Join(tempDir, "notes.txt")
+
+	root, err := buildPathsTree("$PATH", []string{bin, missing, notDir, bin + string(filepath.Separator)}, processFilters(nil, nil))
+	if err != nil {
+		t.Fatalf("buildPathsTree() error = %v", err)
+	}
+
+	output := formatTreeRows(treeRows(root))
+	expected := "$PATH\n" +
+		"├── " + bin + "\n" +
+		"│   └── tool\n" +
+		"├── " + missing + " [missing]\n" +
+		"├── " + notDir + " [not a directory]\n" +
+		"└── " + bin + string(filepath.Separator) + " [duplicate of entry 1]\n"
+	if output != expected {
+		t.Errorf("buildPathsTree() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// patternStats reports how many entries each rule matched.
+var patternStats bool
+
+// patternHit is one rule of a --pattern-stats report.
+type patternHit struct {
+	rule string
+	hits int
+}
+
+// patternHits counts the entries each exclude, smart default, .wintreeignore,
+// and include rule of the last walk matched, listing unused rules with none.
+// An entry is credited to the first rule that matched it, and an excluded
+// directory counts once, as nothing beneath it is read.
+func patternHits(root string, paths []string, f filter) []patternHit {
+	var hits []patternHit
+	add := func(rule string, n int) {
+		if !slices.ContainsFunc(hits, func(hit patternHit) bool { return hit.rule == rule }) {
+			hits = append(hits, patternHit{rule, n})
+		}
+	}
+
+	for _, pattern := range f.excludeGlobs {
+		rule := excludeRuleLabel(pattern, f.excludeGlobs, nil)
+		add(rule, lastWalk.excluded[rule])
+	}
+	if !noIgnoreFile {
+		for _, pattern := range readIgnoreFile(root) {
+			rule := excludeRuleLabel(pattern, f.excludeGlobs, nil)
+			add(rule, lastWalk.excluded[rule])
+		}
+		if n := lastWalk.excluded[ignoreFileName]; n > 0 {
+			add("nested "+ignoreFileName+" files", n)
+		}
+	}
+	for _, re := range f.excludeRegexps {
+		rule := "--exclude-regex " + re.String()
+		add(rule, lastWalk.excluded[rule])
+	}
+
+	included := includeHits(root, paths, f)
+	for _, pattern := range f.includeGlobs {
+		add("--include "+pattern, included["--include "+pattern])
+	}
+	for _, re := range f.includeRegexps {
+		add("--include-regex "+re.String(), included["--include-regex "+re.String()])
+	}
+	return hits
+}
+
+// includeHits credits every matched file to the include rule that listed it:
+// a directory whose name matches an include glob, then an include glob, then an include regex.
+func includeHits(root string, paths []string, f filter) map[string]int {
+	hits := make(map[string]int)
+	if !f.including() {
+		return hits
+	}
+
+	for _, path := range paths {
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		if rule := includeRule(filepath.ToSlash(relPath), f); rule != "" {
+			hits[rule]++
+		}
+	}
+	return hits
+}
+
+// includeRule returns the first include rule that matches a file, or "".
+func includeRule(relPath string, f filter) string {
+	parts := strings.Split(relPath, "/")
+	for _, pattern := range f.includeGlobs {
+		for _, dir := range parts[:len(parts)-1] {
+			if tree.MatchDirName(pattern, dir) {
+				return "--include " + pattern
+			}
+		}
+	}
+	for _, pattern := range f.includeGlobs {
+		if matched, _ := filepath.Match(pattern, parts[len(parts)-1]); matched {
+			return "--include " + pattern
+		}
+	}
+	for _, re := range f.includeRegexps {
+		if re.MatchString(relPath) {
+			return "--include-regex " + re.String()
+		}
+	}
+	return ""
+}
+
+// patternReport formats the hits as aligned lines, marking unused rules so
+// they can be pruned.
+func patternReport(hits []patternHit) string {
+	if len(hits) == 0 {
+		return "No include or exclude patterns were used.\n"
+	}
+
+	width := 0
+	for _, hit := range hits {
+		width = max(width, len(hit.rule))
+	}
+	var report strings.Builder
+	report.WriteString("Pattern hits:\n")
+	for _, hit := range hits {
+		line := fmt.Sprintf("  %-*s %6d", width, hit.rule, hit.hits)
+		if hit.hits == 0 {
+			line += "  (unused)"
+		}
+		report.WriteString(line + "\n")
+	}
+	return report.String()
+}
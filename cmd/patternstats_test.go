@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPatternHits(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, file := range []string{"main.go", "debug.log", "vendor/lib.go", "docs/guide.md", "docs/notes.txt", "src/app.go"} {
+		path := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ignoreFileName), []byte("*.bak\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalDepth, originalSmart := maxDepth, smartExcludes
+	defer func() { maxDepth, smartExcludes = originalDepth, originalSmart }()
+	maxDepth = -1
+	smartExcludes = []string{"vendor"}
+
+	filters := processFilters([]string{"*.log", "vendor", "build"}, []string{"docs", "*.go", "*.rs"})
+	paths, err := findMatchingFiles(tempDir, filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Files in an included directory are credited to it, not to a glob
+	expected := []patternHit{
+		{"--exclude *.log", 1},
+		{"--smart-defaults vendor", 1},
+		{"--exclude build", 0},
+		{".wintreeignore *.bak", 0},
+		{"--include docs", 2},
+		{"--include *.go", 2},
+		{"--include *.rs", 0},
+	}
+	if hits := patternHits(tempDir, paths, filters); !reflect.DeepEqual(hits, expected) {
+		t.Errorf("patternHits() = %v, expected %v", hits, expected)
+	}
+
+	report := patternReport(expected[:3])
+	expectedReport := "Pattern hits:\n" +
+		"  --exclude *.log              1\n" +
+		"  --smart-defaults vendor      1\n" +
+		"  --exclude build              0  (unused)\n"
+	if report != expectedReport {
+		t.Errorf("patternReport() = %q, expected %q", report, expectedReport)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"io/fs"
+	"strings"
+	"unicode/utf8"
+)
+
+// permsPrefixes returns the --perms columns of every row, left-aligned and
+// padded to the same width so that the tree starts in one column.
+func permsPrefixes(rows []treeRow) []string {
+	columns := make([][]string, len(rows))
+	widths := make([]int, 3)
+	for i, row := range rows {
+		columns[i] = permsColumns(row)
+		for j, column := range columns[i] {
+			widths[j] = max(widths[j], utf8.RuneCountInString(column))
+		}
+	}
+
+	prefixes := make([]string, len(rows))
+	for i := range rows {
+		for j, column := range columns[i] {
+			prefixes[i] += padRight(column, widths[j]) + columnGap
+		}
+	}
+	return prefixes
+}
+
+// permsColumns returns the left-aligned permissions, owner, and group shown
+// before a node with --perms, or empty strings for virtual entries that have
+// nothing on disk.
+func permsColumns(row treeRow) []string {
+	if row.node == nil || row.node.Info == nil || row.path == "" {
+		return []string{"", "", ""}
+	}
+	owner, group := fileOwner(row.path, row.node.Info)
+	return []string{fileModeString(row.node.Info), owner, group}
+}
+
+// unixModeString formats mode as ls -l does, e.g. drwxr-xr-x, with s and t
+// marking the setuid, setgid, and sticky bits.
+func unixModeString(mode fs.FileMode) string {
+	var b strings.Builder
+	switch {
+	case mode&fs.ModeDir != 0:
+		b.WriteByte('d')
+	case mode&fs.ModeSymlink != 0:
+		b.WriteByte('l')
+	case mode&fs.ModeNamedPipe != 0:
+		b.WriteByte('p')
+	case mode&fs.ModeSocket != 0:
+		b.WriteByte('s')
+	case mode&fs.ModeCharDevice != 0:
+		b.WriteByte('c')
+	case mode&fs.ModeDevice != 0:
+		b.WriteByte('b')
+	default:
+		b.WriteByte('-')
+	}
+
+	special := []fs.FileMode{fs.ModeSetuid, fs.ModeSetgid, fs.ModeSticky}
+	for i, who := 0, mode.Perm(); i < 3; i++ {
+		bits := who >> (6 - 3*i) & 7
+		b.WriteByte("-r"[bits>>2])
+		b.WriteByte("-w"[bits>>1&1])
+
+		execute := "-x"
+		if mode&special[i] != 0 {
+			execute = "Ss"
+			if i == 2 {
+				execute = "Tt"
+			}
+		}
+		b.WriteByte(execute[bits&1])
+	}
+	return b.String()
+}
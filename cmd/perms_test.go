@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestUnixModeString(t *testing.T) {
+	tests := map[fs.FileMode]string{
+		0644:                               "-rw-r--r--",
+		fs.ModeDir | 0755:                  "drwxr-xr-x",
+		fs.ModeSymlink | 0777:              "lrwxrwxrwx",
+		fs.ModeSetuid | 0755:               "-rwsr-xr-x",
+		fs.ModeSetgid | 0640:               "-rw-r-S---",
+		fs.ModeDir | fs.ModeSticky | 01777: "drwxrwxrwt",
+		fs.ModeNamedPipe | 0600:            "prw-------",
+	}
+	for mode, expected := range tests {
+		if result := unixModeString(mode); result != expected {
+			t.Errorf("unixModeString(%v) = %q, expected %q", mode, result, expected)
+		}
+	}
+}
+
+func TestPermsPrefixes(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "file.go")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Virtual entries have nothing on disk, but the tree still starts in one column
+	rows := []treeRow{
+		{name: "virtual"},
+		{prefix: "└── ", name: "file.go", path: path, node: &tree.Node{Name: "file.go", Info: info}},
+	}
+	prefixes := permsPrefixes(rows)
+	if len(prefixes[0]) != len(prefixes[1]) || strings.TrimSpace(prefixes[0]) != "" {
+		t.Errorf("permsPrefixes() = %q, expected a blank prefix as wide as the others", prefixes)
+	}
+	if !strings.HasPrefix(prefixes[1], fileModeString(info)+columnGap) {
+		t.Errorf("permsPrefixes() = %q, expected the mode first", prefixes[1])
+	}
+}
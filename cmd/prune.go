@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pruneOlderThan is the --prune-older-than age, such as "90d".
+var pruneOlderThan string
+
+// prunedDirs maps each directory collapsed by --prune-older-than to the
+// modification time of its newest file and the number of matched entries
+// hidden beneath it.
+var prunedDirs = make(map[string]prunedDir)
+
+// prunedDir summarizes a collapsed directory.
+type prunedDir struct {
+	latest time.Time
+	hidden int
+}
+
+// parseAge parses an age such as "90d", "6w", or "1y", or any duration
+// time.ParseDuration accepts, such as "36h".
+func parseAge(age string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if count, ok := strings.CutSuffix(age, suffix); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid age %q", age)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(age)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 90d, 6w, 1y, or 36h)", age)
+	}
+	return d, nil
+}
+
+// pruneStale collapses every matched directory in which no file has changed
+// since cutoff: the directory stays, but the entries beneath it are dropped
+// and it is summarized by pruneAnnotation instead. Only files the current
+// filters would show count as changes, however deep they are.
+func pruneStale(root string, paths []string, cutoff time.Time) []string {
+	clear(prunedDirs)
+	// A single walk from the root measures every directory beneath it
+	clear(latestCache)
+	latestModTime(root)
+
+	var kept []string
+	for _, path := range paths {
+		if prunedAncestor(root, path) != "" {
+			continue
+		}
+		kept = append(kept, path)
+
+		info, err := lstatCached(path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if latest := latestCache[path]; !latest.IsZero() && latest.Before(cutoff) {
+			prunedDirs[path] = prunedDir{latest: latest}
+		}
+	}
+
+	// Count what was hidden, now that every collapsed directory is known
+	for _, path := range paths {
+		if dir := prunedAncestor(root, path); dir != "" {
+			summary := prunedDirs[dir]
+			summary.hidden++
+			prunedDirs[dir] = summary
+		}
+	}
+	return kept
+}
+
+// prunedAncestor returns the collapsed directory path lies beneath, or "".
+// Paths are walked parents first, so a directory is always known to be
+// collapsed before anything inside it is reached.
+func prunedAncestor(root, path string) string {
+	for dir := filepath.Dir(path); dir != root && len(dir) > len(root); dir = filepath.Dir(dir) {
+		if _, ok := prunedDirs[dir]; ok {
+			return dir
+		}
+	}
+	return ""
+}
+
+// pruneAnnotation summarizes a directory collapsed by --prune-older-than.
+func pruneAnnotation(path string) string {
+	summary, ok := prunedDirs[path]
+	if !ok {
+		return ""
+	}
+	text := "[unchanged since " + summary.latest.Local().Format("2006-01-02")
+	if summary.hidden > 0 {
+		text += fmt.Sprintf(", %d hidden", summary.hidden)
+	}
+	return text + "]"
+}
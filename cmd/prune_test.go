@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"90d", 90 * 24 * time.Hour, false},
+		{"6w", 42 * 24 * time.Hour, false},
+		{"1y", 365 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"0d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		result, err := parseAge(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if result != tt.expected {
+			t.Errorf("parseAge(%q) = %v, expected %v", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestPruneStale(t *testing.T) {
+	root := t.TempDir()
+	old := time.Date(2020, 6, 1, 12, 0, 0, 0, time.Local)
+	now := time.Now()
+
+	files := map[string]time.Time{
+		"archive/2019/a.csv": old,
+		"archive/2019/b.csv": old,
+		"active/main.go":     now,
+		"active/old/util.go": old,
+	}
+	var paths []string
+	for _, dir := range []string{"active", "active/old", "archive", "archive/2019"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for file, modTime := range files {
+		fullPath := filepath.Join(root, file)
+		if err := os.WriteFile(fullPath, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(fullPath, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Walk order: parents before children
+	for _, relPath := range []string{"active", "active/main.go", "active/old", "active/old/util.go",
+		"archive", "archive/2019", "archive/2019/a.csv", "archive/2019/b.csv"} {
+		paths = append(paths, filepath.Join(root, relPath))
+	}
+
+	defer clear(prunedDirs)
+	kept := pruneStale(root, paths, now.Add(-90*24*time.Hour))
+
+	expected := []string{"active", "active/main.go", "active/old", "archive"}
+	if len(kept) != len(expected) {
+		t.Fatalf("pruneStale() kept %v, expected %v", kept, expected)
+	}
+	for i, relPath := range expected {
+		if kept[i] != filepath.Join(root, relPath) {
+			t.Errorf("pruneStale()[%d] = %s, expected %s", i, kept[i], relPath)
+		}
+	}
+
+	if annotation := pruneAnnotation(filepath.Join(root, "archive")); annotation != "[unchanged since 2020-06-01, 3 hidden]" {
+		t.Errorf("pruneAnnotation(archive) = %q", annotation)
+	}
+	if annotation := pruneAnnotation(filepath.Join(root, "active/old")); annotation != "[unchanged since 2020-06-01, 1 hidden]" {
+		t.Errorf("pruneAnnotation(active/old) = %q", annotation)
+	}
+	if annotation := pruneAnnotation(filepath.Join(root, "active")); annotation != "" {
+		t.Errorf("pruneAnnotation(active) = %q, expected none", annotation)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/cobra"
+)
+
+// queryingDaemon makes findMatchingFiles walk the daemon's index instead of
+// the disk.
+var queryingDaemon bool
+
+var queryCmd = &cobra.Command{
+	Use:   "query [path]",
+	Short: "Show a tree from the listings a running daemon keeps in memory.",
+	Long: `Show the tree of a path beneath one of the roots of a running "wintree
+daemon", as the main command would, but walking the daemon's listings instead
+of the disk. The filters and output flags are the same as the main
+command's.
+
+  wintree query -d -1 -i "*.proto"
+  wintree query services/billing --size --format json`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		if err := validateFormatFlags(); err != nil {
+			return err
+		}
+		startPath, err := resolveStartPath(args)
+		if err != nil {
+			return err
+		}
+		if useSmartDefaults {
+			applySmartDefaults(startPath)
+		}
+
+		queryingDaemon = true
+		defer func() { queryingDaemon = false }()
+		filters := processFilters(excludePatterns, includePatterns)
+		matchingFiles, err := findMatchingFiles(startPath, filters)
+		if err != nil {
+			return fmt.Errorf("error finding files: %w", err)
+		}
+		if filters.including() && len(matchingFiles) == 0 {
+			return reportNoMatches(cmd)
+		}
+
+		output, err := renderOutput(startPath, matchingFiles)
+		if err != nil {
+			return err
+		}
+		return writeOutput(output)
+	},
+}
+
+// queryDaemon has the daemon walk root with the walker's options, then
+// replays the walk's callbacks so the results are used as a walk's would be.
+func queryDaemon(root string, w *tree.Walker) ([]string, error) {
+	socket, err := daemonSocketPath()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf(`no daemon is listening on %s (start one with "wintree daemon")`, socket)
+	}
+	defer conn.Close()
+
+	opts := w.Options
+	request := daemonRequest{
+		Root:            root,
+		Exclude:         opts.Exclude,
+		Include:         opts.Include,
+		MaxDepth:        opts.MaxDepth,
+		DepthCountsDirs: opts.DepthCountsDirs,
+		DirsDepth:       opts.DirsDepth,
+		ShowOSFiles:     opts.ShowOSFiles,
+		FollowSymlinks:  opts.FollowSymlinks,
+	}
+	for _, re := range opts.ExcludeRegexp {
+		request.ExcludeRegexp = append(request.ExcludeRegexp, re.String())
+	}
+	for _, re := range opts.IncludeRegexp {
+		request.IncludeRegexp = append(request.IncludeRegexp, re.String())
+	}
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return nil, fmt.Errorf("failed to query the daemon: %w", err)
+	}
+	var response daemonResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to read the daemon's answer: %w", err)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+
+	if w.OnEntry != nil {
+		for _, entry := range response.Entries {
+			w.OnEntry(entry.Path, fs.FileInfoToDirEntry(entry.info()))
+		}
+	}
+	if w.OnExclude != nil {
+		for _, exclusion := range response.Excluded {
+			w.OnExclude(exclusion.Path, exclusion.Rule)
+		}
+	}
+	w.Repeats = response.Repeats
+	return response.Paths, nil
+}
+
+// info returns the entry's metadata as a fs.FileInfo.
+func (e daemonEntry) info() fs.FileInfo {
+	return entryInfo{e}
+}
+
+// entryInfo is the fs.FileInfo of a daemonEntry.
+type entryInfo struct {
+	entry daemonEntry
+}
+
+func (i entryInfo) Name() string       { return filepath.Base(i.entry.Path) }
+func (i entryInfo) Size() int64        { return i.entry.Size }
+func (i entryInfo) Mode() fs.FileMode  { return i.entry.Mode }
+func (i entryInfo) ModTime() time.Time { return i.entry.ModTime }
+func (i entryInfo) IsDir() bool        { return i.entry.Mode.IsDir() }
+func (i entryInfo) Sys() any           { return nil }
+
+func init() {
+	addFilterFlags(queryCmd.Flags())
+	addOutputFlags(queryCmd.Flags())
+	addFormatFlags(queryCmd.Flags())
+	queryCmd.Flags().StringVarP(&daemonSocket, "socket", "", "", "Local socket the daemon listens on (default daemon.sock in the user cache directory)")
+	rootCmd.AddCommand(queryCmd)
+}
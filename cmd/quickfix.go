@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// quickfixRenderer lists every file of the tree as a "path:1: info" line, the
+// shape of compiler errors that Vim's quickfix list and other editors' error
+// parsers understand, so the matched files can be stepped through in an
+// editor. Directories get no lines of their own.
+type quickfixRenderer struct{}
+
+func (r quickfixRenderer) render(root *tree.Node) (string, error) {
+	return streamString(r, root)
+}
+
+func (quickfixRenderer) stream(w io.Writer, root *tree.Node) error {
+	cwd, _ := os.Getwd()
+	return eachTreeRow(root, func(row treeRow) error {
+		node := row.node
+		// --group-ext lines stand for several files, and have no path to open
+		if node == nil || node == root || node.IsDir || node.Path == "" || node.Count > 0 {
+			return nil
+		}
+		_, err := io.WriteString(w, quickfixPath(cwd, node.Path)+":1: "+quickfixInfo(row)+"\n")
+		return err
+	})
+}
+
+// quickfixPath returns path relative to the working directory, where editors
+// resolve it, or as it is when it lies elsewhere.
+func quickfixPath(cwd, path string) string {
+	if cwd != "" && isBelow(path, cwd) {
+		if relPath, err := filepath.Rel(cwd, path); err == nil {
+			return relPath
+		}
+	}
+	return path
+}
+
+// quickfixInfo returns the message of a file's line: its metadata columns and
+// annotations, as the tree would show them, or its name when there are none.
+func quickfixInfo(row treeRow) string {
+	var parts []string
+	for _, column := range nodeColumns(row) {
+		if column = strings.TrimSpace(column); column != "" {
+			parts = append(parts, column)
+		}
+	}
+	if annotation := nodeAnnotations(row.path); annotation != "" {
+		parts = append(parts, annotation)
+	}
+	if len(parts) == 0 {
+		return row.name
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestQuickfixRenderer(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(cwd, "src")
+	outside := filepath.Join(filepath.Dir(cwd), "other.go")
+	root := &tree.Node{Name: "project", Path: cwd, IsDir: true, Children: []*tree.Node{
+		{Name: "src", Path: src, IsDir: true, Children: []*tree.Node{
+			{Name: "main.go", Path: filepath.Join(src, "main.go")},
+			{Name: ".md (2)", Count: 2},
+		}},
+		{Name: "other.go", Path: outside},
+	}}
+
+	expected := filepath.Join("src", "main.go") + ":1: main.go\n" +
+		outside + ":1: other.go\n"
+	if output, _ := (quickfixRenderer{}).render(root); output != expected {
+		t.Errorf("render() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestQuickfixAnonymized(t *testing.T) {
+	defer func(format string, on bool) { outputFormat, anonymize = format, on }(outputFormat, anonymize)
+	outputFormat, anonymize = "quickfix", true
+	if err := validateFormatFlags(); exitCode(err) != exitUsage {
+		t.Errorf("validateFormatFlags() = %v with --anonymize, expected a flag error", err)
+	}
+	anonymize = false
+	if err := validateFormatFlags(); err != nil {
+		t.Errorf("validateFormatFlags() error = %v", err)
+	}
+}
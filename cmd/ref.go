@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// refQuery is the entry --ref narrows the tree to, by path or node ID.
+var refQuery string
+
+// refPaths narrows paths to the entry ref names, by its slash-separated path
+// relative to root or its tree.NodeID, along with the directories above it
+// and everything beneath it. The entry can be any node of the tree built from
+// paths, including a directory drawn only as the parent of matched files.
+func refPaths(root string, paths []string, ref string) ([]string, error) {
+	wanted := path.Clean(filepath.ToSlash(ref))
+	if wanted == "." || ref == tree.NodeID(".") {
+		return paths, nil
+	}
+
+	target := ""
+	for _, p := range paths {
+		// The directories above a match are drawn in the tree as well
+		for ; target == "" && p != root && isBelow(p, root); p = filepath.Dir(p) {
+			if refMatches(root, p, wanted, ref) {
+				target = p
+			}
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("--ref %q matches no entry of the tree", ref)
+	}
+
+	var kept []string
+	for _, p := range paths {
+		if p == target || isBelow(p, target) || isBelow(target, p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+// refMatches reports whether the entry at p is the one named by ref, whose
+// cleaned slash-separated form is wanted. Node IDs are those of the output,
+// which follow the anonymized path when anonymizing.
+func refMatches(root, p, wanted, ref string) bool {
+	relPath, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	if relPath == wanted || tree.NodeID(relPath) == ref {
+		return true
+	}
+	return anonymizing() && tree.NodeID(anonymizeRelPath(relPath)) == ref
+}
+
+// isBelow reports whether path lies somewhere beneath dir.
+func isBelow(path, dir string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
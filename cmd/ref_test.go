@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestRefPaths(t *testing.T) {
+	root := string(filepath.Separator) + "project"
+	paths := []string{
+		filepath.Join(root, "docs"),
+		filepath.Join(root, "docs", "guide.md"),
+		filepath.Join(root, "src"),
+		filepath.Join(root, "src", "cmd"),
+		filepath.Join(root, "src", "cmd", "main.go"),
+		filepath.Join(root, "src", "cmdline.go"),
+	}
+	expected := []string{
+		filepath.Join(root, "src"),
+		filepath.Join(root, "src", "cmd"),
+		filepath.Join(root, "src", "cmd", "main.go"),
+	}
+
+	// A path and its node ID find the same entry; siblings sharing a prefix are left out
+	for _, ref := range []string{"src/cmd", "./src/cmd/", tree.NodeID("src/cmd")} {
+		result, err := refPaths(root, paths, ref)
+		if err != nil || !reflect.DeepEqual(result, expected) {
+			t.Errorf("refPaths(%q) = %v, %v, expected %v", ref, result, err, expected)
+		}
+	}
+
+	if result, err := refPaths(root, paths, "."); err != nil || len(result) != len(paths) {
+		t.Errorf("refPaths(\".\") = %v, %v, expected every path", result, err)
+	}
+
+	// In include mode, directories are only drawn as the parents of matches
+	included := []string{filepath.Join(root, "src", "cmd", "main.go"), filepath.Join(root, "docs", "guide.md")}
+	for _, ref := range []string{"src", tree.NodeID("src/cmd")} {
+		result, err := refPaths(root, included, ref)
+		if err != nil || !reflect.DeepEqual(result, included[:1]) {
+			t.Errorf("refPaths(%q) of included files = %v, %v, expected %v", ref, result, err, included[:1])
+		}
+	}
+
+	if _, err := refPaths(root, paths, "src/missing"); err == nil {
+		t.Error("refPaths() found an entry that does not exist")
+	}
+}
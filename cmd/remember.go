@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// rememberExcludes are the patterns given to remember with --exclude.
+	rememberExcludes []string
+	// rememberForget removes the patterns instead of adding them.
+	rememberForget bool
+)
+
+var rememberCmd = &cobra.Command{
+	Use:   "remember --exclude PATTERN...",
+	Short: "Save excludes you keep typing to the project's config.",
+	Long: `Add exclude patterns to the project's .wintree.yaml, so that they apply to
+every later run in the project without being typed again. The nearest
+.wintree.yaml in the current directory or a parent is updated, or one is
+created in the current directory. With --forget, the patterns are removed
+instead.
+
+Patterns already remembered are left as they are, and the rest of the file,
+including its comments, is kept.
+
+  wintree remember --exclude node_modules,dist
+  wintree remember --exclude "*.log"
+  wintree remember --forget --exclude dist`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(rememberExcludes) == 0 {
+			cmd.SilenceUsage = false
+			return flagErrorf("no patterns given (use --exclude)")
+		}
+
+		path, err := projectConfigPath()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+
+		var text string
+		var changed []string
+		if rememberForget {
+			text, changed, err = updateConfigList(string(data), "exclude", nil, rememberExcludes)
+		} else {
+			text, changed, err = updateConfigList(string(data), "exclude", rememberExcludes, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid config %s: %w", path, err)
+		}
+
+		switch {
+		case len(changed) == 0 && rememberForget:
+			fmt.Printf("None of the patterns are remembered in %s\n", path)
+			return nil
+		case len(changed) == 0:
+			fmt.Printf("All of the patterns are already remembered in %s\n", path)
+			return nil
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+		if rememberForget {
+			fmt.Printf("Forgot %s in %s\n", strings.Join(changed, ", "), path)
+		} else {
+			fmt.Printf("Remembered %s in %s\n", strings.Join(changed, ", "), path)
+		}
+		return nil
+	},
+}
+
+// projectConfigPath returns the project config that applies in the current
+// directory, or the path to create one at there if none does.
+func projectConfigPath() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the current directory: %w", err)
+	}
+	if path := findProjectConfig(dir); path != "" {
+		return path, nil
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// updateConfigList adds the add values to the list setting key of a config
+// file's text and removes the remove values, returning the new text and the
+// values that were added or removed. The setting is rewritten as a block
+// list in place, or appended if the file has none, and removed once empty.
+// Every other line is kept as it was.
+func updateConfigList(text, key string, add, remove []string) (string, []string, error) {
+	settings, err := parseConfig(text)
+	if err != nil {
+		return "", nil, err
+	}
+	var values []string
+	for _, setting := range settings {
+		if setting.key == key {
+			values = append(values, setting.values...)
+		}
+	}
+
+	var changed []string
+	for _, value := range add {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+			changed = append(changed, value)
+		}
+	}
+	for _, value := range remove {
+		if i := slices.Index(values, value); i >= 0 {
+			values = slices.Delete(values, i, i+1)
+			changed = append(changed, value)
+		}
+	}
+	if len(changed) == 0 {
+		return text, nil, nil
+	}
+
+	var block []string
+	if len(values) > 0 {
+		block = append(block, key+":")
+		for _, value := range values {
+			block = append(block, "  - "+yamlScalar(value))
+		}
+	}
+
+	// Replace the setting's lines, from its key to its last item
+	lines := strings.Split(text, "\n")
+	start, end := -1, -1
+	for i, line := range lines {
+		content := strings.TrimSpace(stripYAMLComment(line))
+		if content == "" {
+			continue
+		}
+		topLevel := line[0] != ' ' && line[0] != '-'
+		if start >= 0 && topLevel {
+			break
+		}
+		if start >= 0 {
+			end = i + 1
+		} else if name, _, _ := strings.Cut(content, ":"); topLevel && strings.TrimSpace(name) == key {
+			start, end = i, i+1
+		}
+	}
+
+	if start < 0 {
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		return text + strings.Join(block, "\n") + "\n", changed, nil
+	}
+	lines = slices.Replace(lines, start, end, block...)
+	return strings.Join(lines, "\n"), changed, nil
+}
+
+// yamlScalar returns value as a YAML scalar, quoting it unless it is made
+// only of characters that cannot be mistaken for YAML syntax.
+func yamlScalar(value string) string {
+	plain := value != "" && value[0] != '-'
+	for _, c := range value {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("._/-", c)) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+func init() {
+	rememberCmd.Flags().StringSliceVarP(&rememberExcludes, "exclude", "e", nil, "Patterns to remember as excludes for the project")
+	rememberCmd.Flags().BoolVarP(&rememberForget, "forget", "", false, "Remove the patterns from the project's excludes instead")
+	rootCmd.AddCommand(rememberCmd)
+}
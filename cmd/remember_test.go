@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpdateConfigList(t *testing.T) {
+	config := "# project settings\nexclude:\n  - .git   # always\n  - node_modules\n\n# deeper trees\ndepth: 3\n"
+
+	tests := []struct {
+		name     string
+		text     string
+		add      []string
+		remove   []string
+		expected string
+		changed  []string
+	}{
+		{
+			name:     "add to a block list",
+			text:     config,
+			add:      []string{"node_modules", "*.log"},
+			expected: "# project settings\nexclude:\n  - .git\n  - node_modules\n  - \"*.log\"\n\n# deeper trees\ndepth: 3\n",
+			changed:  []string{"*.log"},
+		},
+		{
+			name:     "add to a flow list",
+			text:     "exclude: [.git, dist]\ncolor: never",
+			add:      []string{"build"},
+			expected: "exclude:\n  - .git\n  - dist\n  - build\ncolor: never",
+			changed:  []string{"build"},
+		},
+		{
+			name:     "add to a file without the setting",
+			text:     "depth: 3",
+			add:      []string{"dist"},
+			expected: "depth: 3\nexclude:\n  - dist\n",
+			changed:  []string{"dist"},
+		},
+		{
+			name:     "add to a new file",
+			add:      []string{"dist"},
+			expected: "exclude:\n  - dist\n",
+			changed:  []string{"dist"},
+		},
+		{
+			name:     "forget",
+			text:     config,
+			remove:   []string{"node_modules", "dist"},
+			expected: "# project settings\nexclude:\n  - .git\n\n# deeper trees\ndepth: 3\n",
+			changed:  []string{"node_modules"},
+		},
+		{
+			name:     "forget the last pattern",
+			text:     "exclude:\n  - dist\ndepth: 3\n",
+			remove:   []string{"dist"},
+			expected: "depth: 3\n",
+			changed:  []string{"dist"},
+		},
+		{
+			name:     "nothing to change",
+			text:     config,
+			add:      []string{".git"},
+			expected: config,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, changed, err := updateConfigList(tt.text, "exclude", tt.add, tt.remove)
+			if err != nil {
+				t.Fatalf("updateConfigList() error = %v", err)
+			}
+			if text != tt.expected || !reflect.DeepEqual(changed, tt.changed) {
+				t.Errorf("updateConfigList() = %q, %v, expected %q, %v", text, changed, tt.expected, tt.changed)
+			}
+
+			// The result must read back as the intended settings
+			if _, err := parseConfig(text); err != nil {
+				t.Errorf("updateConfigList() wrote an invalid config: %v", err)
+			}
+		})
+	}
+}
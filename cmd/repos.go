@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// repoStatus is the state of a git working tree shown by the repos subcommand.
+type repoStatus struct {
+	// branch is the checked-out branch, or "detached at <commit>"
+	branch string
+	// changed counts modified, staged, conflicted, and untracked files
+	changed       int
+	ahead, behind int
+	// worktreeOf is the main working tree of a linked worktree, or ""
+	worktreeOf string
+	err        error
+}
+
+// repoStatuses maps the absolute path of every repository found to its
+// status. It is only populated by the repos subcommand.
+var repoStatuses map[string]repoStatus
+
+var reposCmd = &cobra.Command{
+	Use:   "repos [path]",
+	Short: "Show the git repositories beneath a directory with their status.",
+	Long: `Scan a directory for git repositories and linked worktrees and render them as
+a tree, each marked with its current branch, whether it has uncommitted
+changes, and how far it is ahead of or behind its upstream.
+
+  wintree repos ~/src
+  wintree repos ~/src -d 2 -e archive
+
+Repositories are not searched for further repositories inside them. The whole
+directory is scanned unless --depth is given.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		startPath, err := resolveStartPath(args)
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("depth") {
+			maxDepth = -1
+		}
+		if _, err := exec.LookPath("git"); err != nil {
+			return fmt.Errorf("git is required to show repository status: %w", err)
+		}
+
+		filters := processFilters(excludePatterns, includePatterns)
+		if filters.err != nil {
+			return filters.err
+		}
+		repos, err := findRepos(startPath, filters)
+		if err != nil {
+			return fmt.Errorf("error finding repositories: %w", err)
+		}
+		if len(repos) == 0 {
+			fmt.Println("No git repositories found.")
+			return nil
+		}
+
+		repoStatuses = gitRepoStatuses(repos)
+		defer func() { repoStatuses = nil }()
+
+		return writeOutput(buildTreeOutput(startPath, repos))
+	},
+}
+
+// findRepos returns every directory beneath root, root included, that is the
+// top of a git working tree: one containing a .git directory, or a .git file
+// as linked worktrees and submodules do. Excluded directories, OS metadata,
+// and directories deeper than --depth are not searched.
+func findRepos(root string, filters filter) ([]string, error) {
+	opts := treeOptions(filters)
+
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than failing the scan
+			if path != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root {
+			relPath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			depth := strings.Count(relPath, string(filepath.Separator))
+			if !opts.WithinDepth(depth, true) || isOSNoise(d.Name()) || isExcludedPath(root, path, filters) {
+				return fs.SkipDir
+			}
+		}
+
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return repos, err
+}
+
+// gitRepoStatuses reads the status of every repository, several at a time.
+func gitRepoStatuses(repos []string) map[string]repoStatus {
+	statuses := make([]repoStatus, len(repos))
+	limit := make(chan struct{}, runtime.NumCPU())
+
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			statuses[i] = gitRepoStatus(repo)
+		}()
+	}
+	wg.Wait()
+
+	result := make(map[string]repoStatus, len(repos))
+	for i, repo := range repos {
+		result[repo] = statuses[i]
+	}
+	return result
+}
+
+// gitRepoStatus reads the branch, changes, and upstream distance of the
+// working tree at dir.
+func gitRepoStatus(dir string) repoStatus {
+	status := repoStatus{worktreeOf: mainWorktree(dir)}
+
+	cmd := exec.Command("git", "status", "--porcelain=v2", "--branch", "-z")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		status.err = fmt.Errorf("git status failed: %s", strings.TrimSpace(stderr.String()))
+		return status
+	}
+
+	var head, oid string
+	records := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		switch {
+		case strings.HasPrefix(record, "# branch.oid "):
+			oid = strings.TrimPrefix(record, "# branch.oid ")
+		case strings.HasPrefix(record, "# branch.head "):
+			head = strings.TrimPrefix(record, "# branch.head ")
+		case strings.HasPrefix(record, "# branch.ab "):
+			for _, field := range strings.Fields(strings.TrimPrefix(record, "# branch.ab ")) {
+				n, _ := strconv.Atoi(field[1:])
+				if field[0] == '+' {
+					status.ahead = n
+				} else {
+					status.behind = n
+				}
+			}
+		case strings.HasPrefix(record, "2 "):
+			// Renames and copies are followed by the original path
+			status.changed++
+			i++
+		case strings.HasPrefix(record, "1 "), strings.HasPrefix(record, "u "), strings.HasPrefix(record, "? "):
+			status.changed++
+		}
+	}
+
+	status.branch = head
+	if head == "(detached)" {
+		status.branch = "detached at " + oid[:min(len(oid), 7)]
+	}
+	return status
+}
+
+// mainWorktree returns the main working tree that dir is a linked worktree
+// of, or "" if dir is not one. Linked worktrees have a .git file pointing into
+// the main repository's .git/worktrees directory.
+func mainWorktree(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, ".git"))
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return ""
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	// gitDir is <main>/.git/worktrees/<name>
+	worktrees := filepath.Dir(filepath.Clean(gitDir))
+	if filepath.Base(worktrees) != "worktrees" {
+		return ""
+	}
+	return filepath.Dir(filepath.Dir(worktrees))
+}
+
+// repoAnnotation describes the status of a repository found by the repos
+// subcommand, such as "[main, 3 changed, ahead 1]".
+func repoAnnotation(path string) string {
+	status, ok := repoStatuses[path]
+	if !ok {
+		return ""
+	}
+	if status.err != nil {
+		return "[status unavailable]"
+	}
+
+	parts := []string{status.branch}
+	if status.changed == 0 {
+		parts = append(parts, "clean")
+	} else {
+		parts = append(parts, fmt.Sprintf("%d changed", status.changed))
+	}
+	if status.ahead > 0 {
+		parts = append(parts, fmt.Sprintf("ahead %d", status.ahead))
+	}
+	if status.behind > 0 {
+		parts = append(parts, fmt.Sprintf("behind %d", status.behind))
+	}
+	if status.worktreeOf != "" {
+		parts = append(parts, "worktree of "+pathLabel(status.worktreeOf))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func init() {
+	addFilterFlags(reposCmd.Flags())
+	addOutputFlags(reposCmd.Flags())
+	reposCmd.Flags().BoolVarP(&noPager, "no-pager", "", false, "Print long output directly instead of through $PAGER")
+	rootCmd.AddCommand(reposCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRepos(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	tempDir := t.TempDir()
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	writeFile := func(path string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := filepath.Join(tempDir, "app")
+	lib := filepath.Join(tempDir, "group", "lib")
+	for _, repo := range []string{app, lib} {
+		writeFile(filepath.Join(repo, "README.md"))
+		git(repo, "init", "-q", "-b", "main")
+		git(repo, "add", ".")
+		git(repo, "commit", "-q", "-m", "initial")
+	}
+	// Nested repositories are not searched for
+	writeFile(filepath.Join(app, "vendor", "dep", "x.go"))
+	git(filepath.Join(app, "vendor", "dep"), "init", "-q")
+
+	writeFile(filepath.Join(lib, "new.go"))
+	if err := os.WriteFile(filepath.Join(lib, "README.md"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(filepath.Join(tempDir, "notes", "todo.txt"))
+	worktree := filepath.Join(tempDir, "app-feature")
+	git(app, "worktree", "add", "-q", "-b", "feature", worktree)
+
+	originalDepth, originalFullPath := maxDepth, showFullPath
+	defer func() { maxDepth, showFullPath = originalDepth, originalFullPath }()
+	maxDepth, showFullPath = -1, false
+
+	repos, err := findRepos(tempDir, processFilters(nil, nil))
+	if err != nil {
+		t.Fatalf("findRepos() error = %v", err)
+	}
+	if expected := []string{app, worktree, lib}; !reflect.DeepEqual(repos, expected) {
+		t.Fatalf("findRepos() = %v, expected %v", repos, expected)
+	}
+
+	repoStatuses = gitRepoStatuses(repos)
+	defer func() { repoStatuses = nil }()
+
+	expected := map[string]string{
+		// The nested repository shows up as untracked
+		app:      "[main, 1 changed]",
+		lib:      "[main, 2 changed]",
+		worktree: "[feature, clean, worktree of app]",
+		tempDir:  "",
+	}
+	for path, annotation := range expected {
+		if result := repoAnnotation(path); result != annotation {
+			t.Errorf("repoAnnotation(%s) = %q, expected %q", path, result, annotation)
+		}
+	}
+}
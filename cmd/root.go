@@ -4,15 +4,19 @@ Copyright © 2025 Maxim Dribny <mdribnyi@gmail.com>
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/atotto/clipboard"
+	"github.com/maxdribny/wintree/pkg/tree"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"regexp"
 )
@@ -27,19 +31,71 @@ var (
 var (
 	excludePatterns  []string
 	includePatterns  []string
+	excludeRegexes   []string
+	includeRegexes   []string
 	outputFile       string
+	noPager          bool
 	copyToClipboard  bool
 	showPatterns     bool
 	showVersion      bool
 	useSmartDefaults bool
+	showOSFiles      bool
+	followSymlinks   bool
+	walkJobs         int
 	maxDepth         int
+	dirsDepth        int
+	minDepth         int
 	showFullPath     bool
 	fullPathOnly     bool
+	annotateMeta     bool
+	describeDirs     bool
+	showOwners       bool
+	contentsDump     bool
+	includeNoise     bool
+	splitTokens      int
+	showCommitInfo   bool
+	archivePath      string
+	treeLabel        string
+	// relativeRootLabel is how the root line shows the working directory:
+	// dot, name, or full, or "" to follow --full-path
+	relativeRootLabel string
+	virtualRoot       string
+	exportViewOnly    bool
+	outputFormat      string
+	noReport          bool
+	watchMode         bool
+	sortOrder         string
+	dirsFirst         bool
+
+	truncateNames bool
+	maxLineWidth  int
+
+	showSizes    bool
+	apparentSize bool
+	siUnits      bool
+	showSizeBars bool
+	showInodes   bool
+	showPerms    bool
+	showMtime    bool
+	timeFormat   string
+	showACL      bool
+
+	anonymize             bool
+	anonymizeHashPatterns []string
 )
 
 type filter struct {
-	excludeGlobs []string
-	includeGlobs []string
+	excludeGlobs   []string
+	includeGlobs   []string
+	excludeRegexps []*regexp.Regexp
+	includeRegexps []*regexp.Regexp
+	// err is set if a --exclude-regex or --include-regex does not compile
+	err error
+}
+
+// including reports whether the filters are in include mode.
+func (f filter) including() bool {
+	return len(f.includeGlobs) > 0 || len(f.includeRegexps) > 0
 }
 
 var rootCmd = &cobra.Command{
@@ -50,8 +106,16 @@ built-in tree commands on Windows and other operating systems.
 
 It allows for advanced filtering with inclusion and exclusion patterns
 and can output to the terminal, a file, or the system clipboard.`,
-	Args: cobra.MaximumNArgs(1), // We expect at most one argument: the path.
+	// We expect at most one argument, the path, unless a virtual root merges several
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 && virtualRoot == "" {
+			return fmt.Errorf("multiple paths require the --virtual-root flag")
+		}
+		return nil
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		started := time.Now()
+
 		// Check if the user wants version info
 		if showVersion {
 			printVersionInfo()
@@ -67,31 +131,142 @@ and can output to the terminal, a file, or the system clipboard.`,
 		// Validate -fp flag usage
 		if fullPathOnly {
 			// Check for conflicting flags
-			if maxDepth != 1 {
-				return fmt.Errorf("-fp flag cannot be used with --depth flag")
+			if maxDepth != 1 || depthKeyword != "" || dirsDepth > 0 {
+				return flagErrorf("-fp flag cannot be used with --depth flag")
 			}
 			if len(excludePatterns) > 0 {
-				return fmt.Errorf("-fp flag cannot be used with --exclude flag")
+				return flagErrorf("-fp flag cannot be used with --exclude flag")
 			}
 			if len(includePatterns) > 0 {
-				return fmt.Errorf("-fp flag cannot be used with --include flag")
+				return flagErrorf("-fp flag cannot be used with --include flag")
 			}
 			if copyToClipboard {
-				return fmt.Errorf("-fp flag cannot be used with --copy flag")
+				return flagErrorf("-fp flag cannot be used with --copy flag")
 			}
 			if outputFile != "" {
-				return fmt.Errorf("-fp flag cannot be used with --out flag")
+				return flagErrorf("-fp flag cannot be used with --out flag")
 			}
 		}
 
-		// 1. Setup - Find Start Path
-		startPath := "."
-		if len(args) > 0 {
-			startPath = args[0]
+		// Validate --split-tokens usage
+		if splitTokens > 0 {
+			if !contentsDump {
+				return flagErrorf("--split-tokens flag requires the --contents flag")
+			}
+			if copyToClipboard {
+				return flagErrorf("--split-tokens flag cannot be used with --copy flag")
+			}
+			if countTokens {
+				return flagErrorf("--split-tokens flag cannot be used with --count-tokens flag, as it reports the tokens of each part")
+			}
+			if summaryJSON != "" || patternStats {
+				return flagErrorf("--split-tokens flag cannot be used with --summary-json or --pattern-stats flags")
+			}
+		}
+
+		// Validate --copy-files usage
+		if copyFiles {
+			if !clipboardFilesSupported() {
+				return flagErrorf("--copy-files flag is only supported on Windows and macOS")
+			}
+			if copyToClipboard {
+				return flagErrorf("--copy-files flag cannot be used with --copy flag")
+			}
+		}
+
+		// Validate --format usage
+		if err := validateFormatFlags(); err != nil {
+			return err
+		}
+
+		// Validate --charset usage
+		switch charsetMode {
+		case "auto", "utf8", "unicode", "ascii":
+		default:
+			return flagErrorf("invalid --charset %q (use auto, utf8, unicode, or ascii)", charsetMode)
+		}
+		if asciiChars && (charsetMode == "utf8" || charsetMode == "unicode") {
+			return flagErrorf("--ascii flag cannot be used with --charset %s", charsetMode)
+		}
+
+		// Validate --color usage
+		switch colorMode {
+		case "auto", "always", "never":
+		default:
+			return flagErrorf("invalid --color %q (use auto, always, or never)", colorMode)
+		}
+
+		// Validate --time-format usage
+		if err := validateTimeFormat(); err != nil {
+			return asFlagError(err)
+		}
+
+		// Validate --hash usage
+		if err := validateChecksumFlags(); err != nil {
+			return asFlagError(err)
+		}
+
+		// Validate --line-endings and --max-file-size usage, which are only
+		// needed once the output is written
+		if _, err := convertLineEndings(""); err != nil {
+			return asFlagError(err)
+		}
+		if _, err := contentSizeLimit(); err != nil {
+			return asFlagError(err)
+		}
+
+		// Validate --archive usage before walking the tree
+		if archivePath != "" {
+			if _, err := archiveFormat(archivePath); err != nil {
+				return asFlagError(err)
+			}
 		}
-		startPath, err := filepath.Abs(startPath)
+
+		// Validate --budget usage before walking the tree
+		budgets, err := parseBudgets(budgetRules)
+		if err != nil {
+			return asFlagError(err)
+		}
+		binaryLimit := int64(-1)
+		if binaryThreshold != "" {
+			if binaryLimit, err = parseSize(binaryThreshold); err != nil {
+				return flagErrorf("invalid --flag-binaries: %w", err)
+			}
+		}
+
+		// Validate --watch usage: only the plain tree is re-rendered
+		if watchMode {
+			if virtualRoot != "" || archivePath != "" || splitTokens > 0 || exportViewOnly || len(budgets) > 0 || binaryLimit >= 0 || countTokens || summaryJSON != "" || patternStats || refQuery != "" || copyFiles {
+				return flagErrorf("--watch flag cannot be used with --virtual-root, --archive, --split-tokens, --export-view, --budget, --flag-binaries, --count-tokens, --summary-json, --pattern-stats, --ref, or --copy-files flags")
+			}
+		}
+
+		// Validate the filter flags before anything is walked
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+
+		// Render several paths under a synthetic root node if requested
+		if virtualRoot != "" {
+			if len(budgets) > 0 || coveragePath != "" || binaryLimit >= 0 || countTokens || summaryJSON != "" || patternStats {
+				return flagErrorf("--budget, --coverage, --flag-binaries, --count-tokens, --summary-json, and --pattern-stats flags cannot be used with --virtual-root flag")
+			}
+			if fullPathOnly {
+				return flagErrorf("-fp flag cannot be used with --virtual-root flag")
+			}
+			if refQuery != "" || copyFiles {
+				return flagErrorf("--ref and --copy-files flags cannot be used with --virtual-root flag")
+			}
+			if contentsDump || archivePath != "" {
+				return flagErrorf("--virtual-root flag cannot be used with --contents or --archive flags")
+			}
+			return writeVirtualRootTree(args)
+		}
+
+		// 1. Setup - Find Start Path
+		startPath, err := resolveStartPath(args)
 		if err != nil {
-			return fmt.Errorf("invalid starting path: %w", err)
+			return err
 		}
 
 		// If fullPathOnly flag is set, just print the absolute path and exit
@@ -100,6 +275,20 @@ and can output to the terminal, a file, or the system clipboard.`,
 			return nil
 		}
 
+		// Read the coverage profile to overlay on the tree
+		if coveragePath != "" {
+			if err := loadCoverage(coveragePath, startPath); err != nil {
+				return err
+			}
+		}
+
+		// Ownership can only be shown from a CODEOWNERS file
+		if showOwners {
+			if file := codeownersFor(startPath); file == nil || len(file.rules) == 0 {
+				return fmt.Errorf("--owners: no CODEOWNERS rules found for %s", startPath)
+			}
+		}
+
 		// Apply smart defaults if requested
 		if useSmartDefaults {
 			applySmartDefaults(startPath)
@@ -107,42 +296,237 @@ and can output to the terminal, a file, or the system clipboard.`,
 
 		filters := processFilters(excludePatterns, includePatterns)
 
-		// 2. Find all matching files
+		// Keep re-rendering the tree as files change if requested
+		if watchMode {
+			// The tree is redrawn in place, so it must never wait in a pager
+			noPager = true
+			return runWatch(startPath, filters)
+		}
+
+		// 2. Find all matching files, or those found before Ctrl+C
+		release := catchInterrupt()
 		matchingFiles, err := findMatchingFiles(startPath, filters)
+		release()
+		interrupted := errors.Is(err, errInterrupted)
+		if interrupted {
+			err = nil
+		}
 		if err != nil {
-			return fmt.Errorf("error finding files: %w", err)
+			err = fmt.Errorf("error finding files: %w", err)
+			if summaryJSON != "" {
+				// The failure is recorded too, so dashboards see the failed run
+				summary := newRunSummary(startPath, nil, started)
+				summary.Errors = append(summary.Errors, err.Error())
+				if text, err := writeSummary(summary, started); err == nil {
+					fmt.Print(text)
+				}
+			}
+			return err
+		}
+
+		// Preview what git archive would produce if requested
+		if exportViewOnly {
+			if matchingFiles, err = exportView(startPath, matchingFiles); err != nil {
+				return err
+			}
+		}
+
+		// Show what was found before Ctrl+C, skipping any further long work
+		if interrupted {
+			finalOutput, err := renderOutput(startPath, matchingFiles)
+			if err != nil {
+				return err
+			}
+			if err := writeOutput(markInterrupted(finalOutput)); err != nil {
+				return err
+			}
+			// The marker already says why the run failed
+			cmd.SilenceUsage, cmd.SilenceErrors = true, true
+			return errInterrupted
+		}
+
+		// Narrow the tree to one entry and its ancestors if requested
+		if refQuery != "" {
+			if matchingFiles, err = refPaths(startPath, matchingFiles, refQuery); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
 		}
 
 		// If in include mode and no files were found, nothing to do
-		if len(filters.includeGlobs) > 0 && len(matchingFiles) == 0 {
-			fmt.Println("No files found matching the given patterns.")
-			return nil
+		if filters.including() && len(matchingFiles) == 0 {
+			return reportNoMatches(cmd)
 		}
 
-		// 3. Build the tree output from the list of files
-		finalOutput := buildTreeOutput(startPath, matchingFiles)
+		// Split the content dump into token-limited part files if requested
+		if splitTokens > 0 {
+			return writeSplitDump(startPath, matchingFiles)
+		}
 
-		// 4. Handle final output
-		if copyToClipboard {
-			if err := clipboard.WriteAll(finalOutput); err != nil {
-				return fmt.Errorf("failed to copy to clipboard: %w", err)
+		// Package the matched files if requested
+		if archivePath != "" {
+			if err := writeArchive(startPath, matchingFiles, archivePath); err != nil {
+				return fmt.Errorf("failed to create archive: %w", err)
 			}
-			fmt.Println("Output copied to clipboard.")
 		}
-		if outputFile != "" {
-			if err := os.WriteFile(outputFile, []byte(finalOutput), 0644); err != nil {
-				return fmt.Errorf("failed to write to output file: %w", err)
+
+		// Measure the directories with a size budget, to be marked in the tree
+		overBudget, err := checkBudgets(startPath, budgets)
+		if err != nil {
+			return err
+		}
+
+		// Look for binaries committed among the sources, to be marked in the tree
+		var binaries []string
+		if binaryLimit >= 0 {
+			if binaries, err = findBinaries(startPath, binaryLimit, filters); err != nil {
+				return fmt.Errorf("error finding binaries: %w", err)
 			}
-			fmt.Printf("Output written to %s\n", outputFile)
 		}
-		if !copyToClipboard && outputFile == "" {
-			fmt.Print(finalOutput)
+
+		// 3. Build the tree output from the list of files, writing it to the
+		// console as it is drawn when nothing needs the whole of it
+		var finalOutput string
+		if streamingOutput() {
+			if err := streamOutput(startPath, matchingFiles); err != nil {
+				return err
+			}
+		} else {
+			renderStarted := time.Now()
+			if finalOutput, err = renderOutput(startPath, matchingFiles); err != nil {
+				return err
+			}
+
+			// Account for the run, with the failures reported below
+			if summaryJSON != "" {
+				summary := newRunSummary(startPath, matchingFiles, started)
+				summary.Durations.Render = milliseconds(time.Since(renderStarted))
+				summary.Errors = append(summary.Errors, overBudget...)
+				if len(binaries) > 0 {
+					summary.Errors = append(summary.Errors, plural(len(binaries), "binary file", "binary files")+" found in source directories")
+				}
+				text, err := writeSummary(summary, started)
+				if err != nil {
+					return err
+				}
+				// A summary written to a file leaves the output untouched
+				if summaryJSON == "-" {
+					finalOutput += "\n" + text
+				}
+			}
+
+			// 4. Handle final output
+			if err := writeOutput(finalOutput); err != nil {
+				return err
+			}
 		}
 
+		// Put the files themselves on the clipboard if requested
+		if copyFiles {
+			if err := copyFileReferences(matchingFiles); err != nil {
+				return err
+			}
+		}
+
+		// Report how much of a model's context the files would take
+		if countTokens {
+			report, err := tokenReport(startPath, matchingFiles, finalOutput)
+			if err != nil {
+				return fmt.Errorf("error counting tokens: %w", err)
+			}
+			fmt.Fprintln(os.Stderr, report)
+		}
+
+		// Report which patterns did the filtering, so dead ones can be pruned
+		if patternStats {
+			fmt.Fprint(os.Stderr, patternReport(patternHits(startPath, matchingFiles, filters)))
+		}
+
+		// Fail when a budget is exceeded, so CI jobs can guard against growth
+		if len(overBudget) > 0 {
+			for _, message := range overBudget {
+				fmt.Fprintln(os.Stderr, message)
+			}
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d size budgets exceeded", len(overBudget), len(budgets))
+		}
+
+		// Fail when binaries are found, so pre-commit hooks can reject them
+		if len(binaries) > 0 {
+			fmt.Fprint(os.Stderr, binaryReport(startPath, binaries))
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s found in source directories", plural(len(binaries), "binary file", "binary files"))
+		}
 		return nil
 	},
 }
 
+// resolveStartPath returns the absolute path of the optional path argument,
+// defaulting to the current directory.
+func resolveStartPath(args []string) (string, error) {
+	startPath := "."
+	if len(args) > 0 {
+		startPath = args[0]
+	}
+	startPath, err := filepath.Abs(startPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid starting path: %w", err)
+	}
+	return startPath, nil
+}
+
+// renderOutput builds the tree for the matched files in the --format
+// requested, followed by their contents when --contents is set.
+func renderOutput(startPath string, matchingFiles []string) (string, error) {
+	finalOutput, err := rendererFor(outputFormat).render(buildTree(startPath, matchingFiles))
+	if err != nil {
+		return "", err
+	}
+
+	if contentsDump {
+		contents, err := buildContentsOutput(startPath, matchingFiles)
+		if err != nil {
+			return "", fmt.Errorf("error dumping file contents: %w", err)
+		}
+		finalOutput += contents
+	}
+
+	return finalOutput, nil
+}
+
+// writeOutput sends the final output to the clipboard (or, if too large, a
+// temporary file whose path is copied), the output file, or the console,
+// depending on the --copy and --out flags. Clipboard and file output take
+// the --line-endings setting. Console output taller
+// than the terminal goes through the pager unless --no-pager is set.
+func writeOutput(finalOutput string) error {
+	// Files and the clipboard get the line endings asked for; the console keeps LF
+	converted := finalOutput
+	if copyToClipboard || outputFile != "" {
+		var err error
+		if converted, err = convertLineEndings(finalOutput); err != nil {
+			return err
+		}
+	}
+
+	if copyToClipboard {
+		if err := copyOutput(converted); err != nil {
+			return err
+		}
+	}
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, []byte(converted), 0644); err != nil {
+			return fmt.Errorf("failed to write to output file: %w", err)
+		}
+		info("Output written to %s", outputFile)
+	}
+	if !copyToClipboard && outputFile == "" && !pageOutput(finalOutput) {
+		fmt.Print(finalOutput)
+	}
+
+	return nil
+}
+
 // expandBraces expands brace patterns like "*.{go,js}" into ["*.go", "*.js"]
 func expandBraces(pattern string) []string {
 	braceRegex := regexp.MustCompile(`\{([^}]*)\}`)
@@ -181,254 +565,484 @@ func processFilters(exclude, include []string) filter {
 		expandedInclude = append(expandedInclude, expandBraces(pattern)...)
 	}
 
-	return filter{
+	f := filter{
 		excludeGlobs: expandedExclude,
 		includeGlobs: expandedInclude,
 	}
-}
-
-// findMatchingFiles handles directory-based includes and file-based glob includes.
-func findMatchingFiles(root string, f filter) ([]string, error) {
-	var matchingPaths []string
 
-	walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	// Regular expressions are compiled once here rather than for every entry
+	for _, expr := range excludeRegexes {
+		re, err := regexp.Compile(expr)
 		if err != nil {
-			return err
+			f.err = fmt.Errorf("invalid --exclude-regex %q: %w", expr, err)
+			return f
 		}
+		f.excludeRegexps = append(f.excludeRegexps, re)
+	}
+	for _, expr := range includeRegexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			f.err = fmt.Errorf("invalid --include-regex %q: %w", expr, err)
+			return f
+		}
+		f.includeRegexps = append(f.includeRegexps, re)
+	}
+	return f
+}
 
-		// Depth check (before exclusion / inclusion)
-		if d.IsDir() && path != root {
-			relPath, err := filepath.Rel(root, path)
-			if err != nil {
-				return err
-			}
+// walkLimits are the parsed values of the filter flags that take sizes,
+// percentages, and ages.
+type walkLimits struct {
+	sampleFraction float64
+	// minSize and maxSize are -1 for no limit
+	minSize, maxSize int64
+	// pruneAge is 0 to prune nothing
+	pruneAge time.Duration
+}
 
-			// Depth 0 is the root's immediate children
-			depth := strings.Count(relPath, string(filepath.Separator))
+// limits holds the walkLimits of the flags, as set by validateFilterFlags.
+var limits = walkLimits{minSize: -1, maxSize: -1}
 
-			// if maxdepth is set and the current depth exceeds it, skip this directory
-			if maxDepth != -1 && depth > maxDepth {
-				return fs.SkipDir
-			}
-		}
+// validateFilterFlags checks the flags added by addFilterFlags, returning a
+// flagError for the first invalid one, and parses the values findMatchingFiles
+// uses into limits. Commands call it once, before walking anything.
+func validateFilterFlags() error {
+	// Building filters compiles --exclude-regex and --include-regex
+	if f := processFilters(nil, nil); f.err != nil {
+		return asFlagError(f.err)
+	}
+	if err := validateFileTypes(); err != nil {
+		return asFlagError(err)
+	}
+	if walkJobs < 0 {
+		return flagErrorf("invalid --jobs %d (use a positive number, or 0 for one per CPU)", walkJobs)
+	}
+	if err := validateRelativeRootLabel(); err != nil {
+		return asFlagError(err)
+	}
+	if err := validateDepthCounts(); err != nil {
+		return asFlagError(err)
+	}
+	if err := validateIconFlags(); err != nil {
+		return asFlagError(err)
+	}
+	switch tree.SortKey(sortOrder) {
+	case tree.SortByName, tree.SortBySize, tree.SortByMtime, tree.SortByExtension:
+	default:
+		return flagErrorf("invalid --sort %q (use name, size, mtime, or extension)", sortOrder)
+	}
 
-		// --- Exclusion Logic (runs first) ---
-		entryName := d.Name()
-		for _, pattern := range f.excludeGlobs {
-			matched, _ := filepath.Match(pattern, entryName)
-			if matched {
-				if d.IsDir() {
-					if path == root {
-						return nil
-					}
-					return fs.SkipDir
-				}
-				return nil
-			}
+	fraction, err := sampleFraction()
+	if err != nil {
+		return asFlagError(err)
+	}
+	minSize, maxSize, err := sizeRange()
+	if err != nil {
+		return asFlagError(err)
+	}
+	var pruneAge time.Duration
+	if pruneOlderThan != "" {
+		if pruneAge, err = parseAge(pruneOlderThan); err != nil {
+			return flagErrorf("invalid --prune-older-than: %w", err)
 		}
+	}
+	limits = walkLimits{sampleFraction: fraction, minSize: minSize, maxSize: maxSize, pruneAge: pruneAge}
+	return nil
+}
 
-		// If not in include mode, add everything that respects the depth limit.
-		if len(f.includeGlobs) == 0 {
-			// Also check depth for files when not in include mode.
-			relPath, err := filepath.Rel(root, path)
-			if err != nil {
-				return err
-			}
-
-			if path == root {
-				return nil
-			}
-
-			depth := strings.Count(relPath, string(filepath.Separator))
-
-			// Add any item that is within the allowed depth.
-			if maxDepth == -1 || depth < maxDepth+1 {
-				matchingPaths = append(matchingPaths, path)
-			}
-		}
-
-		// In include mode, we must match files or directories explicitly.
-		if len(f.includeGlobs) > 0 {
-			// Case 1: A directory is an exact match for an include pattern.
-			// If so, we do a sub-walk and add all its files.
-			if d.IsDir() {
-				for _, pattern := range f.includeGlobs {
-					if d.Name() == pattern {
-						// This directory is explicitly included. Walk it and add all files within.
-						subWalkErr := filepath.WalkDir(path, func(subPath string, subD fs.DirEntry, _ error) error {
-							if !subD.IsDir() {
-								// Check if this sub-file is excluded.
-								isExcluded := false
-								for _, excludePattern := range f.excludeGlobs {
-									if matched, _ := filepath.Match(excludePattern, subD.Name()); matched {
-										isExcluded = true
-										break
-									}
-								}
-								if !isExcluded {
-									matchingPaths = append(matchingPaths, subPath)
-								}
-							}
-							return nil
-						})
-						if subWalkErr != nil {
-							return subWalkErr
-						}
-						// We've processed this directory, so skip it in the main walk to avoid duplication.
-						return fs.SkipDir
-					}
-				}
-			} else { // Case 2: If it's a file, check if it matches a glob-style include pattern.
-				for _, pattern := range f.includeGlobs {
-					if matched, _ := filepath.Match(pattern, d.Name()); matched {
-						// Also check depth for files when in include mode.
-						relPath, err := filepath.Rel(root, path)
-						if err != nil {
-							return err
-						}
-						depth := strings.Count(relPath, string(filepath.Separator))
-						if maxDepth == -1 || depth < maxDepth+1 {
-							matchingPaths = append(matchingPaths, path)
-							break // Found a match, no need to check other patterns
-						}
-					}
-				}
-			}
-		}
+// findMatchingFiles walks root with the filters, any .wintreeignore files,
+// and the depth flags, then narrows the matches with the git, pruning, --min-depth, --type, size, and
+// sampling flags. What each rule leaves out is counted in lastWalk. The flags
+// must have passed validateFilterFlags.
+func findMatchingFiles(root string, f filter) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var pruneCutoff time.Time
+	if limits.pruneAge > 0 {
+		pruneCutoff = time.Now().Add(-limits.pruneAge)
+	}
 
-		return nil
-	})
+	started := time.Now()
+	lastWalk = walkStats{excluded: make(map[string]int)}
+	flagExcludes := f.excludeGlobs
 
-	return matchingPaths, walkErr
-}
+	// The root's .wintreeignore is applied during the walk, like --exclude
+	if !noIgnoreFile {
+		f.excludeGlobs = append(slices.Clip(f.excludeGlobs), readIgnoreFile(root)...)
+	}
 
-// Construct the tree output as a string
-func buildTreeOutput(root string, paths []string) string {
-	if len(paths) == 0 {
-		var output strings.Builder
-		if showFullPath {
-			output.WriteString(root + "\n")
-		} else {
-			output.WriteString(filepath.Base(root) + "\n")
+	clear(walkEntries)
+	var ignoreDirs []string
+	walker := tree.NewWalker(treeOptions(f))
+	walker.OnEntry = func(path string, d fs.DirEntry) {
+		walkEntries[path] = d
+		if !noIgnoreFile && d.Name() == ignoreFileName && filepath.Dir(path) != root {
+			ignoreDirs = append(ignoreDirs, filepath.Dir(path))
 		}
-		return output.String()
 	}
+	walker.OnExclude = func(_, rule string) {
+		lastWalk.drop(excludeRuleLabel(rule, flagExcludes, f.excludeRegexps), 1)
+	}
+	walker.Stop = walkStop
+	var matchingPaths []string
+	var walkErr error
+	if queryingDaemon {
+		matchingPaths, walkErr = queryDaemon(root, walker)
+	} else {
+		matchingPaths, walkErr = walker.Walk(root)
+	}
+	repeatedDirs = walker.Repeats
 
-	// Initialize a map to hold all nodes (directories and files)
-	nodes := make(map[string]bool)
-	// Always include the root directory
-	nodes[root] = true
+	// A stopped walk still has its partial matches narrowed and returned
+	interrupted := errors.Is(walkErr, tree.ErrStopped)
+	if interrupted {
+		walkErr = nil
+	}
+	defer func() { lastWalk.elapsed = time.Since(started) }()
 
-	// Add all files and their parent directories to the nodes map
-	for _, path := range paths {
-		// Skip paths outside the root directory
-		if !strings.HasPrefix(path, root) {
-			continue
-		}
+	// narrow applies a filter to the matches, counting what it leaves out
+	narrow := func(rule string, keep func([]string) []string) {
+		before := len(matchingPaths)
+		matchingPaths = keep(matchingPaths)
+		lastWalk.drop(rule, before-len(matchingPaths))
+	}
 
-		// Add the file itself
-		nodes[path] = true
+	if walkErr == nil && (showGitStatus || gitClean) {
+		// Statuses are read afresh for each walk, as watch re-renders the tree
+		if err := loadGitStates(root); err != nil {
+			return nil, err
+		}
+	}
+	if walkErr == nil {
+		for _, ff := range fileFilters(root, ignoreDirs) {
+			narrow(ff.rule, ff.keep)
+		}
+	}
 
-		// Add all parent directories of the path to the nodes map as well
-		dir := filepath.Dir(path)
-		for dir != root && dir != "." && dir != "/" {
-			nodes[dir] = true
-			dir = filepath.Dir(dir)
+	if walkErr == nil && !pruneCutoff.IsZero() {
+		narrow("--prune-older-than", func(paths []string) []string { return pruneStale(root, paths, pruneCutoff) })
+	}
+	if walkErr == nil && minDepth > 0 {
+		narrow("--min-depth", func(paths []string) []string { return filterByMinDepth(root, paths) })
+	}
+	pluginAnnotations = nil
+	if walkErr == nil && annotateCommand != "" {
+		if err := runAnnotateCommand(root, matchingPaths); err != nil {
+			return nil, err
 		}
+		narrow("--annotate-cmd", func(paths []string) []string { return dropPluginHidden(root, paths) })
+	}
+	if walkErr == nil && (limits.sampleFraction > 0 || sampleCount > 0) {
+		narrow("--sample", func(paths []string) []string { return sampleFiles(root, paths, limits.sampleFraction) })
+	}
+	if interrupted && walkErr == nil {
+		return matchingPaths, errInterrupted
 	}
+	return matchingPaths, walkErr
+}
+
+// fileFilter is a filter findMatchingFiles applies to the matches after the
+// walk, with the rule what it leaves out is counted under.
+type fileFilter struct {
+	rule string
+	keep func(paths []string) []string
+}
 
-	// Convert to a sorted slice for consistent output
-	sortedNodes := make([]string, 0, len(nodes))
-	for nodePath := range nodes {
-		sortedNodes = append(sortedNodes, nodePath)
+// fileFilters returns the filters beyond those of the walk itself that decide
+// which files beneath root are shown: the .wintreeignore files of ignoreDirs,
+// --git-clean, --type, and --min-size/--max-size. The git states must already
+// be loaded.
+func fileFilters(root string, ignoreDirs []string) []fileFilter {
+	var filters []fileFilter
+	if len(ignoreDirs) > 0 {
+		filters = append(filters, fileFilter{ignoreFileName, func(paths []string) []string { return dropNestedIgnored(root, paths, ignoreDirs) }})
 	}
-	sort.Strings(sortedNodes)
+	if gitClean {
+		filters = append(filters, fileFilter{"--git-clean", dropGitIgnored})
+	}
+	if len(fileTypes) > 0 {
+		filters = append(filters, fileFilter{"--type", filterByType})
+	}
+	if limits.minSize >= 0 || limits.maxSize >= 0 {
+		filters = append(filters, fileFilter{"--min-size/--max-size", func(paths []string) []string { return filterBySize(paths, limits.minSize, limits.maxSize) }})
+	}
+	return filters
+}
 
-	// Generate the tree output
-	var output strings.Builder
+// treeOptions returns the walk options for the filters and the depth and
+// OS metadata flags.
+func treeOptions(f filter) tree.Options {
+	return tree.Options{
+		Exclude:         f.excludeGlobs,
+		Include:         f.includeGlobs,
+		ExcludeRegexp:   f.excludeRegexps,
+		IncludeRegexp:   f.includeRegexps,
+		MaxDepth:        maxDepth,
+		DepthCountsDirs: depthCounts == "dirs",
+		DirsDepth:       dirsDepth,
+		ShowOSFiles:     showOSFiles,
+		FollowSymlinks:  followSymlinks,
+		Jobs:            jobs(),
+	}
+}
 
-	// Add full path if flag is set, otherwise add just the base directory name
-	if showFullPath {
-		output.WriteString(root + "\n")
-	} else {
-		output.WriteString(filepath.Base(root) + "\n")
+// jobs returns how many directories to list at once: --jobs, or the number of
+// CPUs by default.
+func jobs() int {
+	if walkJobs > 0 {
+		return walkJobs
 	}
+	return runtime.NumCPU()
+}
 
-	// A map to track which directory levels have more items, for drawing the tree with '|'
-	lastInDir := make(map[int]bool)
+// isOSNoise reports whether name is operating system metadata that should be
+// hidden, which is always the case unless --show-os-files is set.
+func isOSNoise(name string) bool {
+	return !showOSFiles && tree.IsOSNoise(name)
+}
 
-	// Process each node (skipping the root which we already output)
-	for i := 1; i < len(sortedNodes); i++ {
-		path := sortedNodes[i]
-		// Get relative path from root
+// filterByMinDepth drops the entries shallower than --min-depth, counting
+// depth the way --depth does. Their parent directories are still drawn above
+// any deeper entry that is kept.
+func filterByMinDepth(root string, paths []string) []string {
+	var kept []string
+	for _, path := range paths {
 		relPath, err := filepath.Rel(root, path)
 		if err != nil {
 			continue
 		}
+		if strings.Count(relPath, string(filepath.Separator)) >= minDepth {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
 
-		// Calculate the depth of this node relative to root
-		parts := strings.Split(relPath, string(filepath.Separator))
-		depth := len(parts) - 1
+// Construct the tree output as a string
+func buildTreeOutput(root string, paths []string) string {
+	return formatTreeRows(buildTreeRows(root, paths))
+}
 
-		// Check if this is the last entry at its depth or in its parent directory
-		isLast := true
-		if i < len(sortedNodes)-1 {
-			nextPath := sortedNodes[i+1]
-			nextRelPath, err := filepath.Rel(root, nextPath)
-			if err == nil {
-				nextParts := strings.Split(nextRelPath, string(filepath.Separator))
-				nextDepth := len(nextParts) - 1
+// buildTreeRows returns one row per node of the tree, starting with the root.
+func buildTreeRows(root string, paths []string) []treeRow {
+	return treeRows(buildTree(root, paths))
+}
 
-				// If next item is at same depth and has same parent, this isn't last
-				if nextDepth == depth && len(parts) > 1 && len(nextParts) > 1 &&
-					parts[0] == nextParts[0] {
-					isLast = false
-				}
-			}
-		}
-		lastInDir[depth] = isLast
+// rootLabel returns the text for the first line of the tree: the --label
+// override if set, otherwise ".", the full path, or the base directory name,
+// as rootLabelStyle decides.
+func rootLabel(root string) string {
+	if treeLabel != "" {
+		return treeLabel
+	}
+	switch rootLabelStyle(root) {
+	case "dot":
+		return "."
+	case "full":
+		return fullPathLabel(root)
+	}
+	return displayName(root)
+}
 
-		// Print indentation
-		for j := 0; j < depth; j++ {
-			if lastInDir[j] {
-				output.WriteString("    ")
-			} else {
-				output.WriteString("│   ")
-			}
+// rootLabelStyle returns how the root line shows root: dot, name, or full.
+// The tree of the working directory, as given by no path argument or ".",
+// is shown as --relative-root-label says; any other root, and the working
+// directory without that flag, as --full-path says.
+func rootLabelStyle(root string) string {
+	if relativeRootLabel != "" {
+		if wd, err := os.Getwd(); err == nil && wd == root {
+			return relativeRootLabel
 		}
+	}
+	if showFullPath {
+		return "full"
+	}
+	return "name"
+}
 
-		// Print branch prefix
-		if isLast {
-			output.WriteString("└── ")
-		} else {
-			output.WriteString("├── ")
-		}
+// validateRelativeRootLabel checks the --relative-root-label setting.
+func validateRelativeRootLabel() error {
+	switch relativeRootLabel {
+	case "", "dot", "name", "full":
+		return nil
+	}
+	return fmt.Errorf("invalid --relative-root-label %q (use dot, name, or full)", relativeRootLabel)
+}
 
-		output.WriteString(filepath.Base(path) + "\n")
+// pathLabel returns the full path of root or its base name, depending on
+// --full-path.
+func pathLabel(root string) string {
+	if showFullPath {
+		return fullPathLabel(root)
 	}
+	return displayName(root)
+}
+
+// fullPathLabel returns the full path of root, anonymized if requested.
+func fullPathLabel(root string) string {
+	if anonymizing() {
+		return anonymizePath(root)
+	}
+	return root
+}
 
-	return output.String()
+// displayName returns the name shown for a node in the tree.
+func displayName(path string) string {
+	if anonymizing() {
+		return anonymizeName(filepath.Base(path))
+	}
+	return filepath.Base(path)
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	err := rootCmd.Execute()
-	if err != nil {
-		os.Exit(1)
+	registerPatternCompletions(rootCmd)
+	markFlagErrors(rootCmd)
+	if code := exitCode(rootCmd.Execute()); code != exitOK {
+		os.Exit(code)
 	}
 }
 
 func init() {
-	rootCmd.Flags().StringSliceVarP(&excludePatterns, "exclude", "e", []string{}, "Glob patterns to exclude (e.g., .git, *.log, node_modules)")
-	rootCmd.Flags().StringSliceVarP(&includePatterns, "include", "i", []string{}, "Glob patterns to include (e.g., .git, *.go, *.md)")
-	rootCmd.Flags().StringVarP(&outputFile, "out", "o", "", "Output to a file instead of the console")
-	rootCmd.Flags().BoolVarP(&copyToClipboard, "copy", "c", false, "Copy the output to the system clipboard")
+	addFilterFlags(rootCmd.Flags())
+	addOutputFlags(rootCmd.Flags())
+	addFormatFlags(rootCmd.Flags())
+	addContentsFlags(rootCmd.Flags())
+	rootCmd.Flags().BoolVarP(&noPager, "no-pager", "", false, "Print long output directly instead of through $PAGER")
 	rootCmd.Flags().BoolVarP(&showPatterns, "show-patterns", "p", false, "Show a guide for using glob patterns")
 	rootCmd.Flags().BoolVarP(&showVersion, "version", "v", false, "Show version information")
-	rootCmd.Flags().BoolVarP(&useSmartDefaults, "smart-defaults", "s", false, "Apply smart defaults based on detected project type")
-	rootCmd.Flags().IntVarP(&maxDepth, "depth", "d", 1, "Set the maximum depth of the directory tree to display (-1 for unlimited). (Default = 1)")
-	rootCmd.Flags().BoolVarP(&showFullPath, "full-path", "f", true, "Show the full directory path of the current working directory. (Default = true)")
 	rootCmd.Flags().BoolVarP(&fullPathOnly, "filepath", "", false, "Show only the full filepath of the specified path (file or folder). Cannot be used with --depth, --exclude, --include, --copy, or --out flags.")
+	rootCmd.Flags().IntVarP(&splitTokens, "split-tokens", "", 0, "Split --contents output into sequential part files of at most N estimated tokens each")
+	rootCmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Keep running and redraw the tree whenever files are created, deleted, or renamed (like the watch command)")
+	rootCmd.Flags().StringVarP(&coveragePath, "coverage", "", "", "Show the coverage of each file and directory from a Go cover profile or lcov file, coloring poorly covered files")
+	rootCmd.Flags().StringVarP(&binaryThreshold, "flag-binaries", "", "", "Mark binary files larger than this size (default "+defaultBinaryThreshold+") in source directories; fails when any are found")
+	rootCmd.Flags().Lookup("flag-binaries").NoOptDefVal = defaultBinaryThreshold
+	rootCmd.Flags().StringArrayVarP(&budgetRules, "budget", "", nil, "Size budget for a directory, as PATH=SIZE relative to the root (e.g. assets=200MB); fails when exceeded")
+	rootCmd.Flags().BoolVarP(&exportViewOnly, "export-view", "", false, "Show only what git archive would include, hiding untracked and export-ignore paths")
+	rootCmd.Flags().StringVarP(&virtualRoot, "virtual-root", "", "", "Render every path argument as a child of a synthetic root node with this name")
+	rootCmd.Flags().StringVarP(&summaryJSON, "summary-json", "", "", "Write a JSON summary of the run (entries scanned, matched, and excluded by each rule, errors, and durations) to this file, or after the output with no file given")
+	rootCmd.Flags().Lookup("summary-json").NoOptDefVal = "-"
+	rootCmd.Flags().BoolVarP(&copyFiles, "copy-files", "", false, "Copy the matched files themselves to the clipboard, to paste into Explorer, Finder, or an email (Windows and macOS)")
+	rootCmd.Flags().StringVarP(&checksumAlgorithm, "hash", "", "", "Show a checksum of each file, computed in parallel: md5, sha1, or sha256")
+	rootCmd.Flags().StringVarP(&checksumMaxSize, "hash-max-size", "", "64M", "Skip hashing files larger than this (e.g. 1G) with --hash, showing - instead (0 for no limit)")
+	rootCmd.Flags().StringVarP(&refQuery, "ref", "", "", "Show only the entry with this path or node ID (as in --format json and dot), its ancestors, and its contents")
+	rootCmd.Flags().BoolVarP(&patternStats, "pattern-stats", "", false, "Report how many entries each exclude, include, smart default, and .wintreeignore pattern matched on stderr, marking unused ones")
+	rootCmd.Flags().BoolVarP(&countTokens, "count-tokens", "", false, "Report the characters and estimated LLM tokens of the matched files' contents, or of the whole output with --contents, on stderr")
+	rootCmd.Flags().StringVarP(&archivePath, "archive", "", "", "Package the matched files into a .zip, .tar.gz, or .tar archive")
+}
+
+// validateFormatFlags checks --format and how it combines with the other
+// flags, returning a flagError for the first problem.
+func validateFormatFlags() error {
+	switch outputFormat {
+	case "tree", "markdown", "markdown-list", "narrative":
+	case "json", "script", "powershell", "dot", "quickfix":
+		if outputFormat == "dot" {
+			if err := validateDotFlags(); err != nil {
+				return asFlagError(err)
+			}
+		}
+		if contentsDump {
+			return flagErrorf("--format %s cannot be used with --contents flag", outputFormat)
+		}
+		if groupByExt {
+			return flagErrorf("--format %s cannot be used with --group-ext flag", outputFormat)
+		}
+		if summaryJSON == "-" {
+			return flagErrorf("--format %s cannot be followed by a summary (use --summary-json=FILE)", outputFormat)
+		}
+		// Editors open the listed paths, so they cannot be disguised
+		if outputFormat == "quickfix" && anonymizing() {
+			return flagErrorf("--format quickfix cannot be used with --anonymize or --anonymize-hash flags")
+		}
+	default:
+		return flagErrorf("invalid --format %q (use tree, json, markdown, markdown-list, narrative, script, powershell, dot, or quickfix)", outputFormat)
+	}
+	return nil
+}
+
+// addFilterFlags registers the flags that control which entries are shown and
+// how the tree is drawn. Subcommands that render trees share them with rootCmd.
+func addFilterFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVarP(&excludePatterns, "exclude", "e", []string{}, "Glob patterns to exclude (e.g., .git, *.log, node_modules)")
+	flags.StringSliceVarP(&includePatterns, "include", "i", []string{}, "Glob patterns to include (e.g., .git, *.go, *.md)")
+	flags.StringArrayVarP(&excludeRegexes, "exclude-regex", "", []string{}, "Regular expression to exclude entries by their path relative to the root (e.g., ^build/, _test\\.go$); repeatable")
+	flags.StringArrayVarP(&includeRegexes, "include-regex", "", []string{}, "Regular expression to include files by their path relative to the root (e.g., ^src/.*\\.go$); repeatable")
+	flags.BoolVarP(&useSmartDefaults, "smart-defaults", "s", false, "Apply smart defaults based on detected project type")
+	flags.BoolVarP(&noIgnoreFile, "no-ignore-file", "", false, "Ignore the .wintreeignore files in the tree, whose patterns are otherwise added to --exclude")
+	flags.BoolVarP(&showOSFiles, "show-os-files", "", false, "Show OS metadata such as .DS_Store, Thumbs.db, and desktop.ini, which are hidden by default")
+	flags.BoolVarP(&followSymlinks, "follow-symlinks", "L", false, "Descend into symlinked directories, skipping any already shown, and mark symlinks as name -> target")
+	flags.IntVarP(&walkJobs, "jobs", "j", 0, "Number of directories to read at once, and of files to hash at once for hash; more can speed up network drives (0 for one per CPU)")
+	maxDepth = 1
+	flags.VarP(depthValue{&maxDepth}, "depth", "d", "Set the maximum depth of the directory tree to display (-1 for unlimited), or a keyword: full (-1), shallow (1), or files-only (0). (Default = 1)")
+	flags.StringVarP(&depthCounts, "depth-counts", "", "all", "What --depth counts at its deepest level: all (files and directories) or dirs (only directories, marking where the tree is cut off)")
+	flags.StringArrayVarP(&customDepthKeywords, "depth-keyword", "", []string{}, "Define or redefine a --depth keyword as NAME=N (e.g. shallow=2); repeatable")
+	flags.StringSliceVarP(&fileTypes, "type", "", nil, "Only show entries of these kinds, as in find -type: f (files), d (dirs), l (symlinks), x (executables); comma-separated")
+	flags.StringVarP(&minFileSize, "min-size", "", "", "Only show files at least this large (e.g. 10k, 5M, 1G), hiding directories left empty")
+	flags.StringVarP(&maxFileSize, "max-size", "", "", "Only show files at most this large (e.g. 10k, 5M, 1G), hiding directories left empty")
+	flags.StringVarP(&samplePercent, "sample", "", "", "Show a random sample of this percentage of the files in each directory (e.g. 5%)")
+	flags.IntVarP(&sampleCount, "sample-n", "", 0, "Show a random sample of at most N files in each directory")
+	flags.Uint64VarP(&sampleSeed, "seed", "", 0, "Seed for --sample and --sample-n; the same seed always picks the same files")
+	flags.IntVarP(&minDepth, "min-depth", "", 0, "Hide entries shallower than N, counted like --depth (0 is the root's immediate children)")
+	flags.IntVarP(&dirsDepth, "dirs-depth", "", 0, "Limit directory recursion to N levels but list every file in the directories shown (overrides --depth)")
+	flags.BoolVarP(&showFullPath, "full-path", "f", true, "Show the full directory path of the current working directory. (Default = true)")
+	flags.StringVarP(&relativeRootLabel, "relative-root-label", "", "", "Root line for the current directory (no path or \".\"): dot (.), name (base name), or full (full path); overrides --full-path")
+	flags.StringVarP(&treeLabel, "label", "", "", "Replace the root line of the tree with a custom label (e.g., the repository name)")
+	flags.StringVarP(&sortOrder, "sort", "", "name", "Order the entries of each directory by name, size (largest first), mtime (newest first), or extension")
+	flags.BoolVarP(&dirsFirst, "dirs-first", "", false, "List the directories in each directory before its files")
+	flags.BoolVarP(&noReport, "no-report", "", false, "Omit the summary of directory and file counts after the tree")
+	flags.BoolVarP(&truncateNames, "truncate", "", false, "Shorten long names with an ellipsis so lines fit the terminal width")
+	flags.StringVarP(&charsetMode, "charset", "", "auto", "Characters to draw the tree with: auto, utf8 (or unicode), or ascii (auto uses ascii on terminals that cannot show UTF-8)")
+	flags.BoolVarP(&asciiChars, "ascii", "", false, "Draw the tree with |-- and `-- instead of box-drawing characters (the same as --charset ascii)")
+	flags.StringVarP(&colorMode, "color", "", "auto", "Color names by type and extension, as set in $LS_COLORS: auto, always, or never (never for --out and --copy)")
+	flags.StringVarP(&iconMode, "icons", "", "never", "Show a file-type icon before each name: auto (the same as --icons alone), always, or never (auto leaves them out of --out and --copy)")
+	flags.Lookup("icons").NoOptDefVal = "auto"
+	flags.StringVarP(&iconSet, "icon-set", "", "emoji", "Icons for --icons: emoji, or nerd for Nerd Font glyphs")
+	flags.StringArrayVarP(&customIcons, "icon", "", []string{}, "Define or redefine an --icons icon as KEY=ICON, where KEY is an extension (.vue), a file name (Makefile), or dir, file, link, or exec; repeatable")
+	flags.IntVarP(&maxLineWidth, "max-width", "", 0, "Shorten long names with an ellipsis so lines fit N columns (implies --truncate)")
+	flags.BoolVarP(&anonymize, "anonymize", "", false, "Replace the home directory and user name in displayed paths for sharing")
+	flags.StringSliceVarP(&anonymizeHashPatterns, "anonymize-hash", "", []string{}, "Replace names matching these glob patterns with a short hash (implies --anonymize)")
+	flags.BoolVarP(&showMtime, "mtime", "", false, "Show the last-modified time of each entry")
+	flags.StringVarP(&timeFormat, "time-format", "", defaultTimeFormat, "Format of --mtime times: iso, date, datetime, unix, locale (the date order of $LC_TIME or the Windows region), or a Go time layout")
+	flags.BoolVarP(&showInodes, "inodes", "", false, "Show the inode number (Unix) or NTFS file ID (Windows) of each entry")
+	flags.BoolVarP(&showPerms, "perms", "", false, "Show the permissions (Unix) or attributes (Windows), owner, and group of each entry before it, as ls -l does")
+	flags.BoolVarP(&showSizes, "size", "", false, "Show the size of each file and the cumulative size of each directory")
+	flags.BoolVarP(&apparentSize, "apparent-size", "", false, "Report file lengths instead of the space allocated on disk (implies --size)")
+	flags.BoolVarP(&siUnits, "si", "", false, "Show sizes in powers of 1000 (kB, MB, GB), as macOS Finder does, instead of 1024 as Explorer and du -h do")
+	flags.BoolVarP(&showSizeBars, "size-bars", "", false, "Show a bar with each directory's share of its parent's size (implies --size)")
+	flags.BoolVarP(&showACL, "acl", "", false, "Append a compact summary of each entry's ACL, e.g. Users:RX (Windows)")
+	flags.StringVarP(&pruneOlderThan, "prune-older-than", "", "", "Collapse directories where no file has changed within this age (e.g. 90d, 6w, 1y) into one line")
+	flags.BoolVarP(&groupByExt, "group-ext", "", false, "Summarize the files in each directory as one line per extension, e.g. .go (12)")
+	flags.BoolVarP(&showLatest, "latest", "", false, "Mark directories with the newest modification time of any file beneath them")
+	flags.BoolVarP(&annotateMeta, "annotate-meta", "", false, "Mark directories with whether they contain a README and a LICENSE")
+	flags.BoolVarP(&showGitStatus, "git-status", "", false, "Mark entries with their git state: modified, staged, added, untracked, ignored, and so on")
+	flags.BoolVarP(&gitClean, "git-clean", "", false, "Hide the entries git ignores")
+	flags.BoolVarP(&showOwners, "owners", "", false, "Mark directories, and files owned differently from their directory, with their owners from CODEOWNERS")
+	flags.StringVarP(&annotateCommand, "annotate-cmd", "", "", "Command that reads the matched entries as JSON on stdin and answers with labels, colors, or entries to hide (see README)")
+	flags.BoolVarP(&describeDirs, "describe", "", false, "Show the first heading of each directory's README next to its name")
+}
+
+// addOutputFlags registers the flags that control where the output goes.
+func addOutputFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&outputFile, "out", "o", "", "Output to a file instead of the console")
+	flags.BoolVarP(&copyToClipboard, "copy", "c", false, "Copy the output to the system clipboard")
+	flags.StringVarP(&lineEndings, "line-endings", "", "auto", "Line endings for --out and --copy: lf, crlf, or auto (crlf on Windows, except for --format script)")
+}
+
+// addFormatFlags registers --format and the flags that style its dot output.
+func addFormatFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&outputFormat, "format", "", "tree", "Output format: tree, json (nested objects), markdown (fenced code block), markdown-list (nested bullets), narrative (plain sentences for screen readers), script (POSIX shell recreating the skeleton), powershell, dot (Graphviz graph), or quickfix (path:1: lines for editors)")
+	addDotFlags(flags)
+}
+
+// addContentsFlags registers the flags that control dumping file contents
+// after the tree.
+func addContentsFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&contentsDump, "contents", "", false, "Append the contents of each matched file after the tree")
+	flags.BoolVarP(&includeNoise, "include-noise", "", false, "Include lockfiles, minified bundles, and source maps in --contents output")
+	flags.BoolVarP(&showCommitInfo, "commit-info", "", false, "Include the last git commit touching each file in --contents headers")
+	flags.StringVarP(&maxDumpSize, "max-file-size", "", defaultMaxDumpSize, "Skip the contents of files larger than this (e.g. 1M) in --contents output, listing them with their size (0 for no limit)")
 }
 
 func printPatternHelp() {
@@ -491,7 +1105,7 @@ EXAMPLES:
 4. Exclude all hidden files and directories (starting with .):
    wintree --exclude ".*"
 
-5. Include files starting with 'test':
+5. Include files and directories starting with 'test':
    wintree --include "test*"
 
 6. Include files ending with specific extensions:
@@ -509,7 +1123,8 @@ EXAMPLES:
 TIPS:
 • You can use multiple --include and --exclude flags
 • Patterns are case-sensitive on Linux/Mac, case-insensitive on Windows
-• Directory names are matched exactly (no glob patterns for directories)
+• An include pattern matching a directory's name lists all of its contents,
+  whatever the other patterns ("test*" lists tests/ and testdata/ in full)
 • File names support full glob pattern matching
 • Exclusions are processed before inclusions
 • Curly brace expansion (*.{go,js}) is supported
@@ -619,6 +1234,9 @@ func getSmartDefaults(projectType string) []string {
 	}
 }
 
+// smartExcludes are the patterns applySmartDefaults added to the excludes.
+var smartExcludes []string
+
 // applySmartDefaults applies smart exclusion patterns based on detected project type
 func applySmartDefaults(path string) {
 	projectType := detectProjectType(path)
@@ -635,10 +1253,9 @@ func applySmartDefaults(path string) {
 		}
 		if !alreadyExists {
 			excludePatterns = append(excludePatterns, defaultPattern)
+			smartExcludes = append(smartExcludes, defaultPattern)
 		}
 	}
 
-	fmt.Printf("🧠 Smart defaults applied for %s project\n", projectType)
-	fmt.Printf("   Excluding: %s\n", strings.Join(smartDefaults, ", "))
-	fmt.Println()
+	info("🧠 Smart defaults applied for %s project\n   Excluding: %s\n", projectType, strings.Join(smartDefaults, ", "))
 }
@@ -147,6 +147,30 @@ func TestProcessFilters(t *testing.T) {
 	}
 }
 
+func TestProcessFiltersRegex(t *testing.T) {
+	defer func() { excludeRegexes, includeRegexes = nil, nil }()
+
+	excludeRegexes = []string{`^build/`, `_test\.go$`}
+	includeRegexes = []string{`^src/.*\.go$`}
+	f := processFilters(nil, nil)
+	if f.err != nil {
+		t.Fatalf("processFilters() err = %v", f.err)
+	}
+	if len(f.excludeRegexps) != 2 || len(f.includeRegexps) != 1 || !f.including() {
+		t.Errorf("processFilters() = %d exclude and %d include regexps, expected 2 and 1", len(f.excludeRegexps), len(f.includeRegexps))
+	}
+
+	// A bad expression fails before anything is walked
+	excludeRegexes = []string{`(unclosed`}
+	f = processFilters(nil, nil)
+	if f.err == nil || !strings.Contains(f.err.Error(), `invalid --exclude-regex "(unclosed"`) {
+		t.Errorf("processFilters() err = %v, expected an invalid --exclude-regex error", f.err)
+	}
+	if err := validateFilterFlags(); err == nil || err.Error() != f.err.Error() || exitCode(err) != exitUsage {
+		t.Errorf("validateFilterFlags() err = %v, expected %v exiting with %d", err, f.err, exitUsage)
+	}
+}
+
 func TestBuildTreeOutput(t *testing.T) {
 	// Create a temporary directory structure for testing
 	tempDir, err := os.MkdirTemp("", "wintree_test")
@@ -425,3 +449,80 @@ func TestBuildTreeOutput_WithFullPath(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildTreeOutput_WithLabel(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wintree_test_label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "file1.go")
+	if err := os.WriteFile(filePath, []byte("test content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalShowFullPath := showFullPath
+	defer func() {
+		showFullPath = originalShowFullPath
+		treeLabel = ""
+	}()
+
+	for _, fullPath := range []bool{true, false} {
+		showFullPath = fullPath
+		treeLabel = "$PROJECT_ROOT"
+
+		for _, paths := range [][]string{{filePath}, {}} {
+			output := buildTreeOutput(tempDir, paths)
+			lines := strings.Split(output, "\n")
+			if lines[0] != "$PROJECT_ROOT" {
+				t.Errorf("First line should be the label (full-path=%v, %d paths): got %q", fullPath, len(paths), lines[0])
+			}
+			if strings.Contains(output, tempDir) {
+				t.Errorf("Output should not contain the local path when a label is set:\n%s", output)
+			}
+		}
+	}
+}
+
+func TestRootLabel_RelativeRoot(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(workingDir, "sub")
+
+	originalShowFullPath := showFullPath
+	defer func() {
+		showFullPath = originalShowFullPath
+		relativeRootLabel = ""
+	}()
+	showFullPath = true
+
+	tests := []struct {
+		style    string
+		expected string
+	}{
+		{"", workingDir},
+		{"dot", "."},
+		{"name", filepath.Base(workingDir)},
+		{"full", workingDir},
+	}
+	for _, tt := range tests {
+		relativeRootLabel = tt.style
+		if label := rootLabel(workingDir); label != tt.expected {
+			t.Errorf("rootLabel() with %q = %q, expected %q", tt.style, label, tt.expected)
+		}
+		// Other roots keep following --full-path
+		if label := rootLabel(other); label != other {
+			t.Errorf("rootLabel() of another directory with %q = %q, expected %q", tt.style, label, other)
+		}
+	}
+
+	relativeRootLabel = "relative"
+	if err := validateRelativeRootLabel(); err == nil {
+		t.Error("validateRelativeRootLabel() accepted an invalid style")
+	}
+}
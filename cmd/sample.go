@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	samplePercent string
+	sampleCount   int
+	sampleSeed    uint64
+)
+
+// sampleFraction parses --sample, such as "5%" or "5", into a fraction of the
+// files in each directory. It returns 0 when --sample is not set.
+func sampleFraction() (float64, error) {
+	if samplePercent == "" {
+		return 0, nil
+	}
+	if sampleCount > 0 {
+		return 0, fmt.Errorf("--sample flag cannot be used with --sample-n flag")
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(samplePercent), "%"), 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		return 0, fmt.Errorf("invalid --sample %q (use a percentage such as 5%%)", samplePercent)
+	}
+	return percent / 100, nil
+}
+
+// sampleFiles keeps a random sample of the files in each directory: the
+// --sample fraction of them, rounded up, or at most --sample-n. Directories
+// are always kept. The sample depends only on --seed and the directory's path,
+// so the same tree gives the same sample on every run.
+func sampleFiles(root string, paths []string, fraction float64) []string {
+	filesByDir := make(map[string][]int)
+	for i, path := range paths {
+		if info, err := lstatCached(path); err == nil && info.IsDir() {
+			continue
+		}
+		dir := filepath.Dir(path)
+		filesByDir[dir] = append(filesByDir[dir], i)
+	}
+
+	dropped := make(map[int]bool)
+	for dir, files := range filesByDir {
+		keep := sampleCount
+		if fraction > 0 {
+			keep = int(math.Ceil(fraction * float64(len(files))))
+		}
+		if keep >= len(files) {
+			continue
+		}
+
+		relDir, err := filepath.Rel(root, dir)
+		if err != nil {
+			relDir = dir
+		}
+		hash := fnv.New64a()
+		hash.Write([]byte(filepath.ToSlash(relDir)))
+		rng := rand.New(rand.NewPCG(sampleSeed, hash.Sum64()))
+
+		for _, i := range rng.Perm(len(files))[keep:] {
+			dropped[files[i]] = true
+		}
+	}
+
+	var kept []string
+	for i, path := range paths {
+		if !dropped[i] {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
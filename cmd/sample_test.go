@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSampleFraction(t *testing.T) {
+	defer func() { samplePercent, sampleCount = "", 0 }()
+
+	tests := []struct {
+		input    string
+		expected float64
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"5%", 0.05, false},
+		{"50", 0.5, false},
+		{"0%", 0, true},
+		{"150%", 0, true},
+		{"lots", 0, true},
+	}
+	for _, tt := range tests {
+		samplePercent = tt.input
+		result, err := sampleFraction()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sampleFraction(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if result != tt.expected {
+			t.Errorf("sampleFraction(%q) = %v, expected %v", tt.input, result, tt.expected)
+		}
+	}
+
+	samplePercent, sampleCount = "5%", 10
+	if _, err := sampleFraction(); err == nil {
+		t.Error("expected an error when both --sample and --sample-n are set")
+	}
+}
+
+func TestSampleFiles(t *testing.T) {
+	defer func() { sampleCount, sampleSeed = 0, 0 }()
+
+	root := t.TempDir()
+	var paths []string
+	for _, dir := range []string{"a", "b"} {
+		for i := 0; i < 20; i++ {
+			paths = append(paths, filepath.Join(root, dir, fmt.Sprintf("file%02d.dat", i)))
+		}
+	}
+
+	countByDir := func(paths []string) map[string]int {
+		counts := make(map[string]int)
+		for _, path := range paths {
+			counts[filepath.Base(filepath.Dir(path))]++
+		}
+		return counts
+	}
+
+	sample := sampleFiles(root, paths, 0.1)
+	if counts := countByDir(sample); counts["a"] != 2 || counts["b"] != 2 {
+		t.Errorf("10%% sample kept %v files per directory, expected 2 each", counts)
+	}
+	if again := sampleFiles(root, paths, 0.1); !reflect.DeepEqual(sample, again) {
+		t.Errorf("sample is not reproducible: %v then %v", sample, again)
+	}
+
+	sampleCount = 5
+	if counts := countByDir(sampleFiles(root, paths, 0)); counts["a"] != 5 || counts["b"] != 5 {
+		t.Errorf("--sample-n 5 kept %v files per directory, expected 5 each", counts)
+	}
+
+	// Directories are never sampled away
+	sampleCount = 1
+	withDir := append([]string{root}, paths[:3]...)
+	if sample := sampleFiles(root, withDir, 0); len(sample) != 2 || sample[0] != root {
+		t.Errorf("sampleFiles() = %v, expected the directory and one file", sample)
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// scriptRenderer writes a script that recreates the directory skeleton of
+// the tree, relative to the directory it is run in: POSIX shell, or
+// PowerShell when shell is "powershell". Files are created empty and existing
+// files are left untouched.
+type scriptRenderer struct {
+	shell string
+}
+
+func (r scriptRenderer) render(root *tree.Node) (string, error) {
+	var entries []treeEntry
+	root.Walk(func(node *tree.Node, relPath string) {
+		if node != root {
+			entries = append(entries, treeEntry{relPath: relPath, isDir: node.IsDir})
+		}
+	})
+	return formatScript(root.Name, entries, r.shell), nil
+}
+
+// formatScript renders entries as a script in the given format, with label
+// naming the tree in its header comment.
+func formatScript(label string, entries []treeEntry, format string) string {
+	dirs := make(map[string]bool)
+	var files []string
+	for _, entry := range entries {
+		if entry.isDir {
+			dirs[entry.relPath] = true
+			continue
+		}
+		files = append(files, entry.relPath)
+		if dir := pathDir(entry.relPath); dir != "." {
+			dirs[dir] = true
+		}
+	}
+
+	// Creating the deepest directories also creates their parents. Ordered
+	// with "/" before any other character, each directory is directly
+	// followed by its children, if it has any.
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	slashFirst := func(dir string) string { return strings.ReplaceAll(dir, "/", "\x00") }
+	sort.Slice(sorted, func(i, j int) bool { return slashFirst(sorted[i]) < slashFirst(sorted[j]) })
+	var leafDirs []string
+	for i, dir := range sorted {
+		if i+1 == len(sorted) || !strings.HasPrefix(sorted[i+1], dir+"/") {
+			leafDirs = append(leafDirs, dir)
+		}
+	}
+	sort.Strings(leafDirs)
+	sort.Strings(files)
+
+	var output strings.Builder
+	if format == "powershell" {
+		output.WriteString("# Recreates the structure of " + label + "\n")
+		output.WriteString("$ErrorActionPreference = 'Stop'\n")
+		for _, dir := range leafDirs {
+			output.WriteString("New-Item -ItemType Directory -Force -Path " + powershellQuote(dir) + " | Out-Null\n")
+		}
+		for _, file := range files {
+			quoted := powershellQuote(file)
+			output.WriteString("if (-not (Test-Path -LiteralPath " + quoted + ")) { New-Item -ItemType File -Path " + quoted + " | Out-Null }\n")
+		}
+		return output.String()
+	}
+
+	output.WriteString("#!/bin/sh\n")
+	output.WriteString("# Recreates the structure of " + label + "\n")
+	output.WriteString("set -e\n")
+	for _, dir := range leafDirs {
+		// Names may start with "-", which must not be taken for options
+		output.WriteString("mkdir -p -- " + shellQuote(dir) + "\n")
+	}
+	for _, file := range files {
+		output.WriteString("touch -- " + shellQuote(file) + "\n")
+	}
+	return output.String()
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powershellQuote quotes s as a PowerShell literal string.
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
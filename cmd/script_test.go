@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScriptRenderer(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"src/lib", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"src/main.go", "it's.txt"} {
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{
+		filepath.Join(root, "docs"),
+		filepath.Join(root, "it's.txt"),
+		filepath.Join(root, "src"),
+		filepath.Join(root, "src", "lib"),
+		filepath.Join(root, "src", "main.go"),
+	}
+
+	originalLabel := treeLabel
+	defer func() { treeLabel = originalLabel }()
+	treeLabel = "project"
+
+	expected := "#!/bin/sh\n" +
+		"# Recreates the structure of project\n" +
+		"set -e\n" +
+		"mkdir -p -- 'docs'\n" +
+		"mkdir -p -- 'src/lib'\n" +
+		"touch -- 'it'\\''s.txt'\n" +
+		"touch -- 'src/main.go'\n"
+	if output, _ := (scriptRenderer{shell: "script"}).render(buildTree(root, paths)); output != expected {
+		t.Errorf("scriptRenderer(script).render() =\n%s\nexpected:\n%s", output, expected)
+	}
+
+	expected = "# Recreates the structure of project\n" +
+		"$ErrorActionPreference = 'Stop'\n" +
+		"New-Item -ItemType Directory -Force -Path 'docs' | Out-Null\n" +
+		"New-Item -ItemType Directory -Force -Path 'src/lib' | Out-Null\n" +
+		"if (-not (Test-Path -LiteralPath 'it''s.txt')) { New-Item -ItemType File -Path 'it''s.txt' | Out-Null }\n" +
+		"if (-not (Test-Path -LiteralPath 'src/main.go')) { New-Item -ItemType File -Path 'src/main.go' | Out-Null }\n"
+	if output, _ := (scriptRenderer{shell: "powershell"}).render(buildTree(root, paths)); output != expected {
+		t.Errorf("scriptRenderer(powershell).render() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestFormatScriptLeafDirs(t *testing.T) {
+	// "a-b" sorts between "a" and "a/c", which must still count as a's child
+	entries := []treeEntry{
+		{relPath: "a", isDir: true},
+		{relPath: "a-b", isDir: true},
+		{relPath: "a/c", isDir: true},
+		{relPath: "-n"},
+	}
+	expected := "#!/bin/sh\n" +
+		"# Recreates the structure of project\n" +
+		"set -e\n" +
+		"mkdir -p -- 'a-b'\n" +
+		"mkdir -p -- 'a/c'\n" +
+		"touch -- '-n'\n"
+	if output := formatScript("project", entries, "script"); output != expected {
+		t.Errorf("formatScript() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
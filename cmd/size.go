@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// sizeCache holds the size of every path measured during the current build,
+// so that nested directories are only walked once.
+var sizeCache = make(map[string]int64)
+
+// sizesShown reports whether any flag that displays sizes is set.
+func sizesShown() bool {
+	return showSizes || apparentSize || showSizeBars
+}
+
+// sizeColumn returns the human-readable size of a node: the file's own size,
+// or the cumulative size of every file beneath a directory, including those
+// hidden by --depth or the filters, as du reports it.
+func sizeColumn(node *tree.Node) string {
+	if node == nil || node.Path == "" {
+		return ""
+	}
+	measureSize(node)
+	if node.Size == nil {
+		return "?"
+	}
+	return formatSize(*node.Size)
+}
+
+// sizeBarWidth is the number of character cells used by a --size-bars bar.
+const sizeBarWidth = 10
+
+// sizeBarColumn returns a bar and percentage showing how much of its parent
+// directory's size a directory takes up, like ncdu. Files, and roots whose
+// parent is not part of the rendered tree, get no bar.
+func sizeBarColumn(node, parent *tree.Node) string {
+	if node == nil || parent == nil || !node.IsDir {
+		return ""
+	}
+	measureSize(node)
+	measureSize(parent)
+	if node.Size == nil || parent.Size == nil || *parent.Size == 0 {
+		return ""
+	}
+
+	fraction := float64(*node.Size) / float64(*parent.Size)
+	return sizeBar(fraction) + fmt.Sprintf(" %5.1f%%", fraction*100)
+}
+
+// sizeBar draws fraction (0 to 1) as a bar of sizeBarWidth cells, using
+// eighth-block characters for the partially filled cell, or whole '#' cells
+// when the output is limited to ASCII.
+func sizeBar(fraction float64) string {
+	if asciiOutput() {
+		cells := int(math.Round(max(0, min(fraction, 1)) * sizeBarWidth))
+		return strings.Repeat("#", cells) + strings.Repeat(" ", sizeBarWidth-cells)
+	}
+
+	const partials = " ▏▎▍▌▋▊▉"
+	eighths := int(math.Round(max(0, min(fraction, 1)) * sizeBarWidth * 8))
+
+	var bar strings.Builder
+	bar.WriteString(strings.Repeat("█", eighths/8))
+	if eighths%8 > 0 {
+		bar.WriteRune([]rune(partials)[eighths%8])
+	}
+	for utf8.RuneCountInString(bar.String()) < sizeBarWidth {
+		bar.WriteString(" ")
+	}
+	return bar.String()
+}
+
+// measureSize records the size of a node on it, unless the node has already
+// been measured or does not exist on disk. A failed measurement leaves Size nil.
+func measureSize(node *tree.Node) {
+	if node.Size != nil || node.Path == "" {
+		return
+	}
+	if size, err := nodeSize(node.Path); err == nil {
+		node.Size = &size
+	}
+}
+
+// nodeSize returns the size of a file or the total size of a directory's
+// contents, measured as allocated or apparent size depending on --apparent-size.
+func nodeSize(path string) (int64, error) {
+	if size, ok := sizeCache[path]; ok {
+		return size, nil
+	}
+
+	info, err := lstatCached(path)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		sizeCache[path] = fileSize(path, info)
+		return sizeCache[path], nil
+	}
+
+	// Add every file to all of its ancestors up to path, caching every
+	// subdirectory total on the way
+	totals := map[string]int64{path: 0}
+	walkErr := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are left out of the total rather than failing the tree
+			if d != nil && d.IsDir() && p != path {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			totals[p] += 0
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size := fileSize(p, info)
+		totals[p] = size
+		for dir := filepath.Dir(p); ; dir = filepath.Dir(dir) {
+			totals[dir] += size
+			if dir == path {
+				break
+			}
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return 0, walkErr
+	}
+
+	for p, size := range totals {
+		sizeCache[p] = size
+	}
+	return totals[path], nil
+}
+
+// fileSize returns the apparent size of a file with --apparent-size, and
+// otherwise the space it occupies on disk, which is smaller for sparse and
+// compressed files and larger for small files that fill a whole block.
+func fileSize(path string, info os.FileInfo) int64 {
+	if apparentSize {
+		return info.Size()
+	}
+	if size, ok := allocatedSize(path, info); ok {
+		return size
+	}
+	return info.Size()
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestNodeSize_Cumulative(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]int{
+		"a.txt":           100,
+		"sub/b.txt":       200,
+		"sub/deeper/c.go": 300,
+	}
+	for name, size := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	apparentSize = true
+	defer func() {
+		apparentSize = false
+		clear(sizeCache)
+	}()
+
+	tests := []struct {
+		path     string
+		expected int64
+	}{
+		{tempDir, 600},
+		{filepath.Join(tempDir, "sub"), 500},
+		{filepath.Join(tempDir, "sub", "deeper"), 300},
+		{filepath.Join(tempDir, "a.txt"), 100},
+		{filepath.Join(tempDir, "empty"), 0},
+	}
+
+	for _, tt := range tests {
+		size, err := nodeSize(tt.path)
+		if err != nil {
+			t.Fatalf("nodeSize(%s) error = %v", tt.path, err)
+		}
+		if size != tt.expected {
+			t.Errorf("nodeSize(%s) = %d, expected %d", tt.path, size, tt.expected)
+		}
+	}
+
+	if got := sizeColumn(&tree.Node{Name: "virtual", IsDir: true}); got != "" {
+		t.Errorf("sizeColumn() for a virtual node = %q, expected empty", got)
+	}
+}
+
+func TestFileSize_Sparse(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("files are not sparse on Windows unless explicitly marked")
+	}
+
+	path := filepath.Join(t.TempDir(), "sparse.img")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(64 << 20); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if allocated := fileSize(path, info); allocated >= info.Size() {
+		t.Skipf("filesystem does not support sparse files (allocated %d bytes)", allocated)
+	}
+
+	apparentSize = true
+	defer func() { apparentSize = false }()
+	if apparent := fileSize(path, info); apparent != 64<<20 {
+		t.Errorf("fileSize() with --apparent-size = %d, expected %d", apparent, 64<<20)
+	}
+}
+
+func TestSizeBar(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		expected string
+	}{
+		{0, "          "},
+		{0.5, "█████     "},
+		{0.55, "█████▌    "},
+		{1, "██████████"},
+		{1.5, "██████████"},
+	}
+
+	for _, tt := range tests {
+		if result := sizeBar(tt.fraction); result != tt.expected {
+			t.Errorf("sizeBar(%v) = %q, expected %q", tt.fraction, result, tt.expected)
+		}
+	}
+}
+
+func TestSizeBarColumn(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, size := range map[string]int{"big/a.bin": 300, "small/b.bin": 100} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apparentSize = true
+	defer func() {
+		apparentSize = false
+		clear(sizeCache)
+	}()
+
+	root := &tree.Node{Path: tempDir, IsDir: true}
+	big := &tree.Node{Path: filepath.Join(tempDir, "big"), IsDir: true}
+	small := &tree.Node{Path: filepath.Join(tempDir, "small"), IsDir: true}
+	file := &tree.Node{Path: filepath.Join(tempDir, "small", "b.bin")}
+
+	if result := sizeBarColumn(big, root); result != "███████▌    75.0%" {
+		t.Errorf("sizeBarColumn(big) = %q", result)
+	}
+	if result := sizeBarColumn(file, small); result != "" {
+		t.Errorf("files should not get a bar, got %q", result)
+	}
+	if result := sizeBarColumn(root, nil); result != "" {
+		t.Errorf("the root should not get a bar, got %q", result)
+	}
+}
+
+func TestBuildTree_Sizes(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, size := range map[string]int{"src/a.go": 300, "src/b.go": 100, "README.md": 50} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	apparentSize = true
+	defer func() {
+		apparentSize = false
+		clear(sizeCache)
+	}()
+
+	paths := []string{
+		filepath.Join(tempDir, "README.md"),
+		filepath.Join(tempDir, "src"),
+		filepath.Join(tempDir, "src", "a.go"),
+	}
+	output, err := jsonRenderer{}.render(buildTree(tempDir, paths))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root tree.JSONNode
+	if err := json.Unmarshal([]byte(output), &root); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, output)
+	}
+
+	// Directories count every file beneath them, including those filtered out
+	sizes := map[string]int64{}
+	var collect func(node *tree.JSONNode)
+	collect = func(node *tree.JSONNode) {
+		if node.Size == nil {
+			t.Errorf("node %s has no size", node.Path)
+		} else {
+			sizes[node.Path] = *node.Size
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(&root)
+
+	expected := map[string]int64{".": 450, "README.md": 50, "src": 400, "src/a.go": 300}
+	for path, size := range expected {
+		if sizes[path] != size {
+			t.Errorf("size of %s = %d, expected %d", path, sizes[path], size)
+		}
+	}
+}
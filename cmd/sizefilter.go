@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// minFileSize and maxFileSize are the --min-size and --max-size values, such
+// as "10k" or "5M". Empty means no limit.
+var minFileSize, maxFileSize string
+
+// sizeRange returns the --min-size and --max-size limits in bytes, with -1
+// for a limit that is not set.
+func sizeRange() (minSize, maxSize int64, err error) {
+	minSize, maxSize = -1, -1
+	if minFileSize != "" {
+		if minSize, err = parseSize(minFileSize); err != nil {
+			return 0, 0, fmt.Errorf("invalid --min-size: %w", err)
+		}
+	}
+	if maxFileSize != "" {
+		if maxSize, err = parseSize(maxFileSize); err != nil {
+			return 0, 0, fmt.Errorf("invalid --max-size: %w", err)
+		}
+	}
+	if minSize >= 0 && maxSize >= 0 && minSize > maxSize {
+		return 0, 0, fmt.Errorf("--min-size %s is larger than --max-size %s", minFileSize, maxFileSize)
+	}
+	return minSize, maxSize, nil
+}
+
+// filterBySize keeps the files whose length is within the size range.
+// Directories left without any entries are dropped, but directories that had
+// none to begin with, such as those at the depth limit, are kept. paths must
+// list every directory before its contents, as the walk does.
+func filterBySize(paths []string, minSize, maxSize int64) []string {
+	hadEntries := make(map[string]bool)
+	for _, path := range paths {
+		hadEntries[filepath.Dir(path)] = true
+	}
+
+	keep := make([]bool, len(paths))
+	hasKept := make(map[string]bool)
+	// Contents come after their directory, so walk backwards to settle them first
+	for i := len(paths) - 1; i >= 0; i-- {
+		path := paths[i]
+		info, err := lstatCached(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			keep[i] = hasKept[path] || !hadEntries[path]
+		} else {
+			size := info.Size()
+			keep[i] = (minSize < 0 || size >= minSize) && (maxSize < 0 || size <= maxSize)
+		}
+		if keep[i] {
+			hasKept[filepath.Dir(path)] = true
+		}
+	}
+
+	var kept []string
+	for i, path := range paths {
+		if keep[i] {
+			kept = append(kept, path)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilterBySize(t *testing.T) {
+	tempDir := t.TempDir()
+	for file, size := range map[string]int{
+		"big/video.mp4":        20 << 10,
+		"small/notes.txt":      10,
+		"mixed/large.bin":      8 << 10,
+		"mixed/tiny.txt":       1,
+		"mixed/nested/log.txt": 2,
+		"top.txt":              4 << 10,
+	} {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, relPath := range []string{
+		"big", "big/video.mp4",
+		"empty",
+		"mixed", "mixed/large.bin", "mixed/nested", "mixed/nested/log.txt", "mixed/tiny.txt",
+		"small", "small/notes.txt",
+		"top.txt",
+	} {
+		paths = append(paths, filepath.Join(tempDir, filepath.FromSlash(relPath)))
+	}
+
+	tests := []struct {
+		name             string
+		minSize, maxSize int64
+		expected         []string
+	}{
+		{"min", 4 << 10, -1, []string{"big", "big/video.mp4", "empty", "mixed", "mixed/large.bin", "top.txt"}},
+		{"max", -1, 100, []string{"empty", "mixed", "mixed/nested", "mixed/nested/log.txt", "mixed/tiny.txt", "small", "small/notes.txt"}},
+		{"range", 2, 8 << 10, []string{"empty", "mixed", "mixed/large.bin", "mixed/nested", "mixed/nested/log.txt", "small", "small/notes.txt", "top.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept := filterBySize(paths, tt.minSize, tt.maxSize)
+			var relPaths []string
+			for _, path := range kept {
+				relPath, _ := filepath.Rel(tempDir, path)
+				relPaths = append(relPaths, filepath.ToSlash(relPath))
+			}
+			if !reflect.DeepEqual(relPaths, tt.expected) {
+				t.Errorf("filterBySize() = %v, expected %v", relPaths, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSizeRange(t *testing.T) {
+	originalMin, originalMax := minFileSize, maxFileSize
+	defer func() { minFileSize, maxFileSize = originalMin, originalMax }()
+
+	minFileSize, maxFileSize = "10k", "5M"
+	minSize, maxSize, err := sizeRange()
+	if err != nil || minSize != 10<<10 || maxSize != 5<<20 {
+		t.Errorf("sizeRange() = %d, %d, %v, expected %d, %d", minSize, maxSize, err, 10<<10, 5<<20)
+	}
+
+	minFileSize, maxFileSize = "", ""
+	if minSize, maxSize, err = sizeRange(); err != nil || minSize != -1 || maxSize != -1 {
+		t.Errorf("sizeRange() without limits = %d, %d, %v, expected -1, -1", minSize, maxSize, err)
+	}
+
+	for _, invalid := range [][2]string{{"ten", ""}, {"", "5X"}, {"5M", "10k"}} {
+		minFileSize, maxFileSize = invalid[0], invalid[1]
+		if _, _, err := sizeRange(); err == nil {
+			t.Errorf("sizeRange() with %q, %q expected an error", invalid[0], invalid[1])
+		}
+	}
+}
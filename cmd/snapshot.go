@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotVersion is the current version of the snapshot file format.
+// Bump it whenever snapshotEntry changes incompatibly.
+const snapshotVersion = 1
+
+var (
+	snapshotOutput string
+	snapshotHashes bool
+	snapshotXattrs bool
+)
+
+// snapshot records the structure of a directory tree so it can be compared
+// against the live tree later.
+type snapshot struct {
+	Version int             `json:"version"`
+	Root    string          `json:"root"`
+	Created time.Time       `json:"created"`
+	Hashes  bool            `json:"hashes"`
+	Xattrs  bool            `json:"xattrs"`
+	Entries []snapshotEntry `json:"entries"`
+}
+
+// snapshotEntry is a single file, directory, or symlink in a snapshot. Path is
+// slash-separated and relative to the snapshot root.
+type snapshotEntry struct {
+	Path    string            `json:"path"`
+	Type    string            `json:"type"`
+	Size    int64             `json:"size,omitempty"`
+	Mode    string            `json:"mode"`
+	ModTime time.Time         `json:"mtime"`
+	SHA256  string            `json:"sha256,omitempty"`
+	Xattrs  map[string]string `json:"xattrs,omitempty"`
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Record directory structure for later comparison.",
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save [path]",
+	Short: "Save a snapshot of the tree as versioned JSON.",
+	Long: `Save a snapshot of every matched entry's path, type, size, mode, and
+modification time as versioned JSON. Use --hash to record SHA-256 content
+hashes and --xattrs to record extended attributes (including POSIX ACLs on
+Linux). Compare the tree against the snapshot later with "wintree snapshot
+compare".
+
+Unlike the tree view, snapshots include the whole tree unless --depth is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		startPath, err := resolveStartPath(args)
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("depth") {
+			maxDepth = -1
+		}
+
+		if useSmartDefaults {
+			applySmartDefaults(startPath)
+		}
+
+		snap, err := takeSnapshot(startPath, processFilters(excludePatterns, includePatterns), snapshotHashes, snapshotXattrs)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(snap, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+
+		if snapshotOutput == "" {
+			fmt.Print(string(data))
+			return nil
+		}
+		if err := os.WriteFile(snapshotOutput, data, 0644); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		fmt.Printf("Snapshot of %d entries written to %s\n", len(snap.Entries), snapshotOutput)
+		return nil
+	},
+}
+
+// takeSnapshot records every entry under root that passes the filters.
+func takeSnapshot(root string, filters filter, hashes, xattrs bool) (*snapshot, error) {
+	matchingFiles, err := findMatchingFiles(root, filters)
+	if err != nil {
+		return nil, fmt.Errorf("error finding files: %w", err)
+	}
+
+	snap := &snapshot{
+		Version: snapshotVersion,
+		Root:    root,
+		Created: time.Now().UTC(),
+		Hashes:  hashes,
+		Xattrs:  xattrs,
+	}
+
+	for _, path := range matchingFiles {
+		entry, err := newSnapshotEntry(root, path, hashes, xattrs)
+		if err != nil {
+			return nil, err
+		}
+		snap.Entries = append(snap.Entries, entry)
+	}
+
+	sort.Slice(snap.Entries, func(i, j int) bool {
+		return snap.Entries[i].Path < snap.Entries[j].Path
+	})
+
+	return snap, nil
+}
+
+// newSnapshotEntry stats a single path without following symlinks.
+func newSnapshotEntry(root, path string, hashes, xattrs bool) (snapshotEntry, error) {
+	info, err := lstatCached(path)
+	if err != nil {
+		return snapshotEntry{}, err
+	}
+
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return snapshotEntry{}, err
+	}
+
+	entry := snapshotEntry{
+		Path:    filepath.ToSlash(relPath),
+		Type:    entryType(info),
+		Mode:    info.Mode().Perm().String(),
+		ModTime: info.ModTime().UTC(),
+	}
+
+	if entry.Type == "file" {
+		entry.Size = info.Size()
+		if hashes {
+			if entry.SHA256, err = hashFile(path); err != nil {
+				return snapshotEntry{}, fmt.Errorf("failed to hash %s: %w", path, err)
+			}
+		}
+	}
+
+	if xattrs {
+		if entry.Xattrs, err = readXattrs(path); err != nil {
+			return snapshotEntry{}, fmt.Errorf("failed to read extended attributes of %s: %w", path, err)
+		}
+	}
+
+	return entry, nil
+}
+
+// entryType classifies a file mode as "dir", "symlink", "file", or "other".
+func entryType(info os.FileInfo) string {
+	switch {
+	case info.IsDir():
+		return "dir"
+	case info.Mode()&os.ModeSymlink != 0:
+		return "symlink"
+	case info.Mode().IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
+
+// hashFile returns the hex-encoded SHA-256 of a file's contents.
+func hashFile(path string) (string, error) {
+	return hashFileWith(path, sha256.New)
+}
+
+// hashFileWith returns the hex-encoded hash of a file's contents, computed
+// with the hash newHash returns.
+func hashFileWith(path string, newHash func() hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadSnapshot reads a snapshot file, rejecting versions newer than this build
+// understands.
+func loadSnapshot(path string) (*snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	if snap.Version < 1 || snap.Version > snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (this build supports up to %d)", snap.Version, snapshotVersion)
+	}
+
+	return &snap, nil
+}
+
+func init() {
+	addFilterFlags(snapshotSaveCmd.Flags())
+	snapshotSaveCmd.Flags().StringVarP(&snapshotOutput, "out", "o", "", "Write the snapshot to a file instead of the console")
+	snapshotSaveCmd.Flags().BoolVarP(&snapshotHashes, "hash", "", false, "Record SHA-256 hashes of file contents")
+	snapshotSaveCmd.Flags().BoolVarP(&snapshotXattrs, "xattrs", "", false, "Record extended attributes and ACLs (Linux and macOS)")
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	testDir := setupTestDirectory(t)
+	defer os.RemoveAll(testDir)
+
+	originalMaxDepth := maxDepth
+	defer func() { maxDepth = originalMaxDepth }()
+	maxDepth = -1
+
+	snap, err := takeSnapshot(testDir, processFilters([]string{"node_modules"}, []string{}), true, false)
+	if err != nil {
+		t.Fatalf("takeSnapshot() error = %v", err)
+	}
+
+	if snap.Version != snapshotVersion {
+		t.Errorf("snapshot version = %d, expected %d", snap.Version, snapshotVersion)
+	}
+
+	entries := make(map[string]snapshotEntry)
+	for i, entry := range snap.Entries {
+		entries[entry.Path] = entry
+		if i > 0 && snap.Entries[i-1].Path >= entry.Path {
+			t.Errorf("snapshot entries not sorted: %q before %q", snap.Entries[i-1].Path, entry.Path)
+		}
+	}
+
+	mainGo, ok := entries["main.go"]
+	if !ok {
+		t.Fatal("snapshot missing main.go")
+	}
+	if mainGo.Type != "file" || mainGo.Size != int64(len("package main")) {
+		t.Errorf("main.go entry = %+v, expected a file of %d bytes", mainGo, len("package main"))
+	}
+	// sha256("package main")
+	if mainGo.SHA256 != "512843855fcc92a51c810b1b58e0731c01eac9a6a23c157bfa02aad71edffbe7" {
+		t.Errorf("main.go hash = %q, expected sha256 of its contents", mainGo.SHA256)
+	}
+
+	if entries["src"].Type != "dir" {
+		t.Errorf("src entry type = %q, expected dir", entries["src"].Type)
+	}
+	if _, ok := entries["src/app.go"]; !ok {
+		t.Error("snapshot should use slash-separated relative paths")
+	}
+	if _, ok := entries["node_modules/package/index.js"]; ok {
+		t.Error("snapshot should respect exclude filters")
+	}
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "wintree_snapshot_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	write := func(name string, snap snapshot) string {
+		data, err := json.Marshal(snap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("current version", func(t *testing.T) {
+		path := write("current.json", snapshot{Version: snapshotVersion, Entries: []snapshotEntry{{Path: "a.txt", Type: "file"}}})
+		snap, err := loadSnapshot(path)
+		if err != nil {
+			t.Fatalf("loadSnapshot() error = %v", err)
+		}
+		if len(snap.Entries) != 1 || snap.Entries[0].Path != "a.txt" {
+			t.Errorf("loadSnapshot() entries = %+v", snap.Entries)
+		}
+	})
+
+	t.Run("future version", func(t *testing.T) {
+		path := write("future.json", snapshot{Version: snapshotVersion + 1})
+		if _, err := loadSnapshot(path); err == nil {
+			t.Error("expected error for unsupported snapshot version")
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(tempDir, "invalid.json")
+		if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadSnapshot(path); err == nil {
+			t.Error("expected error for invalid snapshot")
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// estimateTokens approximates the number of LLM tokens in s using the common
+// heuristic of roughly four characters per token.
+func estimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// splitContents packs the tree and content sections into parts that each stay
+// within budget tokens. Every part starts with the tree so it can be read on
+// its own. Sections too large for a single part are split on line boundaries,
+// and lines too long for a part are cut. It fails if the tree alone does not
+// fit in budget.
+func splitContents(tree string, sections []contentSection, budget int) ([]string, error) {
+	// Reserve room for the "[part N of M]" marker as well as the tree
+	headerTokens := estimateTokens(tree) + estimateTokens("[part 000 of 000]\n")
+	available := budget - headerTokens
+	if available <= 0 {
+		return nil, fmt.Errorf("the tree alone takes ~%d tokens, more than the --split-tokens budget of %d", headerTokens, budget)
+	}
+
+	var chunks [][]string
+	var current []string
+	currentTokens := 0
+
+	for _, section := range sections {
+		pieces, err := splitSection(section, available)
+		if err != nil {
+			return nil, err
+		}
+		for _, piece := range pieces {
+			pieceTokens := estimateTokens(piece)
+			if len(current) > 0 && currentTokens+pieceTokens > available {
+				chunks = append(chunks, current)
+				current = nil
+				currentTokens = 0
+			}
+			current = append(current, piece)
+			currentTokens += pieceTokens
+		}
+	}
+	if len(current) > 0 || len(chunks) == 0 {
+		chunks = append(chunks, current)
+	}
+
+	parts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		parts[i] = fmt.Sprintf("[part %d of %d]\n", i+1, len(chunks)) + tree + strings.Join(chunk, "")
+	}
+	return parts, nil
+}
+
+// splitSection breaks a single content section into pieces of at most budget
+// tokens. Each piece closes its code fence, and every piece after the first
+// reopens it under a "(continued)" header. A line too long for a piece of its
+// own is cut where the budget runs out and carried on in the next piece.
+func splitSection(section contentSection, budget int) ([]string, error) {
+	full := section.String()
+	if estimateTokens(full) <= budget {
+		return []string{full}, nil
+	}
+
+	// Pieces are measured in runes, which is what estimateTokens counts, so
+	// that the running total never has to be recounted
+	limit := budget * 4
+	footer, continued := section.footer(), section.continuedHeader()
+	footerRunes, continuedRunes := utf8.RuneCountInString(footer), utf8.RuneCountInString(continued)
+	runes := utf8.RuneCountInString(section.header)
+	if max(runes, continuedRunes)+footerRunes >= limit {
+		return nil, fmt.Errorf("the --split-tokens budget leaves no room for the contents of %s beside the tree", section.relPath)
+	}
+
+	var pieces []string
+	var current strings.Builder
+	current.WriteString(section.header)
+	currentHasBody := false
+
+	flush := func() {
+		current.WriteString(footer)
+		pieces = append(pieces, current.String())
+		current.Reset()
+		current.WriteString(continued)
+		runes = continuedRunes
+		currentHasBody = false
+	}
+
+	for _, line := range strings.SplitAfter(section.body, "\n") {
+		for line != "" {
+			lineRunes := utf8.RuneCountInString(line)
+			if runes+lineRunes+footerRunes <= limit {
+				current.WriteString(line)
+				runes += lineRunes
+				currentHasBody = true
+				break
+			}
+			if currentHasBody {
+				flush()
+				continue
+			}
+
+			cut := 0
+			for n := limit - runes - footerRunes; n > 0; n-- {
+				_, size := utf8.DecodeRuneInString(line[cut:])
+				cut += size
+			}
+			current.WriteString(line[:cut])
+			line = line[cut:]
+			currentHasBody = true
+			flush()
+		}
+	}
+	current.WriteString(footer)
+	pieces = append(pieces, current.String())
+
+	return pieces, nil
+}
+
+// splitPartPath returns the file name for the n-th part of a split dump. With
+// no --out file the parts are named part1.md, part2.md, and so on; otherwise the
+// part number is inserted before the output file's extension.
+func splitPartPath(out string, n int) string {
+	if out == "" {
+		return fmt.Sprintf("part%d.md", n)
+	}
+	ext := filepath.Ext(out)
+	return fmt.Sprintf("%s.part%d%s", strings.TrimSuffix(out, ext), n, ext)
+}
+
+// writeSplitDump renders the tree and content sections for the matched files
+// and writes them as sequential part files.
+func writeSplitDump(startPath string, matchingFiles []string) error {
+	sections, err := collectContentSections(startPath, matchingFiles)
+	if err != nil {
+		return fmt.Errorf("error dumping file contents: %w", err)
+	}
+
+	parts, err := splitContents(buildTreeOutput(startPath, matchingFiles), sections, splitTokens)
+	if err != nil {
+		return err
+	}
+	return writeSplitParts(parts)
+}
+
+// writeSplitParts writes each part to its own sequential file.
+func writeSplitParts(parts []string) error {
+	for i, part := range parts {
+		path := splitPartPath(outputFile, i+1)
+		converted, err := convertLineEndings(part)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(converted), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("Part %d of %d written to %s (~%d tokens)\n", i+1, len(parts), path, estimateTokens(part))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("x", 400), 100},
+	}
+
+	for _, tt := range tests {
+		if result := estimateTokens(tt.input); result != tt.expected {
+			t.Errorf("estimateTokens(%q) = %d, expected %d", tt.input, result, tt.expected)
+		}
+	}
+}
+
+// testSection builds a content section the way collectContentSections does.
+func testSection(relPath, body string) contentSection {
+	return contentSection{
+		relPath: relPath,
+		lang:    "go",
+		fence:   "```",
+		header:  "\n=== " + relPath + " ===\n```go\n",
+		body:    body,
+	}
+}
+
+func TestSplitContents(t *testing.T) {
+	tree := "project\n└── a.go\n"
+	sections := []contentSection{
+		testSection("a.go", strings.Repeat("a", 200)+"\n"),
+		testSection("b.go", strings.Repeat("b", 200)+"\n"),
+		testSection("c.go", strings.Repeat("c", 200)+"\n"),
+	}
+
+	t.Run("everything fits", func(t *testing.T) {
+		parts, err := splitContents(tree, sections, 10000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != 1 {
+			t.Fatalf("splitContents() returned %d parts, expected 1", len(parts))
+		}
+		if !strings.HasPrefix(parts[0], "[part 1 of 1]\n"+tree) {
+			t.Errorf("part should start with marker and tree, got:\n%s", parts[0])
+		}
+	})
+
+	t.Run("one section per part", func(t *testing.T) {
+		parts, err := splitContents(tree, sections, 80)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) != 3 {
+			t.Fatalf("splitContents() returned %d parts, expected 3", len(parts))
+		}
+		for i, part := range parts {
+			if !strings.Contains(part, tree) {
+				t.Errorf("part %d does not repeat the tree header", i+1)
+			}
+			if estimateTokens(part) > 80 {
+				t.Errorf("part %d has ~%d tokens, over the budget of 80", i+1, estimateTokens(part))
+			}
+		}
+	})
+
+	t.Run("oversized section is split on lines", func(t *testing.T) {
+		big := testSection("big.txt", strings.Repeat(strings.Repeat("z", 39)+"\n", 20))
+		parts, err := splitContents(tree, []contentSection{big}, 80)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) < 2 {
+			t.Fatalf("splitContents() returned %d parts, expected the section to be split", len(parts))
+		}
+		if !strings.Contains(parts[1], "=== big.txt (continued) ===\n```go\n") {
+			t.Errorf("continuation part missing continued header:\n%s", parts[1])
+		}
+		for i, part := range parts {
+			if strings.Count(part, "```")%2 != 0 {
+				t.Errorf("part %d leaves a code fence unclosed:\n%s", i+1, part)
+			}
+		}
+		total := 0
+		for _, part := range parts {
+			total += strings.Count(part, strings.Repeat("z", 39))
+		}
+		if total != 20 {
+			t.Errorf("split parts contain %d lines, expected 20", total)
+		}
+	})
+
+	t.Run("oversized line is cut", func(t *testing.T) {
+		long := strings.Repeat("é", 500)
+		parts, err := splitContents(tree, []contentSection{testSection("long.txt", long+"\n")}, 80)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parts) < 2 {
+			t.Fatalf("splitContents() returned %d parts, expected the line to be cut", len(parts))
+		}
+		total := 0
+		for i, part := range parts {
+			if estimateTokens(part) > 80 {
+				t.Errorf("part %d has ~%d tokens, over the budget of 80", i+1, estimateTokens(part))
+			}
+			if !utf8.ValidString(part) {
+				t.Errorf("part %d cuts a character in half", i+1)
+			}
+			total += strings.Count(part, "é")
+		}
+		if total != 500 {
+			t.Errorf("split parts contain %d characters of the line, expected 500", total)
+		}
+	})
+
+	t.Run("tree over the budget", func(t *testing.T) {
+		if _, err := splitContents(strings.Repeat("x", 400), sections, 80); err == nil {
+			t.Error("splitContents() accepted a tree larger than the budget")
+		}
+		if _, err := splitContents(tree, sections, 15); err == nil {
+			t.Error("splitContents() accepted a budget with no room for a section header")
+		}
+	})
+}
+
+func TestSplitPartPath(t *testing.T) {
+	tests := []struct {
+		out      string
+		n        int
+		expected string
+	}{
+		{"", 1, "part1.md"},
+		{"", 12, "part12.md"},
+		{"dump.md", 2, "dump.part2.md"},
+		{"context", 3, "context.part3"},
+	}
+
+	for _, tt := range tests {
+		if result := splitPartPath(tt.out, tt.n); result != tt.expected {
+			t.Errorf("splitPartPath(%q, %d) = %q, expected %q", tt.out, tt.n, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"io/fs"
+	"os"
+)
+
+// walkEntries holds the directory entries seen by the last findMatchingFiles
+// walk, keyed by path. Directory listings already carry each entry's
+// metadata on Windows, so reusing them avoids a round trip per file, which
+// dominates on network shares. Elsewhere the entry stats itself on demand,
+// exactly as os.Lstat would.
+var walkEntries = make(map[string]fs.DirEntry)
+
+// lstatCached returns the metadata of path without following symlinks, from
+// the last walk if it saw the path.
+func lstatCached(path string) (os.FileInfo, error) {
+	if entry, ok := walkEntries[path]; ok {
+		if info, err := entry.Info(); err == nil {
+			return info, nil
+		}
+	}
+	return os.Lstat(path)
+}
+
+// statCached is like lstatCached but follows symlinks, as os.Stat does.
+func statCached(path string) (os.FileInfo, error) {
+	info, err := lstatCached(path)
+	if err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return os.Stat(path)
+	}
+	return info, err
+}
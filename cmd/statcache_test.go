@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatCached(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "file.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalMaxDepth := maxDepth
+	defer func() {
+		maxDepth = originalMaxDepth
+		clear(walkEntries)
+	}()
+	maxDepth = -1
+
+	if _, err := findMatchingFiles(tempDir, processFilters([]string{}, []string{})); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := walkEntries[path]; !ok {
+		t.Fatalf("findMatchingFiles() should record the entries it walks")
+	}
+
+	info, err := statCached(path)
+	if err != nil {
+		t.Fatalf("statCached() error = %v", err)
+	}
+	if info.Size() != 5 || info.Name() != "file.txt" {
+		t.Errorf("statCached() = %s (%d bytes), expected file.txt (5 bytes)", info.Name(), info.Size())
+	}
+
+	// Paths the walk never saw fall back to a direct stat
+	if _, err := lstatCached(filepath.Join(tempDir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("lstatCached() of a missing path error = %v, expected not exist", err)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// streamingOutput reports whether the tree can be written to the console as
+// it is drawn, which holds unless the whole output is needed at once: for the
+// clipboard, a file, the contents of the files, a summary, or a token count,
+// or by a format that is only rendered whole.
+func streamingOutput() bool {
+	if copyToClipboard || outputFile != "" || contentsDump || summaryJSON != "" || countTokens {
+		return false
+	}
+	_, ok := rendererFor(outputFormat).(streamer)
+	return ok
+}
+
+// streamOutput builds the tree of the matched files and writes it to the
+// console, or the pager when it is taller than the terminal, line by line.
+// Only the drawing streams: the whole tree is built before the first line.
+func streamOutput(startPath string, matchingFiles []string) error {
+	top := buildTree(startPath, matchingFiles)
+
+	var output io.Writer = os.Stdout
+	// One line per entry, the root, and the report below them
+	dirs, files := top.Counts()
+	if pager := openPager(dirs + files + 3); pager != nil {
+		defer pager.Close()
+		output = pager
+	}
+	buffered := bufio.NewWriter(output)
+	if err := rendererFor(outputFormat).(streamer).stream(buffered, top); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
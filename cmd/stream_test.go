@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestStreamingOutput(t *testing.T) {
+	originalFormat, originalFile := outputFormat, outputFile
+	defer func() {
+		outputFormat, outputFile, contentsDump = originalFormat, originalFile, false
+	}()
+
+	tests := []struct {
+		format   string
+		file     string
+		contents bool
+		expected bool
+	}{
+		{"tree", "", false, true},
+		{"quickfix", "", false, true},
+		{"json", "", false, false},
+		{"tree", "tree.txt", false, false},
+		{"tree", "", true, false},
+	}
+	for _, tt := range tests {
+		outputFormat, outputFile, contentsDump = tt.format, tt.file, tt.contents
+		if result := streamingOutput(); result != tt.expected {
+			t.Errorf("streamingOutput() with --format %s, --out %q, and --contents %v = %v, expected %v", tt.format, tt.file, tt.contents, result, tt.expected)
+		}
+	}
+}
+
+func TestTextRendererStream(t *testing.T) {
+	root := &tree.Node{Name: "project", IsDir: true, Children: []*tree.Node{
+		{Name: "main.go"},
+		{Name: "src", IsDir: true, Children: []*tree.Node{{Name: "app.go"}}},
+	}}
+
+	var streamed strings.Builder
+	if err := (textRenderer{}).stream(&streamed, root); err != nil {
+		t.Fatal(err)
+	}
+	rendered, _ := (textRenderer{}).render(root)
+	if streamed.String() != rendered || !strings.HasPrefix(rendered, "project\n") {
+		t.Errorf("stream() =\n%s\nexpected the same as render():\n%s", streamed.String(), rendered)
+	}
+}
+
+func TestWritePlainRows(t *testing.T) {
+	defer func(width int, mtime bool) { maxLineWidth, showMtime = width, mtime }(maxLineWidth, showMtime)
+	root := &tree.Node{Name: "project", IsDir: true, Children: []*tree.Node{
+		{Name: "a-rather-long-file-name.go"},
+		{Name: "src", IsDir: true, Children: []*tree.Node{{Name: "app.go"}}},
+	}}
+
+	// Lines drawn one at a time match those aligned as a whole, names shortened alike
+	maxLineWidth = 16
+	if rowsAligned() {
+		t.Fatal("rowsAligned() = true without columns, annotations, or --perms")
+	}
+	var plain, aligned strings.Builder
+	if err := writePlainRows(&plain, root, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeRows(&aligned, treeRows(root), nil); err != nil {
+		t.Fatal(err)
+	}
+	if plain.String() != aligned.String() {
+		t.Errorf("writePlainRows() =\n%s\nexpected:\n%s", plain.String(), aligned.String())
+	}
+
+	showMtime = true
+	if !rowsAligned() {
+		t.Error("rowsAligned() = false with --mtime")
+	}
+}
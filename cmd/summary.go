@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// summaryJSON is the --summary-json setting: "" for no summary, "-" to append
+// it to the output, or the file to write it to.
+var summaryJSON string
+
+// walkStats records what the last findMatchingFiles left out and how long it
+// took.
+type walkStats struct {
+	// excluded counts the entries left out by each rule, such as
+	// "--exclude node_modules" or "--type"
+	excluded map[string]int
+	elapsed  time.Duration
+}
+
+// lastWalk holds the statistics of the last findMatchingFiles.
+var lastWalk walkStats
+
+// drop records that a rule left out n entries.
+func (s *walkStats) drop(rule string, n int) {
+	if n > 0 {
+		s.excluded[rule] += n
+	}
+}
+
+// excludeRuleLabel names a rule the walk excluded an entry by, as passed to
+// Walker.OnExclude, after the flag or file it came from. Patterns that are
+// neither flag globs nor regexps came from the root's .wintreeignore.
+func excludeRuleLabel(rule string, globs []string, regexps []*regexp.Regexp) string {
+	if rule == tree.OSNoiseRule {
+		return rule
+	}
+	if slices.Contains(smartExcludes, rule) {
+		return "--smart-defaults " + rule
+	}
+	if slices.Contains(globs, rule) {
+		return "--exclude " + rule
+	}
+	for _, re := range regexps {
+		if re.String() == rule {
+			return "--exclude-regex " + rule
+		}
+	}
+	return ignoreFileName + " " + rule
+}
+
+// runSummary is the machine-readable account of a run written by
+// --summary-json, for CI dashboards to trend a tree over time.
+type runSummary struct {
+	Version   int              `json:"version"`
+	Root      string           `json:"root"`
+	Started   time.Time        `json:"started"`
+	Scanned   int              `json:"scanned"`
+	Matched   summaryCounts    `json:"matched"`
+	Excluded  map[string]int   `json:"excluded"`
+	Errors    []string         `json:"errors"`
+	Durations summaryDurations `json:"durations_ms"`
+}
+
+type summaryCounts struct {
+	Directories int `json:"directories"`
+	Files       int `json:"files"`
+}
+
+type summaryDurations struct {
+	Walk   float64 `json:"walk"`
+	Render float64 `json:"render"`
+	Total  float64 `json:"total"`
+}
+
+// newRunSummary summarizes a run over root that started at started, from the
+// matched paths and the statistics of the last walk.
+func newRunSummary(root string, paths []string, started time.Time) runSummary {
+	summary := runSummary{
+		Version:  1,
+		Root:     root,
+		Started:  started,
+		Scanned:  len(walkEntries),
+		Excluded: lastWalk.excluded,
+		Errors:   []string{},
+	}
+	if anonymizing() {
+		summary.Root = anonymizePath(root)
+	}
+	if summary.Excluded == nil {
+		summary.Excluded = map[string]int{}
+	}
+	for _, path := range paths {
+		if info, err := statCached(path); err == nil && info.IsDir() {
+			summary.Matched.Directories++
+		} else {
+			summary.Matched.Files++
+		}
+	}
+	summary.Durations.Walk = milliseconds(lastWalk.elapsed)
+	return summary
+}
+
+// milliseconds returns d in milliseconds, to a microsecond.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// writeSummary finishes a run's summary and writes it to the --summary-json
+// file, or returns it as text to append to the output with no file given.
+func writeSummary(summary runSummary, started time.Time) (string, error) {
+	summary.Durations.Total = milliseconds(time.Since(started))
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	data = append(data, '\n')
+
+	if summaryJSON == "-" {
+		return string(data), nil
+	}
+	if err := os.WriteFile(summaryJSON, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write summary: %w", err)
+	}
+	return "", nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRunSummary(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, file := range []string{"main.go", "debug.log", ".DS_Store", "build/out.bin", "src/app.go", "src/app.tmp"} {
+		path := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ignoreFileName), []byte("*.tmp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalDepth, originalRegexes, originalTypes := maxDepth, excludeRegexes, fileTypes
+	defer func() { maxDepth, excludeRegexes, fileTypes = originalDepth, originalRegexes, originalTypes }()
+	maxDepth = -1
+	excludeRegexes = []string{"^build$"}
+	fileTypes = []string{"f"}
+
+	started := time.Now()
+	paths, err := findMatchingFiles(tempDir, processFilters([]string{"*.log"}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := newRunSummary(tempDir, paths, started)
+
+	// Every rule is counted once per entry it leaves out, an excluded
+	// directory standing for everything beneath it
+	expected := map[string]int{
+		"--exclude *.log":         1,
+		"--exclude-regex ^build$": 1,
+		".wintreeignore *.tmp":    1,
+		"OS metadata":             1,
+		"--type":                  1,
+	}
+	if !reflect.DeepEqual(summary.Excluded, expected) {
+		t.Errorf("Excluded = %v, expected %v", summary.Excluded, expected)
+	}
+	if summary.Matched != (summaryCounts{Files: 3}) {
+		t.Errorf("Matched = %+v, expected 3 files", summary.Matched)
+	}
+
+	originalSummary := summaryJSON
+	defer func() { summaryJSON = originalSummary }()
+
+	// With no file, the summary is returned to be appended to the output
+	summaryJSON = "-"
+	text, err := writeSummary(summary, started)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		t.Fatalf("writeSummary() = %q, not JSON: %v", text, err)
+	}
+	if decoded["root"] != tempDir || decoded["errors"] == nil {
+		t.Errorf("writeSummary() = %s, expected the root and an empty errors list", text)
+	}
+
+	summaryJSON = filepath.Join(t.TempDir(), "summary.json")
+	if text, err := writeSummary(summary, started); err != nil || text != "" {
+		t.Errorf("writeSummary() to a file = %q, %v, expected nothing to append", text, err)
+	}
+	if _, err := os.Stat(summaryJSON); err != nil {
+		t.Errorf("summary file not written: %v", err)
+	}
+}
+
+func TestSummaryFileLeavesOutputAlone(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "main.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalOut, originalSummary := outputFile, summaryJSON
+	defer func() { outputFile, summaryJSON = originalOut, originalSummary }()
+	outputFile = filepath.Join(t.TempDir(), "tree.txt")
+
+	render := func(summary string) string {
+		summaryJSON = summary
+		if err := rootCmd.RunE(rootCmd, []string{tempDir}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// The summary goes to its file, without a trace in the tree
+	plain := render("")
+	if output := render(filepath.Join(t.TempDir(), "summary.json")); output != plain {
+		t.Errorf("--out with --summary-json FILE = %q, expected %q", output, plain)
+	}
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// countTokens reports the size of the output in characters and estimated
+// LLM tokens.
+var countTokens bool
+
+// tokenReport describes how much of a model's context the output would take:
+// the whole output with --contents, or otherwise the contents that
+// --contents would add for the matched files.
+func tokenReport(root string, paths []string, output string) (string, error) {
+	sections, err := collectContentSections(root, paths)
+	if err != nil {
+		return "", err
+	}
+	files := plural(len(sections), "file", "files")
+
+	if contentsDump {
+		return fmt.Sprintf("Output with the contents of %s: %d characters, ~%d tokens",
+			files, utf8.RuneCountInString(output), estimateTokens(output)), nil
+	}
+
+	var contents strings.Builder
+	for _, section := range sections {
+		contents.WriteString(section.String())
+	}
+	return fmt.Sprintf("Contents of %s: %d characters, ~%d tokens (~%d tokens with the tree)",
+		files, utf8.RuneCountInString(contents.String()), estimateTokens(contents.String()), estimateTokens(output+contents.String())), nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTokenReport(t *testing.T) {
+	tempDir := t.TempDir()
+	paths := []string{filepath.Join(tempDir, "main.go"), filepath.Join(tempDir, "go.sum")}
+	if err := os.WriteFile(paths[0], []byte(strings.Repeat("x", 400)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths[1], []byte("noise"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalContents := contentsDump
+	defer func() { contentsDump = originalContents }()
+
+	// The lockfile is left out, as --contents would leave it out
+	contentsDump = false
+	tree := strings.Repeat("t", 40)
+	sections, _ := collectContentSections(tempDir, paths)
+	dumped := sections[0].String()
+	expected := "Contents of 1 file: " + strconv.Itoa(len(dumped)) + " characters, ~" + strconv.Itoa(estimateTokens(dumped)) +
+		" tokens (~" + strconv.Itoa(estimateTokens(tree+dumped)) + " tokens with the tree)"
+	if report, err := tokenReport(tempDir, paths, tree); err != nil || report != expected {
+		t.Errorf("tokenReport() = %q, %v, expected %q", report, err, expected)
+	}
+
+	contentsDump = true
+	output := tree + dumped + "ü"
+	expected = "Output with the contents of 1 file: " + strconv.Itoa(len(tree+dumped)+1) + " characters, ~" + strconv.Itoa(estimateTokens(output)) + " tokens"
+	if report, err := tokenReport(tempDir, paths, output); err != nil || report != expected {
+		t.Errorf("tokenReport() with --contents = %q, %v, expected %q", report, err, expected)
+	}
+}
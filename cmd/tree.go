@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// renderer turns a tree into output text.
+type renderer interface {
+	render(root *tree.Node) (string, error)
+}
+
+// streamer is a renderer that can also write its output as it is drawn, so
+// that large trees are never held as one string.
+type streamer interface {
+	renderer
+	stream(w io.Writer, root *tree.Node) error
+}
+
+// streamString renders root with s into a string.
+func streamString(s streamer, root *tree.Node) (string, error) {
+	var output strings.Builder
+	err := s.stream(&output, root)
+	return output.String(), err
+}
+
+// rendererFor returns the renderer for an --format value. Any value that is
+// not a structured format renders the text tree.
+func rendererFor(format string) renderer {
+	switch format {
+	case "json":
+		return jsonRenderer{}
+	case "script", "powershell":
+		return scriptRenderer{shell: format}
+	case "markdown", "markdown-list":
+		return markdownRenderer{list: format == "markdown-list"}
+	case "narrative":
+		return narrativeRenderer{}
+	case "dot":
+		return dotRenderer{}
+	case "quickfix":
+		return quickfixRenderer{}
+	}
+	return textRenderer{}
+}
+
+// buildTree arranges the matched paths under root into a tree labelled for
+// display: the root by rootLabel, and every other node by displayName. When
+// sizes are shown or sorted by every node is measured, each directory is
+// ordered by --sort and --dirs-first, and with --group-ext, files are
+// summarized by extension.
+func buildTree(root string, paths []string) *tree.Node {
+	top := tree.Build(root, paths, lstatCached)
+
+	if sizesShown() || tree.SortKey(sortOrder) == tree.SortBySize {
+		// Sizes are measured afresh for each build, as watch re-renders the tree
+		clear(sizeCache)
+		top.Walk(func(node *tree.Node, _ string) {
+			measureSize(node)
+		})
+	}
+	// Sorted before names are anonymized, so the order follows the real names
+	if tree.SortKey(sortOrder) != tree.SortByName || dirsFirst {
+		top.Sort(tree.SortKey(sortOrder), dirsFirst)
+	}
+
+	if checksumAlgorithm != "" {
+		hashNodes(top)
+	}
+
+	top.Walk(func(node *tree.Node, _ string) {
+		node.Name = displayName(node.Path)
+		// Paths and IDs in structured output must not give away the real names
+		if anonymizing() && node.RelPath != "" && node.RelPath != "." {
+			node.RelPath = anonymizeRelPath(node.RelPath)
+		}
+	})
+	top.Name = rootLabel(root)
+
+	if groupByExt {
+		groupExtensions(top)
+	}
+	return top
+}
+
+// textRenderer draws the tree with box-drawing (or ASCII) branches, followed
+// by any metadata columns and annotations.
+type textRenderer struct{}
+
+func (r textRenderer) render(root *tree.Node) (string, error) {
+	return streamString(r, root)
+}
+
+func (textRenderer) stream(w io.Writer, root *tree.Node) error {
+	write := writePlainRows
+	if rowsAligned() {
+		// Every row must be known before the first can be aligned
+		write = func(w io.Writer, root *tree.Node, colors *lsColors) error {
+			return writeRows(w, treeRows(root), colors)
+		}
+	}
+	if err := write(w, root, outputColors()); err != nil {
+		return err
+	}
+	if !noReport {
+		_, err := io.WriteString(w, "\n"+treeReport(root)+"\n")
+		return err
+	}
+	return nil
+}
+
+// treeReport summarizes the entries drawn beneath root as GNU tree does, such
+// as "3 directories, 12 files".
+func treeReport(root *tree.Node) string {
+	dirs, files := root.Counts()
+	return plural(dirs, "directory", "directories") + ", " + plural(files, "file", "files")
+}
+
+// plural formats a count with the singular or plural form of a noun.
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
+
+// treeRows returns one row per node of the tree, starting with the root.
+func treeRows(root *tree.Node) []treeRow {
+	var rows []treeRow
+	_ = eachTreeRow(root, func(row treeRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	return rows
+}
+
+// eachTreeRow calls fn with the row of every node of the tree, starting with
+// the root, as each is drawn. It stops at the first error fn returns.
+func eachTreeRow(root *tree.Node, fn func(treeRow) error) error {
+	return tree.EachRow(root, glyphs(), func(row tree.Row) error {
+		return fn(treeRow{
+			prefix: row.Prefix,
+			name:   row.Name,
+			path:   row.Path,
+			node:   row.Node,
+			parent: row.Parent,
+		})
+	})
+}
+
+// jsonRenderer writes the tree as nested JSON objects.
+type jsonRenderer struct{}
+
+func (jsonRenderer) render(root *tree.Node) (string, error) {
+	data, err := tree.RenderJSON(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tree: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+func TestBuildTreeOutputConnectors(t *testing.T) {
+	originalLabel := treeLabel
+	defer func() { treeLabel = originalLabel }()
+	treeLabel = "project"
+
+	root := "project"
+	output := buildTreeOutput(root, []string{
+		filepath.Join(root, "README.md"),
+		filepath.Join(root, "src"),
+		filepath.Join(root, "src", "lib"),
+		filepath.Join(root, "src", "lib", "util.go"),
+		filepath.Join(root, "src", "main.go"),
+		filepath.Join(root, "src.txt"),
+	})
+
+	// Siblings at every level share one branch, whatever sorts between them
+	expected := "project\n" +
+		"├── README.md\n" +
+		"├── src\n" +
+		"│   ├── lib\n" +
+		"│   │   └── util.go\n" +
+		"│   └── main.go\n" +
+		"└── src.txt\n"
+	if output != expected {
+		t.Errorf("buildTreeOutput() =\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestBuildTreeSort(t *testing.T) {
+	root := t.TempDir()
+	for file, size := range map[string]int{"c/data.bin": 4096 * 4, "b.bin": 4096 * 2, "a.txt": 1} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths := []string{filepath.Join(root, "a.txt"), filepath.Join(root, "b.bin"), filepath.Join(root, "c")}
+
+	originalSort, originalDirsFirst, originalApparent := sortOrder, dirsFirst, apparentSize
+	defer func() { sortOrder, dirsFirst, apparentSize = originalSort, originalDirsFirst, originalApparent }()
+
+	names := func() []string {
+		var result []string
+		for _, child := range buildTree(root, paths).Children {
+			result = append(result, child.Name)
+		}
+		return result
+	}
+
+	// Directories are measured for the order even when sizes are not shown
+	sortOrder, dirsFirst, apparentSize = "size", false, false
+	if result, expected := names(), []string{"c", "b.bin", "a.txt"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("buildTree() with --sort size = %v, expected %v", result, expected)
+	}
+
+	sortOrder, dirsFirst = "name", true
+	if result, expected := names(), []string{"c", "a.txt", "b.bin"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("buildTree() with --dirs-first = %v, expected %v", result, expected)
+	}
+
+	sortOrder, dirsFirst = "name", false
+	if result, expected := names(), []string{"a.txt", "b.bin", "c"}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("buildTree() with --sort name = %v, expected %v", result, expected)
+	}
+}
+
+func TestTextRendererReport(t *testing.T) {
+	originalLabel, originalGroup := treeLabel, groupByExt
+	defer func() { treeLabel, groupByExt, noReport = originalLabel, originalGroup, false }()
+	treeLabel = "project"
+
+	root := "project"
+	paths := []string{
+		filepath.Join(root, "README.md"),
+		filepath.Join(root, "src"),
+		filepath.Join(root, "src", "a.go"),
+		filepath.Join(root, "src", "b.go"),
+	}
+
+	tests := []struct {
+		name       string
+		groupByExt bool
+		noReport   bool
+		expected   string
+	}{
+		{"report", false, false, "\n1 directory, 3 files\n"},
+		{"grouped files are counted", true, false, "\n1 directory, 3 files\n"},
+		{"no report", false, true, "└── src\n    ├── a.go\n    └── b.go\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupByExt, noReport = tt.groupByExt, tt.noReport
+			output, _ := textRenderer{}.render(buildTree(root, paths))
+			if !strings.HasSuffix(output, tt.expected) {
+				t.Errorf("render() =\n%s\nexpected it to end with\n%s", output, tt.expected)
+			}
+		})
+	}
+
+	if report := treeReport(&tree.Node{Name: "empty", IsDir: true}); report != "0 directories, 0 files" {
+		t.Errorf("treeReport() for an empty tree = %q", report)
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "main.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalLabel := treeLabel
+	defer func() { treeLabel = originalLabel }()
+	treeLabel = "project"
+
+	output, err := jsonRenderer{}.render(buildTree(root, []string{filepath.Join(root, "src", "main.go")}))
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+
+	var result tree.JSONNode
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("render() produced invalid JSON: %v\n%s", err, output)
+	}
+
+	expected := tree.JSONNode{Name: "project", Type: "dir", Path: ".", ID: tree.NodeID("."), Children: []*tree.JSONNode{
+		{Name: "src", Type: "dir", Path: "src", ID: tree.NodeID("src"), Children: []*tree.JSONNode{
+			{Name: "main.go", Type: "file", Path: "src/main.go", ID: tree.NodeID("src/main.go")},
+		}},
+	}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("render() =\n%s", output)
+	}
+}
+
+func TestJSONRendererAnonymized(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "secret.go"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	originalPatterns := anonymizeHashPatterns
+	defer func() { anonymizeHashPatterns = originalPatterns }()
+	anonymizeHashPatterns = []string{"secret.*"}
+
+	output, err := jsonRenderer{}.render(buildTree(root, []string{filepath.Join(root, "src", "secret.go")}))
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	var result tree.JSONNode
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither the name nor the path gives the real name away, and the ID
+	// follows the anonymized path
+	if strings.Contains(output, "secret") || strings.Contains(output, tree.NodeID("src/secret.go")) {
+		t.Errorf("render() gave the real name away:\n%s", output)
+	}
+	hashed := "src/" + anonymizeName("secret.go")
+	file := result.Children[0].Children[0]
+	if file.Name != anonymizeName("secret.go") || file.Path != hashed || file.ID != tree.NodeID(hashed) {
+		t.Errorf("anonymized file = %+v, expected the hashed name in its path and ID", file)
+	}
+}
+
+func TestEntriesTree(t *testing.T) {
+	root := entriesTree("project", []treeEntry{
+		{relPath: "src", isDir: true},
+		{relPath: "src/main.go"},
+		{relPath: "empty", isDir: true},
+	})
+
+	output, err := jsonRenderer{}.render(root)
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+
+	// Parsed entries keep their order and their kind without touching the disk
+	var result tree.JSONNode
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatal(err)
+	}
+	expected := tree.JSONNode{Name: "project", Type: "dir", Path: ".", ID: tree.NodeID("."), Children: []*tree.JSONNode{
+		{Name: "src", Type: "dir", Path: "src", ID: tree.NodeID("src"), Children: []*tree.JSONNode{
+			{Name: "main.go", Type: "file", Path: "src/main.go", ID: tree.NodeID("src/main.go")},
+		}},
+		{Name: "empty", Type: "dir", Path: "empty", ID: tree.NodeID("empty")},
+	}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("render() =\n%s", output)
+	}
+}
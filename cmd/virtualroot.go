@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// writeVirtualRootTree renders every path argument as a child of a synthetic
+// root node named by --virtual-root, so that paths on different drives or
+// filesystem roots can be shown as a single tree.
+func writeVirtualRootTree(args []string) error {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	// Smart defaults are detected per path, so start each from the user's patterns
+	userExcludes := slices.Clone(excludePatterns)
+	defer func() { excludePatterns = userExcludes }()
+
+	roots := make([]string, 0, len(args))
+	matches := make([][]string, 0, len(args))
+	for _, arg := range args {
+		root, err := resolveStartPath([]string{arg})
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(root); err != nil {
+			return fmt.Errorf("cannot read %s: %w", arg, err)
+		}
+
+		excludePatterns = slices.Clone(userExcludes)
+		if useSmartDefaults {
+			applySmartDefaults(root)
+		}
+
+		matchingFiles, err := findMatchingFiles(root, processFilters(excludePatterns, includePatterns))
+		if err != nil {
+			return fmt.Errorf("error finding files: %w", err)
+		}
+
+		roots = append(roots, root)
+		matches = append(matches, matchingFiles)
+	}
+
+	output, err := rendererFor(outputFormat).render(buildVirtualTree(virtualRoot, roots, matches))
+	if err != nil {
+		return err
+	}
+	return writeOutput(output)
+}
+
+// buildVirtualTree arranges each root's tree as a child of a node called
+// name. matches[i] holds the matched paths under roots[i].
+func buildVirtualTree(name string, roots []string, matches [][]string) *tree.Node {
+	top := &tree.Node{Name: name, IsDir: true}
+	for i, root := range roots {
+		subtree := buildTree(root, matches[i])
+		// Each root is labelled by its own path; --label only applies to a single tree
+		subtree.Name = pathLabel(root)
+		top.Children = append(top.Children, subtree)
+	}
+	return top
+}
+
+// buildVirtualTreeOutput draws each root's tree as a branch under a node
+// called name.
+func buildVirtualTreeOutput(name string, roots []string, matches [][]string) string {
+	return formatTreeRows(treeRows(buildVirtualTree(name, roots, matches)))
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildVirtualTreeOutput(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	for _, path := range []string{filepath.Join(first, "a.go"), filepath.Join(second, "b.md")} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	originalLabel, originalFullPath := treeLabel, showFullPath
+	defer func() { treeLabel, showFullPath = originalLabel, originalFullPath }()
+	treeLabel = "ignored"
+	showFullPath = true
+
+	output := buildVirtualTreeOutput("machines", []string{first, second}, [][]string{
+		{filepath.Join(first, "a.go")},
+		{filepath.Join(second, "b.md")},
+	})
+
+	expected := "machines\n" +
+		"├── " + first + "\n" +
+		"│   └── a.go\n" +
+		"└── " + second + "\n" +
+		"    └── b.md\n"
+	if output != expected {
+		t.Errorf("buildVirtualTreeOutput() =\n%s\nexpected:\n%s", output, expected)
+	}
+	if strings.Contains(output, "ignored") {
+		t.Error("--label should not replace the per-path labels under a virtual root")
+	}
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/maxdribny/wintree/pkg/tree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchDebounce time.Duration
+	exitOnChange  bool
+	watchFormat   string
+)
+
+// watchEvent is a single filesystem change emitted by --format ndjson.
+type watchEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Op        string    `json:"op"`
+	Path      string    `json:"path"`
+	Type      string    `json:"type"`
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [path]",
+	Short: "Re-render the tree whenever files change.",
+	Long: `Watch a directory and re-render the tree every time files are created,
+modified, deleted, or renamed. All filters apply to both the tree and the
+events that trigger a refresh.
+
+Combined with --contents and --copy, the clipboard always holds the latest
+project context while you edit:
+
+  wintree watch --contents --copy --debounce 2s
+
+With --exit-on-change, nothing is rendered: the command blocks until the first
+change that passes the filters, prints the changed paths, and exits 0. This
+makes it a simple "wait until something in this tree changes" primitive for
+shell scripts.
+
+With --format ndjson, nothing is rendered either: every change is written to
+stdout as a JSON object on its own line, with its timestamp, operation
+(create, write, remove, rename, chmod), path, and type (file, dir, symlink,
+or unknown once the path no longer exists).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateFilterFlags(); err != nil {
+			return err
+		}
+		switch watchFormat {
+		case "tree":
+		case "ndjson":
+			if copyToClipboard || outputFile != "" {
+				return flagErrorf("--format ndjson cannot be used with --copy or --out flags")
+			}
+		default:
+			return flagErrorf("invalid --format %q (use tree or ndjson)", watchFormat)
+		}
+
+		startPath, err := resolveStartPath(args)
+		if err != nil {
+			return err
+		}
+
+		// The tree is redrawn in place, so it must never wait in a pager
+		noPager = true
+
+		if useSmartDefaults {
+			applySmartDefaults(startPath)
+		}
+
+		filters := processFilters(excludePatterns, includePatterns)
+		if filters.err != nil {
+			return asFlagError(filters.err)
+		}
+		return runWatch(startPath, filters)
+	},
+}
+
+// runWatch renders the tree once, then again after each burst of filesystem
+// events has been quiet for the debounce interval. It returns on Ctrl+C.
+func runWatch(startPath string, filters filter) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := addWatchDirs(watcher, startPath, startPath, filters); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", startPath, err)
+	}
+
+	// Our own output file lives inside the tree; writing it must not trigger a refresh
+	ignoredPath := ""
+	if outputFile != "" {
+		if abs, err := filepath.Abs(outputFile); err == nil {
+			ignoredPath = abs
+		}
+	}
+
+	if !exitOnChange && watchFormat == "tree" {
+		if err := renderWatch(startPath, filters); err != nil {
+			return err
+		}
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var debounce *time.Timer
+	var debounceC <-chan time.Time
+	var changed []string
+	seen := make(map[string]bool)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Name == ignoredPath || isExcludedPath(startPath, event.Name, filters) {
+				continue
+			}
+			if !matchesIncludePath(startPath, event.Name, filters) {
+				continue
+			}
+
+			// Newly created directories need watches of their own
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchDirs(watcher, startPath, event.Name, filters); err != nil {
+						fmt.Fprintf(os.Stderr, "warning: failed to watch %s: %v\n", event.Name, err)
+					}
+				}
+			}
+
+			// Machine-readable events are emitted as they happen, without rendering
+			if watchFormat == "ndjson" {
+				if err := writeWatchEvent(os.Stdout, event); err != nil {
+					return err
+				}
+				if !exitOnChange {
+					continue
+				}
+			}
+
+			if debounce == nil {
+				debounce = time.NewTimer(watchDebounce)
+			} else {
+				debounce.Reset(watchDebounce)
+			}
+			debounceC = debounce.C
+
+			if exitOnChange && !seen[event.Name] {
+				seen[event.Name] = true
+				changed = append(changed, event.Name)
+			}
+
+		case <-debounceC:
+			debounceC = nil
+			if exitOnChange {
+				// In ndjson mode the events have already been written
+				if watchFormat == "tree" {
+					for _, path := range changed {
+						fmt.Println(path)
+					}
+				}
+				return nil
+			}
+			if err := renderWatch(startPath, filters); err != nil {
+				return err
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watch error: %w", err)
+
+		case <-interrupt:
+			return nil
+		}
+	}
+}
+
+// writeWatchEvent encodes a filesystem event as a single line of JSON.
+func writeWatchEvent(w io.Writer, event fsnotify.Event) error {
+	entry := watchEvent{
+		Timestamp: time.Now().UTC(),
+		Op:        strings.ToLower(event.Op.String()),
+		Path:      event.Name,
+		Type:      "unknown",
+	}
+	if info, err := os.Lstat(event.Name); err == nil {
+		entry.Type = entryType(info)
+	}
+
+	return json.NewEncoder(w).Encode(entry)
+}
+
+// renderWatch renders the tree and writes it out. Console output clears the
+// screen first so the tree is redrawn in place.
+func renderWatch(startPath string, filters filter) error {
+	matchingFiles, err := findMatchingFiles(startPath, filters)
+	if err != nil {
+		return fmt.Errorf("error finding files: %w", err)
+	}
+
+	if !copyToClipboard && outputFile == "" {
+		fmt.Print("\033[H\033[2J")
+	}
+
+	if filters.including() && len(matchingFiles) == 0 {
+		fmt.Println("No files found matching the given patterns.")
+		return nil
+	}
+
+	finalOutput, err := renderOutput(startPath, matchingFiles)
+	if err != nil {
+		return err
+	}
+
+	if copyToClipboard || outputFile != "" {
+		fmt.Printf("[%s] ", time.Now().Format("15:04:05"))
+	}
+	return writeOutput(finalOutput)
+}
+
+// addWatchDirs adds a watch for dir and every directory beneath it that is not
+// excluded by the filters.
+func addWatchDirs(watcher *fsnotify.Watcher, root, dir string, filters filter) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && isExcludedPath(root, path, filters) {
+			return fs.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// isExcludedPath reports whether any component of path below root matches an
+// exclude pattern or is OS metadata, or the path or one of its directories
+// matches an exclude regex.
+func isExcludedPath(root, path string, filters filter) bool {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil || relPath == "." {
+		return false
+	}
+
+	regexps := tree.Options{ExcludeRegexp: filters.excludeRegexps}
+	parts := strings.Split(relPath, string(filepath.Separator))
+	for i, part := range parts {
+		if isOSNoise(part) || (len(regexps.ExcludeRegexp) > 0 && regexps.ExcludesPath(strings.Join(parts[:i+1], "/"))) {
+			return true
+		}
+		for _, pattern := range filters.excludeGlobs {
+			if matched, _ := filepath.Match(pattern, part); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesIncludePath reports whether a changed path is relevant in include
+// mode: its name matches an include glob, its path matches an include regex,
+// or it lies inside a directory that is included by name. Directories
+// themselves always match, since files may have been added to or removed
+// from them.
+func matchesIncludePath(root, path string, filters filter) bool {
+	if !filters.including() {
+		return true
+	}
+
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	if (tree.Options{IncludeRegexp: filters.includeRegexps}).IncludesPath(filepath.ToSlash(relPath)) {
+		return true
+	}
+
+	parts := strings.Split(relPath, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		for _, pattern := range filters.includeGlobs {
+			if tree.MatchDirName(pattern, part) {
+				return true
+			}
+		}
+	}
+
+	name := parts[len(parts)-1]
+	for _, pattern := range filters.includeGlobs {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	// Deleted entries can no longer be stat'ed; treat them as files
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func init() {
+	addFilterFlags(watchCmd.Flags())
+	addOutputFlags(watchCmd.Flags())
+	addContentsFlags(watchCmd.Flags())
+	watchCmd.Flags().DurationVarP(&watchDebounce, "debounce", "", 500*time.Millisecond, "Wait this long after the last change before re-rendering")
+	watchCmd.Flags().BoolVarP(&exitOnChange, "exit-on-change", "", false, "Wait for the first change, print the changed paths, and exit")
+	watchCmd.Flags().StringVarP(&watchFormat, "format", "", "tree", "Output format: tree (re-render the tree) or ndjson (one JSON object per change)")
+	rootCmd.AddCommand(watchCmd)
+}
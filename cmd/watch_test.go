@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestIsExcludedPath(t *testing.T) {
+	root := filepath.Join("project")
+	filters := processFilters([]string{"node_modules", "*.log"}, []string{})
+
+	tests := []struct {
+		name     string
+		path     string
+		expected bool
+	}{
+		{"root itself", root, false},
+		{"regular file", filepath.Join(root, "src", "main.go"), false},
+		{"excluded directory", filepath.Join(root, "node_modules"), true},
+		{"inside excluded directory", filepath.Join(root, "node_modules", "pkg", "index.js"), true},
+		{"excluded file pattern", filepath.Join(root, "logs", "app.log"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := isExcludedPath(root, tt.path, filters); result != tt.expected {
+				t.Errorf("isExcludedPath(%q) = %v, expected %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatchesIncludePath(t *testing.T) {
+	testDir := setupTestDirectory(t)
+	defer os.RemoveAll(testDir)
+
+	tests := []struct {
+		name     string
+		include  []string
+		path     string
+		expected bool
+	}{
+		{"no include patterns", []string{}, filepath.Join(testDir, "README.md"), true},
+		{"matching file glob", []string{"*.go"}, filepath.Join(testDir, "src", "app.go"), true},
+		{"non-matching file", []string{"*.go"}, filepath.Join(testDir, "src", "utils.js"), false},
+		{"deleted non-matching file", []string{"*.go"}, filepath.Join(testDir, "gone.txt"), false},
+		{"inside included directory", []string{"docs"}, filepath.Join(testDir, "docs", "guide.txt"), true},
+		{"directory event", []string{"*.go"}, filepath.Join(testDir, "tests"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := processFilters([]string{}, tt.include)
+			if result := matchesIncludePath(testDir, tt.path, filters); result != tt.expected {
+				t.Errorf("matchesIncludePath(%q) = %v, expected %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteWatchEvent(t *testing.T) {
+	testDir := setupTestDirectory(t)
+	defer os.RemoveAll(testDir)
+
+	tests := []struct {
+		name         string
+		event        fsnotify.Event
+		expectedOp   string
+		expectedType string
+	}{
+		{"created file", fsnotify.Event{Name: filepath.Join(testDir, "main.go"), Op: fsnotify.Create}, "create", "file"},
+		{"written directory", fsnotify.Event{Name: filepath.Join(testDir, "src"), Op: fsnotify.Write}, "write", "dir"},
+		{"removed path", fsnotify.Event{Name: filepath.Join(testDir, "gone.txt"), Op: fsnotify.Remove}, "remove", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeWatchEvent(&buf, tt.event); err != nil {
+				t.Fatalf("writeWatchEvent() error = %v", err)
+			}
+
+			if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+				t.Errorf("writeWatchEvent() should write exactly one line, got %q", buf.String())
+			}
+
+			var decoded watchEvent
+			if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+				t.Fatalf("writeWatchEvent() wrote invalid JSON %q: %v", buf.String(), err)
+			}
+			if decoded.Op != tt.expectedOp || decoded.Type != tt.expectedType || decoded.Path != tt.event.Name {
+				t.Errorf("writeWatchEvent() = %+v, expected op %q, type %q, path %q", decoded, tt.expectedOp, tt.expectedType, tt.event.Name)
+			}
+			if decoded.Timestamp.IsZero() {
+				t.Error("writeWatchEvent() should set a timestamp")
+			}
+		})
+	}
+}
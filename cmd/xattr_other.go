@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package cmd
+
+// readXattrs is a no-op on platforms without extended attribute support.
+func readXattrs(path string) (map[string]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,56 @@
+//go:build linux || darwin
+
+package cmd
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// readXattrs returns the extended attributes of path, without following
+// symlinks, with hex-encoded values. On Linux this includes POSIX ACLs, which
+// are stored as system.posix_acl_* attributes.
+func readXattrs(path string) (map[string]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil {
+		if errors.Is(err, unix.ENOTSUP) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]string)
+	for _, name := range strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00") {
+		if name == "" {
+			continue
+		}
+		valueSize, err := unix.Lgetxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, valueSize)
+		if valueSize > 0 {
+			if valueSize, err = unix.Lgetxattr(path, name, value); err != nil {
+				return nil, err
+			}
+		}
+		attrs[name] = hex.EncodeToString(value[:valueSize])
+	}
+
+	if len(attrs) == 0 {
+		return nil, nil
+	}
+	return attrs, nil
+}
@@ -2,10 +2,13 @@ module github.com/maxdribny/wintree
 
 go 1.24.4
 
-require github.com/spf13/cobra v1.9.1
-
 require (
-	github.com/atotto/clipboard v0.1.4 // direct
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
+	github.com/atotto/clipboard v0.1.4
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
+	golang.org/x/sys v0.13.0
+	golang.org/x/term v0.13.0
 )
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
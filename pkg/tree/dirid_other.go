@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package tree
+
+import "io/fs"
+
+// readDirID is not supported on this platform, so repeated directories are
+// not detected.
+func readDirID(path string, info fs.FileInfo) (dirID, bool) {
+	return dirID{}, false
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package tree
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// readDirID returns the device and inode of a directory, which a bind mount
+// shares with the directory it mounts.
+func readDirID(path string, info fs.FileInfo) (dirID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return dirID{}, false
+	}
+	return dirID{volume: uint64(stat.Dev), index: uint64(stat.Ino)}, true
+}
@@ -0,0 +1,32 @@
+package tree
+
+import (
+	"io/fs"
+
+	"golang.org/x/sys/windows"
+)
+
+// readDirID returns the volume serial number and file index of a directory,
+// which a mounted folder shares with the directory it mounts.
+func readDirID(path string, info fs.FileInfo) (dirID, bool) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return dirID{}, false
+	}
+	handle, err := windows.CreateFile(name, windows.FILE_READ_ATTRIBUTES,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE, nil,
+		windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS|windows.FILE_FLAG_OPEN_REPARSE_POINT, 0)
+	if err != nil {
+		return dirID{}, false
+	}
+	defer windows.CloseHandle(handle)
+
+	var byHandle windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(handle, &byHandle); err != nil {
+		return dirID{}, false
+	}
+	return dirID{
+		volume: uint64(byHandle.VolumeSerialNumber),
+		index:  uint64(byHandle.FileIndexHigh)<<32 | uint64(byHandle.FileIndexLow),
+	}, true
+}
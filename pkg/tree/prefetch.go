@@ -0,0 +1,84 @@
+package tree
+
+import (
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// dirPrefetcher lists directories ahead of a walk on a bounded number of
+// goroutines. The walk itself stays sequential, so its results and the order
+// of its callbacks are the same as without one; only the waiting for the
+// filesystem overlaps, which is what dominates on large trees and network
+// drives.
+type dirPrefetcher struct {
+	slots chan struct{}
+	// stop abandons the listings not yet started once the walk ends
+	stop chan struct{}
+
+	mu      sync.Mutex
+	pending map[string]*dirListing
+}
+
+// dirListing is the result of listing a directory, ready once done is closed.
+type dirListing struct {
+	done    chan struct{}
+	entries []fs.DirEntry
+	err     error
+}
+
+// newDirPrefetcher returns a prefetcher listing up to jobs directories at once.
+func newDirPrefetcher(jobs int) *dirPrefetcher {
+	return &dirPrefetcher{
+		slots:   make(chan struct{}, jobs),
+		stop:    make(chan struct{}),
+		pending: make(map[string]*dirListing),
+	}
+}
+
+// prefetch starts listing dir in the background, unless it already is.
+func (p *dirPrefetcher) prefetch(dir string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.pending[dir]; ok {
+		return
+	}
+	listing := &dirListing{done: make(chan struct{})}
+	p.pending[dir] = listing
+
+	go func() {
+		defer close(listing.done)
+		select {
+		case p.slots <- struct{}{}:
+		case <-p.stop:
+			listing.err = fs.ErrClosed
+			return
+		}
+		defer func() { <-p.slots }()
+		listing.entries, listing.err = os.ReadDir(dir)
+	}()
+}
+
+// readDir returns the entries of dir sorted by name, as os.ReadDir does,
+// waiting for its prefetched listing if there is one.
+func (p *dirPrefetcher) readDir(dir string) ([]fs.DirEntry, error) {
+	if p == nil {
+		return os.ReadDir(dir)
+	}
+	p.mu.Lock()
+	listing, ok := p.pending[dir]
+	delete(p.pending, dir)
+	p.mu.Unlock()
+	if !ok {
+		return os.ReadDir(dir)
+	}
+	<-listing.done
+	return listing.entries, listing.err
+}
+
+// close abandons every listing that has not started.
+func (p *dirPrefetcher) close() {
+	if p != nil {
+		close(p.stop)
+	}
+}
@@ -0,0 +1,150 @@
+package tree
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+)
+
+// Charset holds the strings tree branches are drawn with.
+type Charset struct {
+	Branch   string
+	Last     string
+	Vertical string
+	Blank    string
+}
+
+var (
+	// UTF8 draws branches with box-drawing characters.
+	UTF8 = Charset{Branch: "├── ", Last: "└── ", Vertical: "│   ", Blank: "    "}
+	// ASCII draws branches the way GNU tree's --charset ascii does.
+	ASCII = Charset{Branch: "|-- ", Last: "`-- ", Vertical: "|   ", Blank: "    "}
+)
+
+// Row is a single line of a drawn tree.
+type Row struct {
+	// Prefix is the indentation and branch drawn before the name
+	Prefix string
+	Name   string
+	Path   string
+	// Node is the node drawn on this line, and Parent the node it is drawn
+	// under, or nil for the root
+	Node   *Node
+	Parent *Node
+}
+
+// Text returns the line as drawn.
+func (r Row) Text() string {
+	return r.Prefix + r.Name
+}
+
+// Rows returns one row per node of the tree, starting with the root.
+func Rows(root *Node, charset Charset) []Row {
+	var rows []Row
+	_ = EachRow(root, charset, func(row Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	return rows
+}
+
+// EachRow calls fn with one row per node of the tree, starting with the root,
+// without holding them all at once. It stops at the first error fn returns.
+func EachRow(root *Node, charset Charset, fn func(Row) error) error {
+	if err := fn(Row{Name: root.Name, Path: root.Path, Node: root}); err != nil {
+		return err
+	}
+
+	var walk func(node *Node, indent string) error
+	walk = func(node *Node, indent string) error {
+		for i, child := range node.Children {
+			branch, next := charset.Branch, charset.Vertical
+			if i == len(node.Children)-1 {
+				branch, next = charset.Last, charset.Blank
+			}
+			if err := fn(Row{Prefix: indent + branch, Name: child.Name, Path: child.Path, Node: child, Parent: node}); err != nil {
+				return err
+			}
+			if err := walk(child, indent+next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(root, "")
+}
+
+// Render draws the tree as text, one line per node.
+func Render(root *Node, charset Charset) string {
+	var output strings.Builder
+	_ = Write(&output, root, charset)
+	return output.String()
+}
+
+// Write draws the tree as Render does, writing each line to w as it is drawn
+// instead of building the whole text first.
+func Write(w io.Writer, root *Node, charset Charset) error {
+	return EachRow(root, charset, func(row Row) error {
+		_, err := io.WriteString(w, row.Text()+"\n")
+		return err
+	})
+}
+
+// JSONNode is the JSON form of a tree node.
+type JSONNode struct {
+	Name string `json:"name"`
+	// Type is dir, file, symlink, or other
+	Type string `json:"type"`
+	// Path is slash-separated and relative to the root, which is "."
+	Path string `json:"path"`
+	// ID is the NodeID of Path
+	ID string `json:"id"`
+	// Size is included for nodes whose size was measured
+	Size     *int64      `json:"size,omitempty"`
+	Children []*JSONNode `json:"children,omitempty"`
+}
+
+// RenderJSON encodes the tree as nested, indented JSON objects.
+func RenderJSON(root *Node) ([]byte, error) {
+	data, err := json.MarshalIndent(toJSONNode(root, "."), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// toJSONNode converts node and its children, giving node the relative path
+// relPath.
+func toJSONNode(node *Node, relPath string) *JSONNode {
+	result := &JSONNode{Name: node.Name, Type: node.Type(), Path: relPath, ID: NodeID(relPath), Size: node.Size}
+	for _, child := range node.Children {
+		result.Children = append(result.Children, toJSONNode(child, ChildPath(relPath, child)))
+	}
+	return result
+}
+
+// ChildPath returns the relative path of child beneath a node at relPath. It
+// follows the child's RelPath where Build set one, so that names relabelled
+// for display do not change paths and IDs.
+func ChildPath(relPath string, child *Node) string {
+	name := child.Name
+	if child.RelPath != "" && child.RelPath != "." {
+		name = path.Base(child.RelPath)
+	}
+	if relPath == "." {
+		return name
+	}
+	return relPath + "/" + name
+}
+
+// NodeID returns a short, stable ID for the node at relPath, the
+// slash-separated path relative to the root, which is ".". IDs stay the same
+// across runs and machines, so that external tools can link to an entry of
+// an exported tree.
+func NodeID(relPath string) string {
+	sum := sha256.Sum256([]byte(relPath))
+	return hex.EncodeToString(sum[:6])
+}
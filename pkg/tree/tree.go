@@ -0,0 +1,225 @@
+// Package tree finds, arranges, and draws directory trees. It is the engine
+// behind the wintree command and can be embedded in other Go programs:
+//
+//	walker := tree.NewWalker(tree.Options{Exclude: []string{".git"}, MaxDepth: -1})
+//	paths, err := walker.Walk(root)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(tree.Render(tree.Build(root, paths, nil), tree.UTF8))
+package tree
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Node is an entry in a tree, with its children in display order.
+type Node struct {
+	// Name is the text shown for the node: the base name, or any label
+	// the caller chooses
+	Name string
+	// Path is the node's filesystem path, or "" for nodes that do not exist
+	// on disk, such as a synthetic root grouping several trees
+	Path string
+	// RelPath is the node's slash-separated path relative to the root it was
+	// built from, which is ".", or "" for nodes the caller added. It is the
+	// path structured output gives the node, so labels added to Name for
+	// display, such as a count or marker, do not change it.
+	RelPath string
+	// Info is the node's Lstat result, or nil if it could not be read or the
+	// node does not exist on disk
+	Info  fs.FileInfo
+	IsDir bool
+	// Size is the node's size in bytes, if the caller measured it, such as
+	// the cumulative size of a directory's contents
+	Size *int64
+	// Count is the number of files a summary node stands for, such as one
+	// line counting the files with an extension, or 0 for ordinary nodes
+	Count    int
+	Children []*Node
+}
+
+// Type names the kind of a node: dir, file, symlink, or other. Nodes that do
+// not exist on disk are a dir or a file.
+func (n *Node) Type() string {
+	if n.Info != nil {
+		switch {
+		case n.Info.IsDir():
+			return "dir"
+		case n.Info.Mode()&os.ModeSymlink != 0:
+			return "symlink"
+		case !n.Info.Mode().IsRegular():
+			return "other"
+		}
+		return "file"
+	}
+	if n.IsDir {
+		return "dir"
+	}
+	return "file"
+}
+
+// Counts returns the number of directories and files beneath n, not counting
+// n itself. Summary nodes count as the files they stand for.
+func (n *Node) Counts() (dirs, files int) {
+	n.Walk(func(node *Node, relPath string) {
+		switch {
+		case relPath == ".":
+		case node.IsDir:
+			dirs++
+		case node.Count > 0:
+			files += node.Count
+		default:
+			files++
+		}
+	})
+	return dirs, files
+}
+
+// SortKey names an order for the children of each node in a tree.
+type SortKey string
+
+const (
+	// SortByName orders children by name, byte by byte.
+	SortByName SortKey = "name"
+	// SortBySize orders children largest first, by their measured Size if
+	// set and their Info otherwise.
+	SortBySize SortKey = "size"
+	// SortByMtime orders children most recently modified first.
+	SortByMtime SortKey = "mtime"
+	// SortByExtension orders children by lowercase extension, with names
+	// that have none first, as ls -X does.
+	SortByExtension SortKey = "extension"
+)
+
+// Build arranges paths beneath root into a tree, adding the parent
+// directories of each path. Paths outside root are skipped, and children are
+// sorted by name. Nodes are described with lstat, or os.Lstat if it is nil.
+func Build(root string, paths []string, lstat func(path string) (fs.FileInfo, error)) *Node {
+	if lstat == nil {
+		lstat = os.Lstat
+	}
+
+	newNode := func(path string) *Node {
+		node := &Node{Name: filepath.Base(path), Path: path, RelPath: "."}
+		if relPath, err := filepath.Rel(root, path); err == nil {
+			node.RelPath = filepath.ToSlash(relPath)
+		}
+		if info, err := lstat(path); err == nil {
+			node.Info = info
+			node.IsDir = info.IsDir()
+		}
+		return node
+	}
+
+	top := newNode(root)
+	top.IsDir = true
+	nodes := map[string]*Node{root: top}
+
+	var add func(path string) *Node
+	add = func(path string) *Node {
+		if node, ok := nodes[path]; ok {
+			return node
+		}
+		node := newNode(path)
+		nodes[path] = node
+
+		parent := add(filepath.Dir(path))
+		parent.IsDir = true
+		parent.Children = append(parent.Children, node)
+		return node
+	}
+
+	for _, path := range paths {
+		relPath, err := filepath.Rel(root, path)
+		if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		add(path)
+	}
+
+	top.Sort(SortByName, false)
+	return top
+}
+
+// Sort orders the children of n and of every node beneath it by key, with
+// ties broken by name. With dirsFirst, directories come before files.
+func (n *Node) Sort(key SortKey, dirsFirst bool) {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if dirsFirst && a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		switch key {
+		case SortBySize:
+			if sizeA, sizeB := a.sortSize(), b.sortSize(); sizeA != sizeB {
+				return sizeA > sizeB
+			}
+		case SortByMtime:
+			if timeA, timeB := a.modTime(), b.modTime(); !timeA.Equal(timeB) {
+				return timeA.After(timeB)
+			}
+		case SortByExtension:
+			if extA, extB := sortExt(a.Name), sortExt(b.Name); extA != extB {
+				return extA < extB
+			}
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range n.Children {
+		child.Sort(key, dirsFirst)
+	}
+}
+
+// sortSize returns the size n is sorted by: its measured Size, the size of
+// the file it describes, or 0 if neither is known.
+func (n *Node) sortSize() int64 {
+	switch {
+	case n.Size != nil:
+		return *n.Size
+	case n.Info != nil && !n.Info.IsDir():
+		return n.Info.Size()
+	}
+	return 0
+}
+
+// modTime returns the modification time of n, or the zero time if it is not
+// known.
+func (n *Node) modTime() time.Time {
+	if n.Info == nil {
+		return time.Time{}
+	}
+	return n.Info.ModTime()
+}
+
+// sortExt returns the lowercase extension of name, or "" for names without
+// one, such as Makefile and .gitignore.
+func sortExt(name string) string {
+	ext := filepath.Ext(name)
+	if ext == name {
+		return ""
+	}
+	return strings.ToLower(ext)
+}
+
+// Walk calls fn for node and every node beneath it, parents first, with
+// each node's slash-separated path relative to node, which is ".".
+func (n *Node) Walk(fn func(node *Node, relPath string)) {
+	var walk func(node *Node, relPath string)
+	walk = func(node *Node, relPath string) {
+		fn(node, relPath)
+		for _, child := range node.Children {
+			childPath := child.Name
+			if relPath != "." {
+				childPath = relPath + "/" + child.Name
+			}
+			walk(child, childPath)
+		}
+	}
+	walk(n, ".")
+}
@@ -0,0 +1,616 @@
+package tree
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func setupTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, file := range []string{
+		"main.go",
+		"README.md",
+		".DS_Store",
+		"src/app.go",
+		"src/lib/util.go",
+		"node_modules/pkg/index.js",
+		"docs/guide.md",
+	} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func relPaths(t *testing.T, root string, paths []string) []string {
+	t.Helper()
+	var result []string
+	for _, path := range paths {
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, filepath.ToSlash(relPath))
+	}
+	return result
+}
+
+func TestWalk(t *testing.T) {
+	root := setupTree(t)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{
+			name:     "immediate children",
+			opts:     Options{MaxDepth: 0},
+			expected: []string{"README.md", "docs", "main.go", "node_modules", "src"},
+		},
+		{
+			name:     "exclude",
+			opts:     Options{MaxDepth: -1, Exclude: []string{"node_modules", "*.md"}},
+			expected: []string{"docs", "main.go", "src", "src/app.go", "src/lib", "src/lib/util.go"},
+		},
+		{
+			name:     "include",
+			opts:     Options{MaxDepth: -1, Include: []string{"*.go", "docs"}},
+			expected: []string{"docs/guide.md", "main.go", "src/app.go", "src/lib/util.go"},
+		},
+		{
+			name:     "include directory glob",
+			opts:     Options{MaxDepth: -1, Include: []string{"l*", "n*", "*.md"}, Exclude: []string{"*.js"}},
+			expected: []string{"README.md", "docs/guide.md", "src/lib/util.go"},
+		},
+		{
+			name:     "exclude regexp",
+			opts:     Options{MaxDepth: -1, Exclude: []string{"node_modules"}, ExcludeRegexp: []*regexp.Regexp{regexp.MustCompile(`^src/lib$`), regexp.MustCompile(`\.md$`)}},
+			expected: []string{"docs", "main.go", "src", "src/app.go"},
+		},
+		{
+			name:     "include regexp",
+			opts:     Options{MaxDepth: -1, Include: []string{"*.md"}, IncludeRegexp: []*regexp.Regexp{regexp.MustCompile(`^src/.*\.go$`)}},
+			expected: []string{"README.md", "docs/guide.md", "src/app.go", "src/lib/util.go"},
+		},
+		{
+			name:     "dirs depth",
+			opts:     Options{DirsDepth: 1, Exclude: []string{"node_modules", "docs"}},
+			expected: []string{"README.md", "main.go", "src", "src/app.go"},
+		},
+		{
+			name:     "OS files",
+			opts:     Options{MaxDepth: 0, ShowOSFiles: true, Include: []string{".*"}},
+			expected: []string{".DS_Store"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := NewWalker(tt.opts).Walk(root)
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if result := relPaths(t, root, paths); !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Walk() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestWalkDepth documents what each depth lists. Depth 0 is the root's
+// immediate children, and each level below adds one. With DepthCountsDirs the
+// deepest level holds only directories. In include mode, directories are only
+// listed as the parents of matching files, so only the files' depths matter.
+func TestWalkDepth(t *testing.T) {
+	root := setupTree(t)
+	all := []string{"README.md", "docs", "docs/guide.md", "main.go", "src", "src/app.go", "src/lib", "src/lib/util.go"}
+
+	tests := []struct {
+		depth      int
+		countsDirs bool
+		include    []string
+		expected   []string
+	}{
+		{0, false, nil, []string{"README.md", "docs", "main.go", "src"}},
+		{1, false, nil, []string{"README.md", "docs", "docs/guide.md", "main.go", "src", "src/app.go", "src/lib"}},
+		{2, false, nil, all},
+		{-1, false, nil, all},
+		{0, true, nil, []string{"docs", "src"}},
+		{1, true, nil, []string{"README.md", "docs", "main.go", "src", "src/lib"}},
+		{2, true, nil, []string{"README.md", "docs", "docs/guide.md", "main.go", "src", "src/app.go", "src/lib"}},
+		{-1, true, nil, all},
+		{0, false, []string{"*.go"}, []string{"main.go"}},
+		{1, false, []string{"*.go"}, []string{"main.go", "src/app.go"}},
+		{2, false, []string{"*.go"}, []string{"main.go", "src/app.go", "src/lib/util.go"}},
+		{0, true, []string{"*.go"}, nil},
+		{1, true, []string{"*.go"}, []string{"main.go"}},
+		{2, true, []string{"*.go"}, []string{"main.go", "src/app.go"}},
+		{-1, true, []string{"*.go"}, []string{"main.go", "src/app.go", "src/lib/util.go"}},
+	}
+
+	for _, tt := range tests {
+		opts := Options{MaxDepth: tt.depth, DepthCountsDirs: tt.countsDirs, Include: tt.include, Exclude: []string{"node_modules"}}
+		paths, err := NewWalker(opts).Walk(root)
+		if err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		if result := relPaths(t, root, paths); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Walk() with depth %d, DepthCountsDirs %v, and Include %v = %v, expected %v", tt.depth, tt.countsDirs, tt.include, result, tt.expected)
+		}
+	}
+}
+
+func TestWalkOnEntry(t *testing.T) {
+	root := setupTree(t)
+
+	visited := make(map[string]bool)
+	walker := NewWalker(Options{MaxDepth: -1, Exclude: []string{"*.md"}})
+	walker.OnEntry = func(path string, _ os.DirEntry) {
+		visited[path] = true
+	}
+	if _, err := walker.Walk(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Filtered entries are visited too, so their listing can be reused
+	if !visited[filepath.Join(root, "README.md")] {
+		t.Error("OnEntry was not called for an excluded file")
+	}
+}
+
+func TestWalkOnExclude(t *testing.T) {
+	root := setupTree(t)
+
+	rules := make(map[string]string)
+	walker := NewWalker(Options{MaxDepth: -1, Exclude: []string{"node_modules"}, ExcludeRegexp: []*regexp.Regexp{regexp.MustCompile(`\.md$`)}})
+	walker.OnExclude = func(path, rule string) {
+		rules[relPaths(t, root, []string{path})[0]] = rule
+	}
+	if _, err := walker.Walk(root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing beneath an excluded directory is reported
+	expected := map[string]string{
+		".DS_Store":     OSNoiseRule,
+		"README.md":     `\.md$`,
+		"docs/guide.md": `\.md$`,
+		"node_modules":  "node_modules",
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("OnExclude() rules = %v, expected %v", rules, expected)
+	}
+}
+
+func TestWalkStop(t *testing.T) {
+	root := setupTree(t)
+
+	// Stopping on reaching docs keeps what was found up to it
+	stop := make(chan struct{})
+	walker := NewWalker(Options{MaxDepth: -1})
+	walker.Stop = stop
+	walker.OnEntry = func(path string, _ os.DirEntry) {
+		if filepath.Base(path) == "docs" {
+			close(stop)
+		}
+	}
+	paths, err := walker.Walk(root)
+	if !errors.Is(err, ErrStopped) {
+		t.Fatalf("Walk() error = %v, expected ErrStopped", err)
+	}
+	if result := relPaths(t, root, paths); !reflect.DeepEqual(result, []string{"README.md", "docs"}) {
+		t.Errorf("Walk() = %v, expected README.md and docs", result)
+	}
+}
+
+func TestWalkRepeats(t *testing.T) {
+	root := setupTree(t)
+
+	// A bind mount of the root inside itself would loop forever if followed
+	mountPoint := filepath.Join(root, "loop")
+	if err := os.Mkdir(mountPoint, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Command("mount", "--bind", root, mountPoint).Run(); err != nil {
+		t.Skip("bind mounts are not available")
+	}
+	defer exec.Command("umount", mountPoint).Run()
+
+	walker := NewWalker(Options{MaxDepth: -1})
+	paths, err := walker.Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed := 0
+	for _, path := range paths {
+		if path == mountPoint {
+			listed++
+		} else if filepath.Dir(path) == mountPoint {
+			t.Errorf("the contents of a repeated directory were listed: %s", path)
+		}
+	}
+	if listed != 1 {
+		t.Errorf("the repeated directory was listed %d times, expected once", listed)
+	}
+	if expected := map[string]string{mountPoint: root}; !reflect.DeepEqual(walker.Repeats, expected) {
+		t.Errorf("Repeats = %v, expected %v", walker.Repeats, expected)
+	}
+}
+
+func TestWalkFollowSymlinks(t *testing.T) {
+	root := setupTree(t)
+	// docs/lib is followed; src/back points at the root and would loop
+	for link, target := range map[string]string{
+		filepath.Join(root, "docs", "lib"): filepath.Join(root, "src", "lib"),
+		filepath.Join(root, "src", "back"): root,
+	} {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symlinks are not available: %v", err)
+		}
+	}
+
+	opts := Options{MaxDepth: -1, Exclude: []string{"node_modules"}}
+	paths, err := NewWalker(opts).Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range relPaths(t, root, paths) {
+		if strings.HasPrefix(path, "docs/lib/") {
+			t.Errorf("a symlink was followed without FollowSymlinks: %s", path)
+		}
+	}
+
+	opts.FollowSymlinks = true
+	walker := NewWalker(opts)
+	paths, err = walker.Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"README.md",
+		"docs",
+		"docs/guide.md",
+		"docs/lib",
+		"docs/lib/util.go",
+		"main.go",
+		"src",
+		"src/app.go",
+		"src/back",
+		"src/lib",
+	}
+	if got := relPaths(t, root, paths); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Walk() with FollowSymlinks = %v, expected %v", got, expected)
+	}
+
+	expectedRepeats := map[string]string{
+		filepath.Join(root, "src", "back"): root,
+		filepath.Join(root, "src", "lib"):  filepath.Join(root, "docs", "lib"),
+	}
+	if !reflect.DeepEqual(walker.Repeats, expectedRepeats) {
+		t.Errorf("Repeats = %v, expected %v", walker.Repeats, expectedRepeats)
+	}
+}
+
+func TestWalkJobs(t *testing.T) {
+	root := setupTree(t)
+	// Enough directories for listings to be fetched out of order
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(root, "src", "pkg"+strings.Repeat("x", i), "deep", "file.go")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, opts := range []Options{
+		{MaxDepth: -1},
+		{MaxDepth: 1, Exclude: []string{"node_modules"}},
+		{MaxDepth: -1, Include: []string{"*.go", "docs"}},
+		{DirsDepth: 2},
+	} {
+		sequential, err := NewWalker(opts).Walk(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.Jobs = 8
+		concurrent, err := NewWalker(opts).Walk(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(concurrent, sequential) {
+			t.Errorf("Walk() with %+v = %v, expected the sequential result %v", opts, concurrent, sequential)
+		}
+	}
+}
+
+func TestWalkJobsPrefetch(t *testing.T) {
+	root := setupTree(t)
+	walker := NewWalker(Options{MaxDepth: 0, Exclude: []string{"node_modules"}, Jobs: 8})
+	prefetched := make(map[string]bool)
+	walker.OnEntry = func(string, fs.DirEntry) {
+		walker.prefetcher.mu.Lock()
+		defer walker.prefetcher.mu.Unlock()
+		for dir := range walker.prefetcher.pending {
+			prefetched[dir] = true
+		}
+	}
+	if _, err := walker.Walk(root); err != nil {
+		t.Fatal(err)
+	}
+	if !prefetched[filepath.Join(root, "src")] {
+		t.Errorf("src was not prefetched: %v", prefetched)
+	}
+	// Neither excluded directories nor those below --depth are listed
+	for _, dir := range []string{filepath.Join(root, "node_modules"), filepath.Join(root, "src", "lib")} {
+		if prefetched[dir] {
+			t.Errorf("%s was prefetched, though the walk skips it", dir)
+		}
+	}
+}
+
+func TestWalkReadDir(t *testing.T) {
+	root := setupTree(t)
+	opts := Options{MaxDepth: -1, Exclude: []string{"node_modules"}, Jobs: 8}
+	expected, err := NewWalker(opts).Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed := make(map[string]bool)
+	walker := NewWalker(opts)
+	walker.ReadDir = func(dir string) ([]os.DirEntry, error) {
+		listed[dir] = true
+		return os.ReadDir(dir)
+	}
+	paths, err := walker.Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Walk() with ReadDir = %v, expected %v", paths, expected)
+	}
+	for _, dir := range []string{root, filepath.Join(root, "src", "lib")} {
+		if !listed[dir] {
+			t.Errorf("ReadDir was not called for %s", dir)
+		}
+	}
+	if listed[filepath.Join(root, "node_modules")] {
+		t.Error("ReadDir was called for an excluded directory")
+	}
+}
+
+func TestWalkFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":                   {},
+		"README.md":                 {},
+		".DS_Store":                 {},
+		"src/app.go":                {},
+		"src/lib/util.go":           {},
+		"node_modules/pkg/index.js": {},
+		"docs/guide.md":             {},
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{"everything", Options{MaxDepth: -1}, []string{"README.md", "docs", "docs/guide.md", "main.go", "node_modules", "node_modules/pkg", "node_modules/pkg/index.js", "src", "src/app.go", "src/lib", "src/lib/util.go"}},
+		{"exclude and depth", Options{MaxDepth: 1, Exclude: []string{"node_modules", "*.md"}}, []string{"docs", "main.go", "src", "src/app.go", "src/lib"}},
+		{"include", Options{MaxDepth: -1, Include: []string{"*.go", "docs"}, Jobs: 8}, []string{"docs/guide.md", "main.go", "src/app.go", "src/lib/util.go"}},
+	}
+	for _, tt := range tests {
+		walker := NewWalker(tt.opts)
+		walker.FS = fsys
+		paths, err := walker.Walk(".")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(paths, tt.expected) {
+			t.Errorf("%s: Walk() = %v, expected %v", tt.name, paths, tt.expected)
+		}
+	}
+
+	walker := NewWalker(Options{MaxDepth: -1, Include: []string{"*.go"}})
+	walker.FS = fsys
+	paths, err := walker.Walk(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := Build(".", paths, func(path string) (fs.FileInfo, error) { return fs.Stat(fsys, path) })
+	expected := ".\n" +
+		"├── main.go\n" +
+		"└── src\n" +
+		"    ├── app.go\n" +
+		"    └── lib\n" +
+		"        └── util.go\n"
+	if output := Render(top, UTF8); output != expected {
+		t.Errorf("Render() of an fs.FS walk =\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestBuildAndRender(t *testing.T) {
+	root := setupTree(t)
+	paths, err := NewWalker(Options{MaxDepth: -1, Exclude: []string{"node_modules", "docs"}}).Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	top := Build(root, paths, nil)
+	top.Name = "project"
+
+	expected := "project\n" +
+		"├── README.md\n" +
+		"├── main.go\n" +
+		"└── src\n" +
+		"    ├── app.go\n" +
+		"    └── lib\n" +
+		"        └── util.go\n"
+	if output := Render(top, UTF8); output != expected {
+		t.Errorf("Render() =\n%s\nexpected:\n%s", output, expected)
+	}
+
+	expected = "project\n" +
+		"|-- README.md\n" +
+		"|-- main.go\n" +
+		"`-- src\n" +
+		"    |-- app.go\n" +
+		"    `-- lib\n" +
+		"        `-- util.go\n"
+	if output := Render(top, ASCII); output != expected {
+		t.Errorf("Render(ASCII) =\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+// failingWriter accepts a number of writes, then fails.
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errors.New("closed")
+	}
+	w.writes--
+	return len(p), nil
+}
+
+func TestWrite(t *testing.T) {
+	top := &Node{Name: "project", IsDir: true, Children: []*Node{
+		{Name: "main.go"},
+		{Name: "src", IsDir: true, Children: []*Node{{Name: "app.go"}}},
+	}}
+
+	var output strings.Builder
+	if err := Write(&output, top, UTF8); err != nil || output.String() != Render(top, UTF8) {
+		t.Errorf("Write() = %q, %v, expected %q", output.String(), err, Render(top, UTF8))
+	}
+
+	// Drawing stops at the first failed write
+	w := &failingWriter{writes: 2}
+	if err := Write(w, top, UTF8); err == nil {
+		t.Error("Write() to a failing writer returned no error")
+	}
+	rows := 0
+	EachRow(top, UTF8, func(Row) error {
+		rows++
+		return errors.New("stop")
+	})
+	if rows != 1 {
+		t.Errorf("EachRow() went on for %d rows after an error, expected 1", rows)
+	}
+}
+
+func TestNodeSort(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]int{"b.txt": 30, "a.go": 10, "Makefile": 20, "c.GO": 40}
+	var paths []string
+	for name, size := range files {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Larger files are older
+		mtime := time.Now().Add(-time.Duration(size) * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	dir := filepath.Join(root, "lib")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, dir)
+
+	names := func(node *Node) []string {
+		var result []string
+		for _, child := range node.Children {
+			result = append(result, child.Name)
+		}
+		return result
+	}
+
+	top := Build(root, paths, nil)
+	if expected := []string{"Makefile", "a.go", "b.txt", "c.GO", "lib"}; !reflect.DeepEqual(names(top), expected) {
+		t.Errorf("Build() order = %v, expected %v", names(top), expected)
+	}
+
+	// The directory's measured size puts it between the files
+	dirSize := int64(25)
+	top.Children[4].Size = &dirSize
+
+	tests := []struct {
+		key       SortKey
+		dirsFirst bool
+		expected  []string
+	}{
+		{SortByName, true, []string{"lib", "Makefile", "a.go", "b.txt", "c.GO"}},
+		{SortBySize, false, []string{"c.GO", "b.txt", "lib", "Makefile", "a.go"}},
+		{SortByMtime, true, []string{"lib", "a.go", "Makefile", "b.txt", "c.GO"}},
+		{SortByExtension, false, []string{"Makefile", "lib", "a.go", "c.GO", "b.txt"}},
+	}
+	for _, tt := range tests {
+		top.Sort(tt.key, tt.dirsFirst)
+		if result := names(top); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Sort(%s, %v) = %v, expected %v", tt.key, tt.dirsFirst, result, tt.expected)
+		}
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	top := &Node{Name: "project", IsDir: true, Children: []*Node{
+		{Name: "src", IsDir: true, Children: []*Node{{Name: "main.go"}}},
+	}}
+
+	data, err := RenderJSON(top)
+	if err != nil {
+		t.Fatalf("RenderJSON() error = %v", err)
+	}
+
+	expected := `{
+  "name": "project",
+  "type": "dir",
+  "path": ".",
+  "id": "cdb4ee2aea69",
+  "children": [
+    {
+      "name": "src",
+      "type": "dir",
+      "path": "src",
+      "id": "25a6634263c1",
+      "children": [
+        {
+          "name": "main.go",
+          "type": "file",
+          "path": "src/main.go",
+          "id": "9e185f29fa35"
+        }
+      ]
+    }
+  ]
+}
+`
+	if string(data) != expected {
+		t.Errorf("RenderJSON() =\n%s\nexpected:\n%s", data, expected)
+	}
+}
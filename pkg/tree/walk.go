@@ -0,0 +1,587 @@
+package tree
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// OSNoiseNames are operating system metadata files and folders that are
+// hidden from every tree unless Options.ShowOSFiles is set. They are matched
+// case-insensitively, as Windows and macOS file names are.
+var OSNoiseNames = []string{
+	".DS_Store",
+	".Spotlight-V100",
+	".Trashes",
+	".fseventsd",
+	"Thumbs.db",
+	"ehthumbs.db",
+	"desktop.ini",
+	"$RECYCLE.BIN",
+	"System Volume Information",
+}
+
+// ErrStopped is returned by Walk, along with the entries found so far, when
+// the walk ends early because Walker.Stop was closed.
+var ErrStopped = errors.New("walk stopped")
+
+// OSNoiseRule is the rule passed to Walker.OnExclude for OS metadata.
+const OSNoiseRule = "OS metadata"
+
+// Options control which entries a Walker lists.
+type Options struct {
+	// Exclude holds glob patterns matched against entry names. Matching
+	// directories are skipped along with everything beneath them.
+	Exclude []string
+	// Include holds glob patterns for the files to list. A directory whose
+	// name matches a pattern, as MatchDirName decides, has its whole contents
+	// listed instead, whatever the other patterns. If empty, everything that
+	// is not excluded is listed.
+	Include []string
+	// ExcludeRegexp holds regular expressions matched against the path of
+	// each entry relative to the root, with forward slashes. Matching
+	// directories are skipped along with everything beneath them.
+	ExcludeRegexp []*regexp.Regexp
+	// IncludeRegexp holds regular expressions matched like ExcludeRegexp.
+	// Files whose paths match are listed as if they matched Include.
+	IncludeRegexp []*regexp.Regexp
+	// MaxDepth limits how deep entries are listed: 0 is the root's
+	// immediate children, and -1 is unlimited.
+	MaxDepth int
+	// DepthCountsDirs leaves the files out of the deepest level MaxDepth
+	// allows, which then holds only the directories, marking where the tree
+	// was cut off. Otherwise files and directories count alike.
+	DepthCountsDirs bool
+	// DirsDepth, if positive, limits directories to that many levels but
+	// lists every file in the directories shown. It overrides MaxDepth.
+	DirsDepth int
+	// ShowOSFiles lists operating system metadata such as .DS_Store, which
+	// is hidden otherwise.
+	ShowOSFiles bool
+	// FollowSymlinks descends into symlinks to directories as if they were
+	// the directories themselves. A link to a directory already reached is
+	// listed but not descended into, as with Walker.Repeats.
+	FollowSymlinks bool
+	// Jobs is how many directories may be listed at once. The results are
+	// the same for any value; above 1, listings are fetched concurrently
+	// ahead of the walk, which speeds up large trees and network drives.
+	Jobs int
+}
+
+// Walker finds the entries of a directory tree that match its Options.
+type Walker struct {
+	Options Options
+	// OnEntry, if set, is called for every entry the walk visits, including
+	// those that end up filtered out, so that callers can reuse the
+	// directory listing instead of stat'ing entries again.
+	OnEntry func(path string, d fs.DirEntry)
+	// OnExclude, if set, is called for every entry left out by an exclusion
+	// rule, with the rule: the Exclude pattern or ExcludeRegexp expression
+	// that matched, or OSNoiseRule. An excluded directory is reported once,
+	// as nothing beneath it is visited.
+	OnExclude func(path, rule string)
+	// Stop, if set, ends the walk early once it is closed, as when the
+	// user presses Ctrl+C; Walk then returns ErrStopped.
+	Stop <-chan struct{}
+	// Repeats maps every directory that the last Walk reached a second time,
+	// such as through a bind mount, to the path it was first reached by.
+	// Repeated directories are listed but not descended into, so a mount of
+	// an ancestor cannot make the walk loop.
+	Repeats map[string]string
+	// ReadDir, if set, lists directories in place of os.ReadDir, such as from
+	// an index kept in memory. It must return the entries sorted by name.
+	ReadDir func(dir string) ([]fs.DirEntry, error)
+	// FS, if set, is walked instead of the disk, such as a zip file opened
+	// with archive/zip. The root and the paths returned are then fs.FS paths,
+	// with forward slashes and "." for the top. Symlinks are not followed
+	// and repeated directories are not detected.
+	FS fs.FS
+
+	// prefetcher lists directories ahead of the current Walk when Jobs > 1
+	prefetcher *dirPrefetcher
+}
+
+// dirID identifies a directory independently of the path it is reached by.
+type dirID struct {
+	volume, index uint64
+}
+
+// followedLink is a symlink to a directory that the walk descends into. Its
+// Info still describes the link itself.
+type followedLink struct {
+	fs.DirEntry
+	// target describes the directory the link resolves to, at realPath
+	target   fs.FileInfo
+	realPath string
+}
+
+func (followedLink) IsDir() bool { return true }
+
+// NewWalker returns a Walker for the given options.
+func NewWalker(opts Options) *Walker {
+	return &Walker{Options: opts}
+}
+
+// Walk returns the paths beneath root that match the options, in lexical
+// order with every directory before its contents. The root itself is not
+// included.
+func (w *Walker) Walk(root string) ([]string, error) {
+	opts := w.Options
+	var matchingPaths []string
+
+	visit := func(path string, d fs.DirEntry) {
+		if w.OnEntry != nil {
+			w.OnEntry(path, d)
+		}
+	}
+	excluded := func(path, rule string) {
+		if w.OnExclude != nil {
+			w.OnExclude(path, rule)
+		}
+	}
+	stopped := func() bool {
+		select {
+		case <-w.Stop:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// repeated reports whether a directory has already been reached by
+	// another path, recording it in Repeats if so
+	w.Repeats = make(map[string]string)
+	visited := make(map[dirID]string)
+	repeated := func(path string, d fs.DirEntry) bool {
+		if w.FS != nil {
+			return false
+		}
+		// A followed link is identified by the directory it resolves to
+		idPath, info := path, fs.FileInfo(nil)
+		if link, ok := d.(followedLink); ok {
+			idPath, info = link.realPath, link.target
+		} else {
+			var err error
+			if info, err = d.Info(); err != nil {
+				return false
+			}
+		}
+		id, ok := readDirID(idPath, info)
+		if !ok {
+			return false
+		}
+		if first, seen := visited[id]; seen {
+			w.Repeats[path] = first
+			return true
+		}
+		visited[id] = path
+		return false
+	}
+
+	if opts.Jobs > 1 && w.ReadDir == nil && w.FS == nil {
+		w.prefetcher = newDirPrefetcher(opts.Jobs)
+		defer func() {
+			w.prefetcher.close()
+			w.prefetcher = nil
+		}()
+	}
+
+	// enters reports whether the checks below let the walk descend into the
+	// directory at path, so that only those are prefetched
+	enters := func(path string, d fs.DirEntry) bool {
+		relPath, err := filepath.Rel(root, path)
+		if err != nil || !opts.WithinDepth(strings.Count(relPath, string(filepath.Separator)), true) {
+			return false
+		}
+		if opts.isOSNoise(d.Name()) || opts.excludingRegexp(root, path) != nil {
+			return false
+		}
+		for _, pattern := range opts.Exclude {
+			if matched, _ := filepath.Match(pattern, d.Name()); matched {
+				return false
+			}
+		}
+		return true
+	}
+
+	walkErr := w.walkDir(root, enters, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if stopped() {
+			return ErrStopped
+		}
+		visit(path, d)
+
+		// Depth check (before exclusion / inclusion)
+		if d.IsDir() && path != root {
+			relPath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+
+			// Depth 0 is the root's immediate children
+			depth := strings.Count(relPath, string(filepath.Separator))
+
+			// if the current depth exceeds the limit, skip this directory
+			if !opts.WithinDepth(depth, true) {
+				return fs.SkipDir
+			}
+		}
+
+		// --- Exclusion Logic (runs first) ---
+		entryName := d.Name()
+		if path != root && opts.isOSNoise(entryName) {
+			excluded(path, OSNoiseRule)
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		for _, pattern := range opts.Exclude {
+			matched, _ := filepath.Match(pattern, entryName)
+			if matched {
+				if d.IsDir() {
+					if path == root {
+						return nil
+					}
+					excluded(path, pattern)
+					return fs.SkipDir
+				}
+				excluded(path, pattern)
+				return nil
+			}
+		}
+
+		if re := opts.excludingRegexp(root, path); path != root && re != nil {
+			excluded(path, re.String())
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		isRepeat := d.IsDir() && repeated(path, d)
+
+		// If not in include mode, add everything that respects the depth limit.
+		if !opts.Including() {
+			// Also check depth for files when not in include mode.
+			relPath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+
+			if path == root {
+				return nil
+			}
+
+			depth := strings.Count(relPath, string(filepath.Separator))
+
+			// Add any item that is within the allowed depth.
+			if opts.WithinDepth(depth, d.IsDir()) {
+				matchingPaths = append(matchingPaths, path)
+			}
+		}
+
+		// A directory reached a second time is listed, but not again in full
+		if isRepeat {
+			return fs.SkipDir
+		}
+
+		// In include mode, we must match files or directories explicitly.
+		if opts.Including() {
+			// Case 1: A directory's name matches an include pattern, though
+			// only by its exact name for the root. If so, we do a sub-walk
+			// and add all its files.
+			if d.IsDir() {
+				for _, pattern := range opts.Include {
+					if d.Name() == pattern || path != root && MatchDirName(pattern, d.Name()) {
+						// This directory is explicitly included. Walk it and add all files within.
+						subEnters := func(_ string, subD fs.DirEntry) bool { return !opts.isOSNoise(subD.Name()) }
+						subWalkErr := w.walkDir(path, subEnters, func(subPath string, subD fs.DirEntry, _ error) error {
+							if stopped() {
+								return ErrStopped
+							}
+							visit(subPath, subD)
+							if subD.IsDir() && subPath != path && opts.isOSNoise(subD.Name()) {
+								excluded(subPath, OSNoiseRule)
+								return fs.SkipDir
+							}
+							if subD.IsDir() && subPath != path && repeated(subPath, subD) {
+								return fs.SkipDir
+							}
+							if !subD.IsDir() {
+								// Check if this sub-file is excluded.
+								rule := ""
+								if opts.isOSNoise(subD.Name()) {
+									rule = OSNoiseRule
+								}
+								for _, excludePattern := range opts.Exclude {
+									if rule != "" {
+										break
+									}
+									if matched, _ := filepath.Match(excludePattern, subD.Name()); matched {
+										rule = excludePattern
+									}
+								}
+								if re := opts.excludingRegexp(root, subPath); rule == "" && re != nil {
+									rule = re.String()
+								}
+								if rule == "" {
+									matchingPaths = append(matchingPaths, subPath)
+								} else {
+									excluded(subPath, rule)
+								}
+							}
+							return nil
+						})
+						if subWalkErr != nil {
+							return subWalkErr
+						}
+						// We've processed this directory, so skip it in the main walk to avoid duplication.
+						return fs.SkipDir
+					}
+				}
+			} else { // Case 2: If it's a file, check if it matches a glob-style include pattern.
+				matched := len(opts.IncludeRegexp) > 0 && opts.IncludesPath(slashRel(root, path))
+				for _, pattern := range opts.Include {
+					if matched {
+						break // Found a match, no need to check other patterns
+					}
+					matched, _ = filepath.Match(pattern, d.Name())
+				}
+				if matched {
+					// Also check depth for files when in include mode.
+					relPath, err := filepath.Rel(root, path)
+					if err != nil {
+						return err
+					}
+					depth := strings.Count(relPath, string(filepath.Separator))
+					if opts.WithinDepth(depth, false) {
+						matchingPaths = append(matchingPaths, path)
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return matchingPaths, walkErr
+}
+
+// walkDir is filepath.WalkDir, except that with FollowSymlinks it descends
+// into symlinks to directories, passing them to fn as directories, with Jobs
+// it lists directories ahead of fn, and with ReadDir or FS it lists them
+// through those. It relies on fn skipping directories already reached to end
+// cycles. Only the directories that enters reports fn will descend into are
+// prefetched.
+func (w *Walker) walkDir(root string, enters func(path string, d fs.DirEntry) bool, fn fs.WalkDirFunc) error {
+	if !w.Options.FollowSymlinks && w.prefetcher == nil && w.ReadDir == nil && w.FS == nil {
+		return filepath.WalkDir(root, fn)
+	}
+
+	var info fs.FileInfo
+	var err error
+	if w.FS != nil {
+		info, err = fs.Stat(w.FS, root)
+	} else {
+		info, err = os.Lstat(root)
+	}
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = w.walkEntries(root, w.follow(root, fs.FileInfoToDirEntry(info)), enters, fn)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkEntries walks the entries beneath path in lexical order, with the same
+// fs.SkipDir and fs.SkipAll handling as filepath.WalkDir. The subdirectories
+// of each directory entered that enters accepts are prefetched before any of
+// them is walked.
+func (w *Walker) walkEntries(path string, d fs.DirEntry, enters func(path string, d fs.DirEntry) bool, fn fs.WalkDirFunc) error {
+	if err := fn(path, d, nil); err != nil || !d.IsDir() {
+		if errors.Is(err, fs.SkipDir) && d.IsDir() {
+			err = nil
+		}
+		return err
+	}
+
+	entries, err := w.readDir(path)
+	if err != nil {
+		// Report the failed listing, as filepath.WalkDir does
+		if err = fn(path, d, err); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+
+	children := make([]fs.DirEntry, len(entries))
+	for i, entry := range entries {
+		entryPath := w.join(path, entry.Name())
+		children[i] = w.follow(entryPath, entry)
+		if children[i].IsDir() && w.prefetcher != nil && enters(entryPath, children[i]) {
+			w.prefetcher.prefetch(entryPath)
+		}
+	}
+
+	for i, entry := range entries {
+		if err := w.walkEntries(w.join(path, entry.Name()), children[i], enters, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// readDir lists dir through ReadDir or FS if set, and the prefetcher
+// otherwise.
+func (w *Walker) readDir(dir string) ([]fs.DirEntry, error) {
+	if w.ReadDir != nil {
+		return w.ReadDir(dir)
+	}
+	if w.FS != nil {
+		return fs.ReadDir(w.FS, dir)
+	}
+	return w.prefetcher.readDir(dir)
+}
+
+// join returns the path of name in dir: an fs.FS path when walking FS, and a
+// filepath otherwise.
+func (w *Walker) join(dir, name string) string {
+	if w.FS == nil {
+		return filepath.Join(dir, name)
+	}
+	if dir == "." {
+		return name
+	}
+	return dir + "/" + name
+}
+
+// follow returns d as a followedLink if FollowSymlinks is set and d is a
+// symlink that resolves to a directory, and d unchanged otherwise.
+func (w *Walker) follow(path string, d fs.DirEntry) fs.DirEntry {
+	if !w.Options.FollowSymlinks || w.FS != nil || d.Type()&fs.ModeSymlink == 0 {
+		return d
+	}
+	target, err := os.Stat(path)
+	if err != nil || !target.IsDir() {
+		return d
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return d
+	}
+	return followedLink{DirEntry: d, target: target, realPath: realPath}
+}
+
+// WithinDepth reports whether an entry at the given depth (0 for the root's
+// immediate children) should be listed. With DirsDepth, directories are
+// limited to that many levels but every file inside a listed directory is
+// kept; with DepthCountsDirs, it is the other way around.
+func (o Options) WithinDepth(depth int, isDir bool) bool {
+	if o.DirsDepth > 0 {
+		if isDir {
+			return depth < o.DirsDepth
+		}
+		return depth <= o.DirsDepth
+	}
+	if o.MaxDepth == -1 {
+		return true
+	}
+	if o.DepthCountsDirs && !isDir {
+		return depth < o.MaxDepth
+	}
+	return depth <= o.MaxDepth
+}
+
+// MatchDirName reports whether the Include pattern selects a directory called
+// name, by its exact name or as a glob pattern, so that "test*" includes both
+// tests and testdata.
+func MatchDirName(pattern, name string) bool {
+	if name == pattern {
+		return true
+	}
+	matched, _ := filepath.Match(pattern, name)
+	return matched
+}
+
+// Including reports whether the options are in include mode, listing only
+// the files that match Include or IncludeRegexp.
+func (o Options) Including() bool {
+	return len(o.Include) > 0 || len(o.IncludeRegexp) > 0
+}
+
+// ExcludesPath reports whether relPath, relative to the root with forward
+// slashes, matches one of the ExcludeRegexp expressions.
+func (o Options) ExcludesPath(relPath string) bool {
+	for _, re := range o.ExcludeRegexp {
+		if re.MatchString(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludingRegexp returns the first ExcludeRegexp expression matching path
+// beneath root, or nil if none does.
+func (o Options) excludingRegexp(root, path string) *regexp.Regexp {
+	if len(o.ExcludeRegexp) == 0 {
+		return nil
+	}
+	relPath := slashRel(root, path)
+	for _, re := range o.ExcludeRegexp {
+		if re.MatchString(relPath) {
+			return re
+		}
+	}
+	return nil
+}
+
+// IncludesPath reports whether relPath, relative to the root with forward
+// slashes, matches one of the IncludeRegexp expressions.
+func (o Options) IncludesPath(relPath string) bool {
+	for _, re := range o.IncludeRegexp {
+		if re.MatchString(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// slashRel returns path relative to root with forward slashes, as the
+// regular expressions of Options are matched against.
+func slashRel(root, path string) string {
+	relPath, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// isOSNoise reports whether name is operating system metadata that these
+// options hide.
+func (o Options) isOSNoise(name string) bool {
+	return !o.ShowOSFiles && IsOSNoise(name)
+}
+
+// IsOSNoise reports whether name is one of the OSNoiseNames.
+func IsOSNoise(name string) bool {
+	for _, noise := range OSNoiseNames {
+		if strings.EqualFold(name, noise) {
+			return true
+		}
+	}
+	return false
+}
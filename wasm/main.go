@@ -0,0 +1,141 @@
+//go:build js && wasm
+
+// Command wasm renders wintree-style trees of zip files in the browser,
+// using the same walking and rendering as the CLI. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o wintree.wasm ./wasm
+//
+// and load it through wintree.js, next to wasm_exec.js from the Go
+// distribution. It defines a global wintreeRender(bytes, options) function,
+// which returns the tree as a string, or an Error.
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"syscall/js"
+
+	"github.com/maxdribny/wintree/pkg/tree"
+)
+
+// options are the settings wintreeRender accepts, named and behaving like
+// the flags of the CLI.
+type options struct {
+	exclude     []string
+	include     []string
+	depth       int
+	format      string
+	charset     string
+	label       string
+	dirsFirst   bool
+	showOSFiles bool
+}
+
+func main() {
+	js.Global().Set("wintreeRender", js.FuncOf(render))
+	// The function must outlive main for the page to call it
+	select {}
+}
+
+// render is wintreeRender: it draws the zip file in args[0], a Uint8Array,
+// with the options in args[1], an object that may be left out.
+func render(_ js.Value, args []js.Value) any {
+	if len(args) == 0 || args[0].Type() != js.TypeObject {
+		return jsError("wintreeRender needs the bytes of a zip file as a Uint8Array")
+	}
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+
+	opts := options{depth: -1, format: "tree", charset: "utf8"}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		opts = parseOptions(args[1], opts)
+	}
+	output, err := renderZip(data, opts)
+	if err != nil {
+		return jsError(err.Error())
+	}
+	return output
+}
+
+// parseOptions reads the options set on a JavaScript object over defaults.
+func parseOptions(value js.Value, opts options) options {
+	list := func(name string) []string {
+		var values []string
+		if v := value.Get(name); v.Type() == js.TypeObject {
+			for i := 0; i < v.Length(); i++ {
+				values = append(values, v.Index(i).String())
+			}
+		}
+		return values
+	}
+	opts.exclude = list("exclude")
+	opts.include = list("include")
+	if v := value.Get("depth"); v.Type() == js.TypeNumber {
+		opts.depth = v.Int()
+	}
+	if v := value.Get("format"); v.Type() == js.TypeString {
+		opts.format = v.String()
+	}
+	if v := value.Get("charset"); v.Type() == js.TypeString {
+		opts.charset = v.String()
+	}
+	if v := value.Get("label"); v.Type() == js.TypeString {
+		opts.label = v.String()
+	}
+	opts.dirsFirst = value.Get("dirsFirst").Truthy()
+	opts.showOSFiles = value.Get("showOSFiles").Truthy()
+	return opts
+}
+
+// renderZip draws the tree of the zip file in data.
+func renderZip(data []byte, opts options) (string, error) {
+	var charset tree.Charset
+	switch opts.charset {
+	case "utf8", "unicode":
+		charset = tree.UTF8
+	case "ascii":
+		charset = tree.ASCII
+	default:
+		return "", fmt.Errorf("invalid charset %q (use utf8 or ascii)", opts.charset)
+	}
+	if opts.format != "tree" && opts.format != "json" {
+		return "", fmt.Errorf("invalid format %q (use tree or json)", opts.format)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("cannot read the zip file: %w", err)
+	}
+	walker := tree.NewWalker(tree.Options{
+		Exclude:     opts.exclude,
+		Include:     opts.include,
+		MaxDepth:    opts.depth,
+		ShowOSFiles: opts.showOSFiles,
+	})
+	walker.FS = archive
+	paths, err := walker.Walk(".")
+	if err != nil {
+		return "", err
+	}
+
+	top := tree.Build(".", paths, func(path string) (fs.FileInfo, error) {
+		return fs.Stat(archive, path)
+	})
+	if opts.label != "" {
+		top.Name = opts.label
+	}
+	top.Sort(tree.SortByName, opts.dirsFirst)
+
+	if opts.format == "json" {
+		output, err := tree.RenderJSON(top)
+		return string(output), err
+	}
+	return tree.Render(top, charset), nil
+}
+
+// jsError returns a JavaScript Error with the message, for wintree.js to throw.
+func jsError(message string) js.Value {
+	return js.Global().Get("Error").New(message)
+}
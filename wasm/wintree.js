@@ -0,0 +1,41 @@
+// wintree.js renders wintree-style trees of zip files in the browser with
+// wintree.wasm. Load wasm_exec.js from the Go distribution first:
+//
+//   <script src="wasm_exec.js"></script>
+//   <script type="module">
+//     import { loadWintree } from "./wintree.js";
+//     const wintree = await loadWintree("wintree.wasm");
+//     const text = await wintree.render(input.files[0], { depth: 2, exclude: ["node_modules"] });
+//   </script>
+//
+// Options are named like the CLI's flags: exclude and include (arrays of
+// glob patterns), depth (-1, the default, for the whole archive), format
+// ("tree" or "json"), charset ("utf8" or "ascii"), label, dirsFirst, and
+// showOSFiles.
+
+export async function loadWintree(wasmURL = "wintree.wasm") {
+  if (typeof Go === "undefined") {
+    throw new Error("load wasm_exec.js before wintree.js");
+  }
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(wasmURL), go.importObject);
+  // The Go program keeps running to serve calls until the page is closed
+  go.run(instance);
+
+  return {
+    // render draws the tree of a zip file given as a File, Blob,
+    // ArrayBuffer, or Uint8Array. A File's name, without .zip, labels the
+    // root unless options.label is set.
+    async render(zip, options = {}) {
+      const bytes = new Uint8Array(zip instanceof Blob ? await zip.arrayBuffer() : zip);
+      if (options.label === undefined && typeof File !== "undefined" && zip instanceof File) {
+        options = { ...options, label: zip.name.replace(/\.zip$/i, "") };
+      }
+      const result = globalThis.wintreeRender(bytes, options);
+      if (result instanceof Error) {
+        throw result;
+      }
+      return result;
+    },
+  };
+}